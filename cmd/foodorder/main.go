@@ -2,40 +2,322 @@ package main
 
 import (
 	"backend-challenge/config"
+	"backend-challenge/internal/catalog"
 	"backend-challenge/internal/db"
+	"backend-challenge/internal/delivery"
 	"backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/images"
+	"backend-challenge/internal/kitchen"
 	"backend-challenge/internal/services"
+	"backend-challenge/internal/slots"
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func setupDB(db string) *sql.DB {
-	log.Printf("DB Path: %s", db)
-	d, err := sql.Open("sqlite3", db)
+func setupDB(path string) *sql.DB {
+	log.Printf("DB Path: %s", path)
+	d, err := sql.Open("sqlite3", path)
 	if err != nil {
 		log.Fatalf("sql.Open failed: %v", err)
 	}
+	if err := db.Migrate(context.Background(), d); err != nil {
+		log.Fatalf("db.Migrate failed: %v", err)
+	}
 	return d
 }
 
+func fulfilmentAvailability(cfg map[string]config.FulfilmentAvailability) map[string]services.FulfilmentAvailability {
+	availability := make(map[string]services.FulfilmentAvailability, len(cfg))
+	for kind, a := range cfg {
+		availability[kind] = services.FulfilmentAvailability{Disabled: a.Disabled, From: a.From, Until: a.Until}
+	}
+	return availability
+}
+
+// deliveryZones builds the delivery zones from the config, falling back to the
+// delivery_zones table. It returns nil when neither defines any zone.
+func deliveryZones(cfg delivery.Config, dao db.DeliveryZoneDao) *delivery.Zones {
+	if len(cfg.Zones) == 0 {
+		definitions, err := dao.GetDeliveryZones(context.Background())
+		if err != nil {
+			log.Fatalf("loading delivery zones: %v", err)
+		}
+		for _, definition := range definitions {
+			var zone delivery.ZoneConfig
+			if err := json.Unmarshal(definition, &zone); err != nil {
+				log.Fatalf("loading delivery zones: %v", err)
+			}
+			cfg.Zones = append(cfg.Zones, zone)
+		}
+	}
+	if len(cfg.Zones) == 0 {
+		return nil
+	}
+	zones, err := delivery.New(cfg)
+	if err != nil {
+		log.Fatalf("delivery zones: %v", err)
+	}
+	return zones
+}
+
+// pickupSlots builds the pickup slot schedule from the config. It returns nil
+// when no slot length is set, and orders can't be scheduled ahead.
+func pickupSlots(cfg slots.Config) *slots.Schedule {
+	if cfg.Length == 0 {
+		return nil
+	}
+	schedule, err := slots.New(cfg)
+	if err != nil {
+		log.Fatalf("pickup slots: %v", err)
+	}
+	return schedule
+}
+
+// productDao reads the catalog from memory, checked for changes made elsewhere
+// every check, or from the database when check is zero.
+func productDao(conn *sql.DB, check time.Duration) db.ProductDao {
+	if check == 0 {
+		return db.NewProductDao(conn)
+	}
+	catalog := db.NewCachedProductDao(conn)
+	go catalog.CheckEvery(context.Background(), check)
+	return catalog
+}
+
+const catalogUsage = `usage:
+  foodorder catalog export [-format csv|json] [file]
+  foodorder catalog import [-format csv|json] [-dry-run] [-prune] file
+
+export writes the products on sale to file, or to standard output as JSON.
+import creates and updates the products in file, going by their IDs, and with
+-prune archives the products on sale that file doesn't have. A file of "-" is
+standard input or output, and needs -format.
+`
+
+// catalogCommand runs "foodorder catalog", which imports and exports the
+// product catalog, and returns its exit code.
+func catalogCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || (args[0] != "import" && args[0] != "export") {
+		fmt.Fprint(stderr, catalogUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("catalog "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, catalogUsage) }
+	format := fs.String("format", "", "csv or json; by default the file's extension says")
+	dryRun := fs.Bool("dry-run", false, "only show the changes import would make")
+	prune := fs.Bool("prune", false, "archive the products on sale the file doesn't have")
+	files, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return 2
+	}
+	if len(files) > 1 || (args[0] == "import" && len(files) == 0) {
+		fs.Usage()
+		return 2
+	}
+	path := "-"
+	if len(files) == 1 {
+		path = files[0]
+	}
+	switch {
+	case *format != "":
+	case path != "-":
+		if *format, err = catalog.FormatOf(path); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	case args[0] == "export":
+		*format = catalog.JSON
+	default:
+		fmt.Fprintln(stderr, "importing from standard input needs -format")
+		return 2
+	}
+
+	conn := setupDB(config.ReadConfig().Db)
+	defer conn.Close()
+	products := db.NewProductDao(conn)
+	ctx := context.Background()
+	if args[0] == "export" {
+		err = exportCatalog(ctx, products, path, *format, stdout)
+	} else {
+		err = importCatalog(ctx, products, path, *format, *dryRun, *prune, stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed parses the flags in args wherever they are among the
+// other arguments, and returns those.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return rest, nil
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func exportCatalog(ctx context.Context, products db.ProductDao, path, format string, stdout io.Writer) error {
+	all, err := products.GetAllProducts(ctx)
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		return catalog.Write(stdout, format, all)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := catalog.Write(f, format, all); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "exported %d products to %s\n", len(all), path)
+	return nil
+}
+
+func importCatalog(ctx context.Context, products db.ProductDao, path, format string, dryRun, prune bool, stdout io.Writer) error {
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	entries, err := catalog.Read(in, format)
+	var invalid catalog.Errors
+	if errors.As(err, &invalid) {
+		return fmt.Errorf("%s has %d problems, nothing was imported:\n%w", path, len(invalid), err)
+	} else if err != nil {
+		return err
+	}
+	changes, err := catalog.Plan(ctx, products, entries, prune)
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, c := range changes {
+		fmt.Fprintln(stdout, c)
+		counts[c.Kind]++
+	}
+	summary := fmt.Sprintf("%d to create, %d to update, %d to restore, %d to archive, %d unchanged",
+		counts[catalog.Create], counts[catalog.Update], counts[catalog.Restore], counts[catalog.Archive],
+		len(entries)-len(changes)+counts[catalog.Archive])
+	if dryRun {
+		fmt.Fprintf(stdout, "%s; dry run, nothing was changed\n", summary)
+		return nil
+	}
+	if err := catalog.Apply(ctx, products, changes); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s; done\n", summary)
+	return nil
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "catalog" {
+		os.Exit(catalogCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
 	log.Printf("Server started")
 	config := config.GetConfig()
 	conn := setupDB(config.Db)
 	defer conn.Close()
-	product_dao := db.NewProductDao(conn)
+	product_dao := productDao(conn, config.CatalogCheck)
 	order_dao := db.NewOrderDao(conn)
+	quote_dao := db.NewQuoteDao(conn)
+	cart_dao := db.NewCartDao(conn)
+	webhook_dao := db.NewWebhookDao(conn)
+	stock_dao := db.NewStockDao(conn)
+	ingredient_dao := db.NewIngredientDao(conn)
+	slot_dao := db.NewSlotDao(conn)
+	kitchen_dao := db.NewKitchenDao(conn)
+	category_dao := db.NewCategoryDao(conn)
+	schedule := pickupSlots(config.Slots)
+	stations, err := kitchen.New(config.Kitchen)
+	if err != nil {
+		log.Fatalf("kitchen stations: %v", err)
+	}
 
-	OrderAPIService := services.NewOrderAPIService(order_dao, product_dao, config.CouponBase, config.CouponMin)
+	OrderEvents := services.NewOrderEventBus(config.StreamReplay, stations)
+	OrderAPIService := services.NewOrderAPIService(order_dao, product_dao, quote_dao, config.CouponBase, config.CouponMin,
+		services.WithCouponDiscount(config.CouponDiscount),
+		services.WithQuoteTTL(config.QuoteTTL),
+		services.WithAsyncOrders(config.AsyncOrders),
+		services.WithCouponRelease(config.CouponRelease),
+		services.WithOrderEvents(OrderEvents),
+		services.WithFulfilmentAvailability(fulfilmentAvailability(config.Fulfilment)),
+		services.WithDeliveryZones(deliveryZones(config.Delivery, db.NewDeliveryZoneDao(conn))),
+		services.WithSlots(schedule),
+		services.WithImageBaseURL(config.ImageBaseURL),
+	)
 	OrderAPIController := openapi.NewOrderAPIController(OrderAPIService)
+	go OrderAPIService.ReleaseScheduledEvery(context.Background(), time.Minute)
+	OrderStreamController := services.NewOrderStreamController(OrderEvents, config.StreamHeartbeat)
+
+	CartAPIService := services.NewCartAPIService(cart_dao, OrderAPIService, config.CartTTL)
+	CartAPIController := openapi.NewCartAPIController(CartAPIService)
+	go CartAPIService.ExpireCartsEvery(context.Background(), time.Minute)
 
-	ProductAPIService := services.NewProductAPIService(product_dao)
+	WebhookAPIService := services.NewWebhookAPIService(webhook_dao)
+	WebhookAPIController := openapi.NewWebhookAPIController(WebhookAPIService)
+	WebhookDispatcher := services.NewWebhookDispatcher(webhook_dao,
+		services.WithWebhookAttempts(config.WebhookAttempts),
+		services.WithWebhookBackoff(config.WebhookBackoff, config.WebhookMaxBackoff),
+	)
+	go WebhookDispatcher.DispatchEvery(context.Background(), time.Second)
+
+	var uploads *images.Store
+	if config.Images.Dir != "" {
+		if uploads, err = images.New(config.Images); err != nil {
+			log.Fatalf("image store: %v", err)
+		}
+	}
+	ProductAPIService := services.NewProductAPIService(product_dao, stock_dao, ingredient_dao, config.ImageBaseURL, uploads,
+		services.WithProductCacheControl(config.ProductCacheControl),
+	)
 	ProductAPIController := openapi.NewProductAPIController(ProductAPIService)
+	ImageController := services.NewImageController(uploads)
+
+	CategoryAPIService := services.NewCategoryAPIService(category_dao, ProductAPIService)
+	CategoryAPIController := openapi.NewCategoryAPIController(CategoryAPIService)
+
+	InventoryAPIService := services.NewInventoryAPIService(stock_dao, ingredient_dao)
+	InventoryAPIController := openapi.NewInventoryAPIController(InventoryAPIService)
+
+	SlotAPIService := services.NewSlotAPIService(schedule, slot_dao)
+	SlotAPIController := openapi.NewSlotAPIController(SlotAPIService)
+
+	KitchenAPIService := services.NewKitchenAPIService(kitchen_dao, order_dao, stations, OrderEvents, config.ImageBaseURL)
+	KitchenAPIController := openapi.NewKitchenAPIController(KitchenAPIService)
 
-	router := openapi.NewRouter(OrderAPIController, ProductAPIController)
+	// The stream routes go first so that /api/order/stream isn't taken for an order ID.
+	router := openapi.NewRouter(OrderStreamController, CartAPIController, OrderAPIController, ProductAPIController, CategoryAPIController, WebhookAPIController, InventoryAPIController, SlotAPIController, KitchenAPIController, ImageController)
+	router.Use(services.RequireAPIKey(services.KeysByScope(config.AdminKeys, config.APIKeys)))
 
 	log.Fatal(http.ListenAndServe(":8080", router))
 }
@@ -1,21 +1,153 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
+
+	"backend-challenge/internal/delivery"
+	"backend-challenge/internal/images"
+	"backend-challenge/internal/kitchen"
+	"backend-challenge/internal/slots"
 
 	"github.com/stretchr/testify/assert/yaml"
 )
 
 type Config struct {
+	// Db is the SQLite database file, or a DSN with go-sqlite3 options. Orders
+	// take stock in a transaction, so concurrent writers should wait for each
+	// other (_busy_timeout) and take the write lock up front (_txlock=immediate).
 	Db         string   `yaml:"db" validate:"required"`
 	CouponBase []string `yaml:"couponBase" validate:"required"`
 	CouponMin  int      `yaml:"couponMin" validate:"required"`
+	// CouponDiscount is the percentage of the subtotal taken off by a valid coupon.
+	CouponDiscount float32 `yaml:"couponDiscount"`
+	// QuoteTTL is how long quoted prices are guaranteed, e.g. "15m".
+	QuoteTTL time.Duration `yaml:"quoteTTL"`
+	// CartTTL is how long a cart survives without being modified, e.g. "30m".
+	CartTTL time.Duration `yaml:"cartTTL"`
+	// AsyncOrders answers every PlaceOrder with 202 and finishes the order in
+	// the background, not only those sent with Prefer: respond-async.
+	AsyncOrders bool `yaml:"asyncOrders"`
+	// CouponRelease decides when a refund gives an order's coupon back:
+	// "never", "cancel" (on cancellation) or "refund" (also on a full refund).
+	CouponRelease string `yaml:"couponRelease"`
+	// WebhookAttempts is how many times an event is sent before its delivery is dead.
+	WebhookAttempts int `yaml:"webhookAttempts"`
+	// WebhookBackoff is the wait after a webhook's first failure; it doubles
+	// after each further failure up to WebhookMaxBackoff.
+	WebhookBackoff    time.Duration `yaml:"webhookBackoff"`
+	WebhookMaxBackoff time.Duration `yaml:"webhookMaxBackoff"`
+	// StreamReplay is how many recent order events are kept for SSE clients
+	// resuming with Last-Event-ID.
+	StreamReplay int `yaml:"streamReplay"`
+	// StreamHeartbeat is how often an idle SSE stream is sent a comment, e.g. "15s".
+	StreamHeartbeat time.Duration `yaml:"streamHeartbeat"`
+	// Fulfilment limits when each fulfilment type (dine_in, takeaway, delivery)
+	// can be chosen. Types that aren't listed are always available.
+	Fulfilment map[string]FulfilmentAvailability `yaml:"fulfilment"`
+	// Delivery lists the zones delivery orders are priced by. Without zones
+	// here, the delivery_zones table is used, and without either delivery is
+	// free everywhere.
+	Delivery delivery.Config `yaml:"delivery"`
+	// Slots divides the day into the pickup slots orders can be scheduled
+	// for. Without a slot length, orders can't be scheduled ahead.
+	Slots slots.Config `yaml:"slots"`
+	// Kitchen maps products and categories to the stations that prepare
+	// them. Anything not mapped is prepared at a station named after its
+	// category.
+	Kitchen kitchen.Config `yaml:"kitchen"`
+	// ImageBaseURL is the URL stored product image paths are relative to.
+	// Images stored as absolute URLs are served as they are.
+	ImageBaseURL string `yaml:"imageBaseURL"`
+	// Images says where uploaded product pictures are kept and the sizes
+	// they are cut to. Without a directory, uploads are turned off.
+	Images images.Config `yaml:"images"`
+	// AdminKeys are the API keys, sent in the api_key header, that may be
+	// used for every operation the API spec secures. Keys are secrets, so
+	// deployments give them in ADMIN_KEYS, separated by commas, rather than
+	// here.
+	AdminKeys []string `yaml:"adminKeys"`
+	// APIKeys are further keys by the scope they may be used for, e.g.
+	// create_order for a storefront or kitchen for the kitchen screens. An
+	// operation whose scope has neither these nor admin keys is closed.
+	// API_KEYS adds to them, as scope=key pairs separated by commas.
+	APIKeys map[string][]string `yaml:"apiKeys"`
+	// CatalogCheck is how often the in-memory catalog is checked against the
+	// database for changes made outside this server, e.g. "30s". Without it
+	// the catalog is read from the database every time.
+	CatalogCheck time.Duration `yaml:"catalogCheck"`
+	// ProductCacheControl is the Cache-Control header product reads are
+	// sent with, e.g. "no-cache" to have clients check their copy with its
+	// ETag every time. Without it none is sent.
+	ProductCacheControl string `yaml:"productCacheControl"`
+}
+
+// FulfilmentAvailability switches a fulfilment type off, or limits it to the
+// local times of day from From until Until, e.g. "10:00" and "21:00".
+type FulfilmentAvailability struct {
+	Disabled bool   `yaml:"disabled"`
+	From     string `yaml:"from"`
+	Until    string `yaml:"until"`
 }
 
+// GetConfig reads the configuration and checks what the server needs of it,
+// exiting if anything is wrong.
 func GetConfig() Config {
+	config := ReadConfig()
+	if len(config.CouponBase) < config.CouponMin {
+		log.Fatalf("Minimum %d couponBase file are required", config.CouponMin)
+	}
+	for _, file := range config.CouponBase {
+		if _, err := os.Stat(file); err != nil {
+			log.Fatalf("couponBase: %s doesn't exist", file)
+		}
+	}
+	if err := config.addKeys(os.Getenv("ADMIN_KEYS"), os.Getenv("API_KEYS")); err != nil {
+		log.Fatal(err)
+	}
+	for kind, availability := range config.Fulfilment {
+		for _, value := range []string{availability.From, availability.Until} {
+			if _, err := time.Parse("15:04", value); value != "" && err != nil {
+				log.Fatalf("fulfilment.%s: %q is not a time of day like 21:00", kind, value)
+			}
+		}
+	}
+	return config
+}
+
+// addKeys adds the keys given in ADMIN_KEYS, admin, and API_KEYS, scoped, to
+// those of config.yaml.
+func (c *Config) addKeys(admin, scoped string) error {
+	for _, key := range strings.Split(admin, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			c.AdminKeys = append(c.AdminKeys, key)
+		}
+	}
+	for _, pair := range strings.Split(scoped, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		scope, key, ok := strings.Cut(pair, "=")
+		scope, key = strings.TrimSpace(scope), strings.TrimSpace(key)
+		if !ok || scope == "" || key == "" {
+			return fmt.Errorf("API_KEYS: %q is not a scope=key pair", pair)
+		}
+		if c.APIKeys == nil {
+			c.APIKeys = make(map[string][]string)
+		}
+		c.APIKeys[scope] = append(c.APIKeys[scope], key)
+	}
+	return nil
+}
+
+// ReadConfig reads config.yaml without checking it, for tools that only need
+// some of it, such as the database.
+func ReadConfig() Config {
 	_, filename, _, ok := runtime.Caller(0)
 	if !ok {
 		log.Fatal("Failed to Setup DB")
@@ -33,13 +165,5 @@ func GetConfig() Config {
 	if err != nil {
 		log.Fatalf("Error unmarshalling YAML: %v", err)
 	}
-	if len(config.CouponBase) < config.CouponMin {
-		log.Fatalf("Minimum %d couponBase file are required", config.CouponMin)
-	}
-	for _, file := range config.CouponBase {
-		if _, err := os.Stat(file); err != nil {
-			log.Fatalf("couponBase: %s doesn't exist", file)
-		}
-	}
 	return config
 }
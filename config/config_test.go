@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddKeys(t *testing.T) {
+	c := Config{AdminKeys: []string{"file"}}
+	err := c.addKeys(" ops, ,root ", "kitchen=tablet, create_order=shop,kitchen=pass,")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"file", "ops", "root"}, c.AdminKeys)
+	assert.Equal(t, map[string][]string{"kitchen": {"tablet", "pass"}, "create_order": {"shop"}}, c.APIKeys)
+
+	var none Config
+	assert.NoError(t, none.addKeys("", ""))
+	assert.Empty(t, none.AdminKeys)
+	assert.Nil(t, none.APIKeys)
+
+	for _, scoped := range []string{"tablet", "kitchen=", "=tablet"} {
+		assert.Error(t, (&Config{}).addKeys("", scoped), scoped)
+	}
+}
@@ -0,0 +1,333 @@
+// Package catalog reads and writes the product catalog as CSV or JSON files,
+// and works out and makes the changes that bring the database in line with
+// such a file.
+package catalog
+
+import (
+	"backend-challenge/internal/db"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Formats a catalog file can be in.
+const (
+	CSV  = "csv"
+	JSON = "json"
+)
+
+// FormatOf returns the format of the file at path, going by its extension.
+func FormatOf(path string) (string, error) {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case CSV, JSON:
+		return ext, nil
+	}
+	return "", fmt.Errorf("can't tell the format of %q; name it .csv or .json", path)
+}
+
+// variants are the image variants in the order files list them.
+var variants = []string{db.ImageThumbnail, db.ImageMobile, db.ImageTablet, db.ImageDesktop}
+
+// Entry is a product as a catalog file has it. A file holds the whole of each
+// product it lists: a missing description, tag or image is removed from the
+// product. Modifiers are left as they are.
+type Entry struct {
+	ID          db.ID    `json:"id"`
+	Name        string   `json:"name"`
+	Price       float32  `json:"price"`
+	Category    string   `json:"category"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Images are paths relative to the image base URL, or absolute URLs, by
+	// variant.
+	Images map[string]string `json:"images,omitempty"`
+	// Line is where the entry starts in the file it was read from.
+	Line int `json:"-"`
+}
+
+// EntryOf is the entry of a product.
+func EntryOf(p db.Product) Entry {
+	return Entry{
+		ID:          p.Id,
+		Name:        p.Name,
+		Price:       p.Price,
+		Category:    p.Category,
+		Description: p.Description,
+		Tags:        p.Tags,
+		Images:      p.Images,
+	}
+}
+
+// LineError is a problem with a catalog file, at the line it was found on.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Errors are the problems found in a catalog file, in the order of the lines
+// they were found on. A line may have several.
+type Errors []*LineError
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Read reads the entries of a catalog file in format. Every problem found
+// with them is returned together as Errors, so that a file can be fixed in
+// one go.
+func Read(r io.Reader, format string) ([]Entry, error) {
+	var entries []Entry
+	var errs Errors
+	switch format {
+	case CSV:
+		entries, errs = readCSV(r)
+	case JSON:
+		entries, errs = readJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown catalog format %q", format)
+	}
+	errs = append(errs, validate(entries)...)
+	if errs != nil {
+		slices.SortStableFunc(errs, func(a, b *LineError) int { return a.Line - b.Line })
+		return nil, errs
+	}
+	return entries, nil
+}
+
+// csvColumns are the columns of a CSV catalog, in the order Write writes
+// them. Tags are separated by semicolons.
+var csvColumns = []string{"id", "name", "price", "category", "description", "tags",
+	"image_thumbnail", "image_mobile", "image_tablet", "image_desktop"}
+
+// requiredColumns are the columns every CSV catalog needs.
+var requiredColumns = []string{"id", "name", "price", "category"}
+
+func readCSV(r io.Reader) ([]Entry, Errors) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, Errors{{Line: 1, Err: errors.New("the header row is missing")}}
+	} else if err != nil {
+		return nil, Errors{csvError(err)}
+	}
+	columns := make(map[string]int, len(header))
+	var errs Errors
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			errs = append(errs, &LineError{Line: 1, Err: fmt.Errorf("column %q is there twice", name)})
+		} else if !slices.Contains(csvColumns, name) {
+			errs = append(errs, &LineError{Line: 1, Err: fmt.Errorf("unknown column %q", name)})
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			errs = append(errs, &LineError{Line: 1, Err: fmt.Errorf("column %q is missing", name)})
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	var entries []Entry
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, append(errs, csvError(err))
+		}
+		line, _ := cr.FieldPos(0)
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		e := Entry{ID: cell("id"), Name: cell("name"), Category: cell("category"), Description: cell("description"), Line: line}
+		if price, err := strconv.ParseFloat(cell("price"), 32); err != nil {
+			errs = append(errs, &LineError{Line: line, Err: fmt.Errorf("price %q isn't a number", cell("price"))})
+		} else {
+			e.Price = float32(price)
+		}
+		if tags := cell("tags"); tags != "" {
+			for _, tag := range strings.Split(tags, ";") {
+				e.Tags = append(e.Tags, strings.TrimSpace(tag))
+			}
+		}
+		for _, variant := range variants {
+			if image := cell("image_" + variant); image != "" {
+				if e.Images == nil {
+					e.Images = make(map[string]string)
+				}
+				e.Images[variant] = image
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, errs
+}
+
+// csvError places a CSV syntax error on its line.
+func csvError(err error) *LineError {
+	var parse *csv.ParseError
+	if errors.As(err, &parse) {
+		return &LineError{Line: parse.Line, Err: parse.Err}
+	}
+	return &LineError{Line: 1, Err: err}
+}
+
+func readJSON(r io.Reader) ([]Entry, Errors) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, Errors{{Line: 1, Err: err}}
+	}
+	lineAt := func(offset int64) int {
+		return 1 + bytes.Count(data[:offset], []byte("\n"))
+	}
+	fail := func(err error, offset int64) Errors {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			offset = syntax.Offset
+		}
+		return Errors{{Line: lineAt(offset), Err: err}}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, fail(err, dec.InputOffset())
+	} else if tok != json.Delim('[') {
+		return nil, fail(errors.New("a JSON catalog is an array of products"), 0)
+	}
+	var entries []Entry
+	var errs Errors
+	for dec.More() {
+		// The entry starts after the space and comma that follow the last.
+		start := dec.InputOffset()
+		for start < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[start])) {
+			start++
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, append(errs, fail(err, start)...)
+		}
+		// Price is read apart so that a missing one isn't taken for free.
+		var e struct {
+			Entry
+			Price *float32 `json:"price"`
+		}
+		e.Line = lineAt(start)
+		entryDec := json.NewDecoder(bytes.NewReader(raw))
+		entryDec.DisallowUnknownFields()
+		if err := entryDec.Decode(&e); err != nil {
+			errs = append(errs, &LineError{Line: e.Line, Err: err})
+			continue
+		}
+		if e.Price == nil {
+			errs = append(errs, &LineError{Line: e.Line, Err: errors.New("price is missing")})
+			continue
+		}
+		e.Entry.Price = *e.Price
+		e.ID, e.Name, e.Category = strings.TrimSpace(e.ID), strings.TrimSpace(e.Name), strings.TrimSpace(e.Category)
+		entries = append(entries, e.Entry)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, append(errs, fail(err, dec.InputOffset())...)
+	}
+	return entries, errs
+}
+
+// validate checks the entries of a file against what the catalog takes.
+func validate(entries []Entry) Errors {
+	var errs Errors
+	lines := make(map[db.ID]int, len(entries))
+	for _, e := range entries {
+		fail := func(format string, args ...any) {
+			errs = append(errs, &LineError{Line: e.Line, Err: fmt.Errorf(format, args...)})
+		}
+		switch first, ok := lines[e.ID]; {
+		case !db.ValidID(e.ID):
+			fail("id %q isn't 1 to 64 letters, digits, '-', '.', '_' or '~'", e.ID)
+		case ok:
+			fail("product %s is on line %d too", e.ID, first)
+		default:
+			lines[e.ID] = e.Line
+		}
+		if e.Name == "" {
+			fail("name is missing")
+		}
+		if e.Price < 0 || math.IsInf(float64(e.Price), 0) || math.IsNaN(float64(e.Price)) {
+			fail("price %v isn't a price", e.Price)
+		}
+		if db.Slug(e.Category) == "" {
+			fail("category %q has no letters or digits", e.Category)
+		}
+		for _, tag := range e.Tags {
+			if strings.TrimSpace(tag) == "" {
+				fail("tags can't be empty")
+				break
+			}
+		}
+		for variant, image := range e.Images {
+			if !slices.Contains(variants, variant) {
+				fail("unknown image variant %q", variant)
+			} else if strings.TrimSpace(image) == "" {
+				fail("the %s image is empty", variant)
+			}
+		}
+	}
+	return errs
+}
+
+// Write writes products as a catalog file in format, which Read reads back.
+func Write(w io.Writer, format string, products []db.Product) error {
+	switch format {
+	case CSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvColumns); err != nil {
+			return err
+		}
+		for _, p := range products {
+			record := []string{p.Id, p.Name, strconv.FormatFloat(float64(p.Price), 'f', -1, 32), p.Category,
+				p.Description, strings.Join(p.Tags, ";")}
+			for _, variant := range variants {
+				record = append(record, p.Images[variant])
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case JSON:
+		entries := make([]Entry, len(products))
+		for i, p := range products {
+			entries[i] = EntryOf(p)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	return fmt.Errorf("unknown catalog format %q", format)
+}
@@ -0,0 +1,111 @@
+package catalog_test
+
+import (
+	"backend-challenge/internal/catalog"
+	"backend-challenge/internal/db"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	waffle := catalog.Entry{ID: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle", Tags: []string{"sweet", "fruit"},
+		Images: map[string]string{db.ImageThumbnail: "waffle.jpg"}, Line: 2}
+	tests := []struct {
+		format string
+		file   string
+		want   []catalog.Entry
+	}{
+		{format: catalog.CSV, file: `id,name,price,category,tags,image_thumbnail
+1,Waffle with Berries,6.5,Waffle,sweet; fruit,waffle.jpg
+lemon-tart, Lemon Tart ,5,Tart,,
+`,
+			want: []catalog.Entry{waffle, {ID: "lemon-tart", Name: "Lemon Tart", Price: 5, Category: "Tart", Line: 3}}},
+		{format: catalog.JSON, file: `[
+  {"id": "1", "name": "Waffle with Berries", "price": 6.5, "category": "Waffle",
+   "tags": ["sweet", "fruit"], "images": {"thumbnail": "waffle.jpg"}},
+  {"id": "lemon-tart", "name": "Lemon Tart", "price": 5, "category": "Tart"}
+]`,
+			want: []catalog.Entry{waffle, {ID: "lemon-tart", Name: "Lemon Tart", Price: 5, Category: "Tart", Line: 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			entries, err := catalog.Read(strings.NewReader(tt.file), tt.format)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, entries)
+		})
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		want   []string
+	}{
+		{name: "csv header", format: catalog.CSV, file: "id,name,colour\n",
+			want: []string{`line 1: unknown column "colour"`, `line 1: column "price" is missing`, `line 1: column "category" is missing`}},
+		{name: "csv rows", format: catalog.CSV, file: "id,name,price,category\n1,Waffle,abc,Waffle\nbad id,,1,!!\n1,Again,-2,Waffle\n",
+			want: []string{
+				`line 2: price "abc" isn't a number`,
+				`line 3: id "bad id" isn't 1 to 64 letters, digits, '-', '.', '_' or '~'`,
+				"line 3: name is missing",
+				`line 3: category "!!" has no letters or digits`,
+				"line 4: product 1 is on line 2 too",
+				"line 4: price -2 isn't a price",
+			}},
+		{name: "csv syntax", format: catalog.CSV, file: "id,name,price,category\n1,Waffle,1\n",
+			want: []string{"line 2: wrong number of fields"}},
+		{name: "json entries", format: catalog.JSON, file: "[\n  {\"id\": \"1\", \"name\": \"Waffle\", \"category\": \"Waffle\"},\n  {\"id\": \"2\", \"name\": \"Pie\", \"price\": 1, \"category\": \"Pie\", \"colour\": \"red\"},\n  {\"id\": \"3\", \"name\": \"Tart\", \"price\": 1, \"category\": \"Tart\", \"images\": {\"huge\": \"tart.jpg\"}}\n]",
+			want: []string{
+				"line 2: price is missing",
+				`line 3: json: unknown field "colour"`,
+				`line 4: unknown image variant "huge"`,
+			}},
+		{name: "json syntax", format: catalog.JSON, file: "[\n  {\"id\": \"1\"},\n  {\"id\": }\n]",
+			want: []string{"line 2: price is missing", "line 3: invalid character '}' after array element"}},
+		{name: "json object", format: catalog.JSON, file: `{"id": "1"}`,
+			want: []string{"line 1: a JSON catalog is an array of products"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := catalog.Read(strings.NewReader(tt.file), tt.format)
+			var errs catalog.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("want catalog.Errors, got %v", err)
+			}
+			assert.Equal(t, strings.Join(tt.want, "\n"), errs.Error())
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	products := []db.Product{
+		{Id: "1", Name: "Waffle, with Berries", Price: 6.5, Category: "Waffle", Tags: []string{"sweet", "fruit"},
+			Images: map[string]string{db.ImageThumbnail: "waffle.jpg", db.ImageDesktop: "https://cdn.example.com/waffle.jpg"}},
+		{Id: "2", Name: "Vanilla Bean Crème Brûlée", Price: 7.3, Category: "Crème Brûlée", Description: "Creamy"},
+	}
+	for _, format := range []string{catalog.CSV, catalog.JSON} {
+		var buf bytes.Buffer
+		assert.NoError(t, catalog.Write(&buf, format, products), format)
+		// What is written reads back as it was.
+		entries, err := catalog.Read(&buf, format)
+		assert.NoError(t, err, format)
+		for i, e := range entries {
+			e.Line = 0
+			assert.Equal(t, catalog.EntryOf(products[i]), e, format)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	format, err := catalog.FormatOf("menu.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, catalog.CSV, format)
+	_, err = catalog.FormatOf("menu.xlsx")
+	assert.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package catalog
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of change an import makes to a product.
+const (
+	Create = "create"
+	Update = "update"
+	// Restore puts an archived product back on the menu, updating it too if
+	// the file has it otherwise.
+	Restore = "restore"
+	Archive = "archive"
+)
+
+// Change is what importing a file does to one product.
+type Change struct {
+	Kind string
+	// Entry is what the product becomes. For Archive it is the product as
+	// it is, and has no line.
+	Entry Entry
+	// Version is the version of the product the change was worked out
+	// from; 0 for Create. Applying the change fails if it has moved on.
+	Version int64
+	// Diffs describe what an Update or Restore changes, field by field.
+	Diffs []string
+	// fields is set when more than the images change.
+	fields bool
+	// images are the variants whose image changes.
+	images []string
+}
+
+func (c Change) String() string {
+	mark := map[string]string{Create: "+", Update: "~", Restore: "~", Archive: "-"}[c.Kind]
+	s := fmt.Sprintf("%s %s %s %q", mark, c.Kind, c.Entry.ID, c.Entry.Name)
+	if c.Entry.Line > 0 {
+		s += fmt.Sprintf(" (line %d)", c.Entry.Line)
+	}
+	for _, diff := range c.Diffs {
+		s += "\n    " + diff
+	}
+	return s
+}
+
+// Plan works out the changes importing entries makes to the catalog: products
+// the file has that the catalog doesn't are created, and those it has
+// otherwise are updated. With prune, products on sale that the file doesn't
+// have are archived; without it they are left alone. Products that wouldn't
+// change have no Change.
+func Plan(ctx context.Context, products db.ProductDao, entries []Entry, prune bool) ([]Change, error) {
+	ids := make([]db.ID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	current, err := products.GetProductsByIDs(ctx, ids)
+	var unknown *db.UnknownProductsError
+	if err != nil && !errors.As(err, &unknown) {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, e := range entries {
+		p, ok := current[e.ID]
+		if !ok {
+			changes = append(changes, Change{Kind: Create, Entry: e, images: imageVariants(e.Images)})
+			continue
+		}
+		c := Change{Kind: Update, Entry: e, Version: p.Version}
+		c.Diffs, c.fields, c.images = diff(p, e)
+		if !p.ArchivedAt.IsZero() {
+			c.Kind = Restore
+		} else if len(c.Diffs) == 0 {
+			continue
+		}
+		changes = append(changes, c)
+	}
+
+	if prune {
+		onSale, err := products.GetAllProducts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range onSale {
+			if !slices.Contains(ids, p.Id) {
+				changes = append(changes, Change{Kind: Archive, Entry: EntryOf(p), Version: p.Version})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// diff describes how e differs from the product p. It reports whether any
+// field besides the images changes, and returns the variants whose image
+// does.
+func diff(p db.Product, e Entry) (diffs []string, fields bool, images []string) {
+	if p.Name != e.Name {
+		diffs = append(diffs, fmt.Sprintf("name: %q -> %q", p.Name, e.Name))
+	}
+	if p.Price != e.Price {
+		diffs = append(diffs, fmt.Sprintf("price: %v -> %v", p.Price, e.Price))
+	}
+	if !sameCategory(p.Category, e.Category) {
+		diffs = append(diffs, fmt.Sprintf("category: %q -> %q", p.Category, e.Category))
+	}
+	if p.Description != e.Description {
+		diffs = append(diffs, fmt.Sprintf("description: %q -> %q", p.Description, e.Description))
+	}
+	if !slices.Equal(p.Tags, e.Tags) {
+		diffs = append(diffs, fmt.Sprintf("tags: [%s] -> [%s]", strings.Join(p.Tags, ", "), strings.Join(e.Tags, ", ")))
+	}
+	fields = len(diffs) > 0
+	for _, variant := range variants {
+		before, after := p.Images[variant], e.Images[variant]
+		if before == after {
+			continue
+		}
+		images = append(images, variant)
+		switch {
+		case before == "":
+			diffs = append(diffs, fmt.Sprintf("%s image: added %q", variant, after))
+		case after == "":
+			diffs = append(diffs, fmt.Sprintf("%s image: removed %q", variant, before))
+		default:
+			diffs = append(diffs, fmt.Sprintf("%s image: %q -> %q", variant, before, after))
+		}
+	}
+	return diffs, fields, images
+}
+
+// sameCategory reports whether two category names are one category, the way
+// products are put in categories: by slug, taking plurals for the singular.
+func sameCategory(a, b string) bool {
+	a, b = db.Slug(a), db.Slug(b)
+	return a == b || a+"s" == b || b+"s" == a
+}
+
+func imageVariants(images map[string]string) []string {
+	var changed []string
+	for _, variant := range variants {
+		if images[variant] != "" {
+			changed = append(changed, variant)
+		}
+	}
+	return changed
+}
+
+// Apply makes changes, in order, through products. Each product is written
+// on its own, so an error leaves the changes before it made; importing the
+// file again picks up where it stopped. A product changed by someone else
+// since the changes were planned fails with *db.ProductVersionError.
+func Apply(ctx context.Context, products db.ProductDao, changes []Change) error {
+	for _, c := range changes {
+		if err := apply(ctx, products, c); err != nil {
+			return fmt.Errorf("%s %s: %w", c.Kind, c.Entry.ID, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, products db.ProductDao, c Change) error {
+	p := db.Product{
+		Id:          c.Entry.ID,
+		Name:        c.Entry.Name,
+		Price:       c.Entry.Price,
+		Category:    c.Entry.Category,
+		Description: c.Entry.Description,
+		Tags:        c.Entry.Tags,
+	}
+	version := c.Version
+	switch c.Kind {
+	case Archive:
+		_, err := products.SetProductArchived(ctx, p.Id, true, version)
+		return err
+	case Create:
+		if _, err := products.CreateProduct(ctx, p); err != nil {
+			return err
+		}
+	case Update, Restore:
+		if c.fields {
+			updated, err := products.UpdateProduct(ctx, p, version)
+			if err != nil {
+				return err
+			}
+			version = updated.Version
+		}
+		if c.Kind == Restore {
+			if _, err := products.SetProductArchived(ctx, p.Id, false, version); err != nil {
+				return err
+			}
+		}
+	}
+	for _, variant := range c.images {
+		var err error
+		if image := c.Entry.Images[variant]; image != "" {
+			err = products.SetProductImage(ctx, p.Id, variant, image)
+		} else {
+			err = products.DeleteProductImage(ctx, p.Id, variant)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
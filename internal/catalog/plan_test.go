@@ -0,0 +1,92 @@
+package catalog_test
+
+import (
+	"backend-challenge/internal/catalog"
+	"backend-challenge/internal/db"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	ctx := context.Background()
+	if err := db.Migrate(ctx, sqlDB); err != nil {
+		t.Fatalf("db.Migrate failed: %v", err)
+	}
+	products := db.NewProductDao(sqlDB)
+	for _, p := range []db.Product{
+		{Id: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffles"},
+		{Id: "2", Name: "Vanilla Bean Crème Brûlée", Price: 7, Category: "Crème Brûlée"},
+		{Id: "3", Name: "Macaron Mix of Five", Price: 8, Category: "Macaron"},
+		{Id: "4", Name: "Classic Tiramisu", Price: 5.5, Category: "Tiramisu"},
+	} {
+		_, err := products.CreateProduct(ctx, p)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, products.SetProductImage(ctx, "2", db.ImageThumbnail, "creme-brulee.jpg"))
+	_, err = products.SetProductArchived(ctx, "4", true, 0)
+	assert.NoError(t, err)
+
+	entries, err := catalog.Read(strings.NewReader(`id,name,price,category,tags,image_thumbnail
+1,Waffle with Berries,6.5,Waffle,,
+2,Vanilla Bean Crème Brûlée,7.5,Crème Brûlée,,
+4,Classic Tiramisu,5.5,Tiramisu,,
+lemon-tart,Lemon Tart,5,Tart,lemon;tart,lemon-tart.jpg
+`), catalog.CSV)
+	assert.NoError(t, err)
+
+	changes, err := catalog.Plan(ctx, products, entries, false)
+	assert.NoError(t, err)
+	var kinds []string
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind+" "+c.Entry.ID)
+	}
+	// Waffle is the Waffles category, so product 1 doesn't change.
+	assert.Equal(t, []string{"update 2", "restore 4", "create lemon-tart"}, kinds)
+	assert.Equal(t, `~ update 2 "Vanilla Bean Crème Brûlée" (line 3)
+    price: 7 -> 7.5
+    thumbnail image: removed "creme-brulee.jpg"`, changes[0].String())
+	pruned, err := catalog.Plan(ctx, products, entries, true)
+	assert.NoError(t, err)
+	assert.Equal(t, `- archive 3 "Macaron Mix of Five"`, pruned[len(pruned)-1].String())
+
+	// Planning changes nothing; applying them brings the catalog in line
+	// with the file, after which there is nothing left to do.
+	assert.NoError(t, catalog.Apply(ctx, products, pruned))
+	onSale, err := products.GetAllProducts(ctx)
+	assert.NoError(t, err)
+	var ids []db.ID
+	for _, p := range onSale {
+		ids = append(ids, p.Id)
+	}
+	assert.ElementsMatch(t, []db.ID{"1", "2", "4", "lemon-tart"}, ids)
+	p, err := products.GetProduct(ctx, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(7.5), p.Price)
+	assert.Empty(t, p.Images)
+	p, err = products.GetProduct(ctx, "lemon-tart")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lemon", "tart"}, p.Tags)
+	assert.Equal(t, map[string]string{db.ImageThumbnail: "lemon-tart.jpg"}, p.Images)
+	changes, err = catalog.Plan(ctx, products, entries, true)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Products changed after the changes were planned aren't overwritten.
+	entries[0].Price = 7
+	changes, err = catalog.Plan(ctx, products, entries, false)
+	assert.NoError(t, err)
+	_, err = products.UpdateProduct(ctx, db.Product{Id: "1", Name: "Waffle with Berries", Price: 6.9, Category: "Waffles"}, 0)
+	assert.NoError(t, err)
+	var stale *db.ProductVersionError
+	assert.ErrorAs(t, catalog.Apply(ctx, products, changes), &stale)
+}
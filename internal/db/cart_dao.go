@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CartDaoImpl struct {
+	db *sql.DB
+}
+
+var _ CartDao = &CartDaoImpl{}
+
+func NewCartDao(db *sql.DB) CartDao {
+	return &CartDaoImpl{db: db}
+}
+
+// CreateCart implements CartDao.
+func (c *CartDaoImpl) CreateCart(ctx context.Context, cart Cart) error {
+	validate := validator.New()
+	if err := validate.Struct(cart); err != nil {
+		return err
+	}
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "INSERT INTO carts (id, coupon_code, updated_at) VALUES (?, ?, ?)",
+		cart.ID, cart.CouponCode, time.Now().UTC()); err != nil {
+		return err
+	}
+	for _, item := range cart.Items {
+		modifiers, err := modifierIDs(item)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO cart_items (cart_id, product_id, quantity, modifiers, note) VALUES (?, ?, ?, ?, ?)",
+			cart.ID, item.ProductID, item.Quantity, modifiers, item.Note); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetCart implements CartDao. Lines are returned in the order they were first added.
+func (c *CartDaoImpl) GetCart(ctx context.Context, id ID) (Cart, error) {
+	var cart Cart
+	row := c.db.QueryRowContext(ctx, "SELECT id, coupon_code, updated_at FROM carts WHERE id = ?", id)
+	if err := row.Scan(&cart.ID, &cart.CouponCode, &cart.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Cart{}, ErrNotFound(err)
+		}
+		return Cart{}, err
+	}
+	rows, err := c.db.QueryContext(ctx, "SELECT product_id, quantity, modifiers, note FROM cart_items WHERE cart_id = ? ORDER BY rowid", id)
+	if err != nil {
+		return Cart{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item Item
+		var modifiersJSON []byte
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &modifiersJSON, &item.Note); err != nil {
+			return Cart{}, err
+		}
+		var ids []ID
+		if err := json.Unmarshal(modifiersJSON, &ids); err != nil {
+			return Cart{}, err
+		}
+		for _, id := range ids {
+			item.Modifiers = append(item.Modifiers, Modifier{ID: id})
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	return cart, rows.Err()
+}
+
+// modifierIDs is the JSON array of the IDs of a cart line's modifiers.
+func modifierIDs(item Item) ([]byte, error) {
+	ids := make([]ID, 0, len(item.Modifiers))
+	for _, modifier := range item.Modifiers {
+		ids = append(ids, modifier.ID)
+	}
+	return json.Marshal(ids)
+}
+
+// touch refreshes the cart's activity time inside tx, failing if the cart doesn't exist.
+func touch(ctx context.Context, tx *sql.Tx, cartID ID) error {
+	res, err := tx.ExecContext(ctx, "UPDATE carts SET updated_at = ? WHERE id = ?", time.Now().UTC(), cartID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound(sql.ErrNoRows)
+	}
+	return nil
+}
+
+// writeCart runs write against the cart inside a transaction after refreshing its activity time.
+func (c *CartDaoImpl) writeCart(ctx context.Context, cartID ID, query string, args ...any) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := touch(ctx, tx, cartID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetCartItem implements CartDao.
+func (c *CartDaoImpl) SetCartItem(ctx context.Context, cartID ID, item Item) error {
+	validate := validator.New()
+	if err := validate.Struct(item); err != nil {
+		return err
+	}
+	modifiers, err := modifierIDs(item)
+	if err != nil {
+		return err
+	}
+	return c.writeCart(ctx, cartID,
+		`INSERT INTO cart_items (cart_id, product_id, quantity, modifiers, note) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (cart_id, product_id) DO UPDATE SET
+			quantity = excluded.quantity, modifiers = excluded.modifiers, note = excluded.note`,
+		cartID, item.ProductID, item.Quantity, modifiers, item.Note)
+}
+
+// RemoveCartItem implements CartDao.
+func (c *CartDaoImpl) RemoveCartItem(ctx context.Context, cartID ID, productID ID) error {
+	return c.writeCart(ctx, cartID, "DELETE FROM cart_items WHERE cart_id = ? AND product_id = ?", cartID, productID)
+}
+
+// SetCartCoupon implements CartDao. An empty code removes the coupon.
+func (c *CartDaoImpl) SetCartCoupon(ctx context.Context, cartID ID, couponCode string) error {
+	return c.writeCart(ctx, cartID, "UPDATE carts SET coupon_code = ? WHERE id = ?", couponCode, cartID)
+}
+
+// DeleteCart implements CartDao.
+func (c *CartDaoImpl) DeleteCart(ctx context.Context, id ID) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM carts WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteCartsIdleSince implements CartDao.
+func (c *CartDaoImpl) DeleteCartsIdleSince(ctx context.Context, since time.Time) (int64, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	since = since.UTC()
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM cart_items WHERE cart_id IN (SELECT id FROM carts WHERE updated_at < ?)", since); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, "DELETE FROM carts WHERE updated_at < ?", since)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
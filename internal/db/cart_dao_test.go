@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"backend-challenge/internal/db"
+)
+
+func TestCartDao(t *testing.T) {
+	ctx := context.Background()
+	cartDao := db.NewCartDao(setupTestDB(t))
+	assert.NoError(t, cartDao.CreateCart(ctx, db.Cart{ID: "cart-1"}))
+
+	assert.NoError(t, cartDao.SetCartItem(ctx, "cart-1", db.Item{ProductID: "2", Quantity: 1, Note: "no nuts"}))
+	assert.NoError(t, cartDao.SetCartItem(ctx, "cart-1", db.Item{ProductID: "1", Quantity: 1}))
+	large := []db.Modifier{{ID: "large"}, {ID: "extra-syrup"}}
+	assert.NoError(t, cartDao.SetCartItem(ctx, "cart-1", db.Item{ProductID: "2", Quantity: 3, Modifiers: large}))
+	assert.Error(t, cartDao.SetCartItem(ctx, "cart-1", db.Item{ProductID: "3"}), "zero quantity")
+	assert.NoError(t, cartDao.SetCartCoupon(ctx, "cart-1", "HAPPYHRS"))
+
+	cart, err := cartDao.GetCart(ctx, "cart-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "HAPPYHRS", cart.CouponCode)
+	assert.Equal(t, []db.Item{{ProductID: "2", Quantity: 3, Modifiers: large}, {ProductID: "1", Quantity: 1}}, cart.Items, "setting a line replaces it")
+	assert.WithinDuration(t, time.Now(), cart.UpdatedAt, 5*time.Second)
+
+	assert.NoError(t, cartDao.RemoveCartItem(ctx, "cart-1", "2"))
+	cart, err = cartDao.GetCart(ctx, "cart-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []db.Item{{ProductID: "1", Quantity: 1}}, cart.Items)
+
+	assert.ErrorIs(t, cartDao.SetCartItem(ctx, "missing", db.Item{ProductID: "1", Quantity: 1}), sql.ErrNoRows)
+	assert.ErrorIs(t, cartDao.SetCartCoupon(ctx, "missing", "HAPPYHRS"), sql.ErrNoRows)
+	_, err = cartDao.GetCart(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.NoError(t, cartDao.DeleteCart(ctx, "cart-1"))
+	_, err = cartDao.GetCart(ctx, "cart-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCartDao_DeleteCartsIdleSince(t *testing.T) {
+	ctx := context.Background()
+	cartDao := db.NewCartDao(setupTestDB(t))
+	assert.NoError(t, cartDao.CreateCart(ctx, db.Cart{ID: "idle", Items: []db.Item{{ProductID: "1", Quantity: 1}}}))
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, cartDao.CreateCart(ctx, db.Cart{ID: "active"}))
+
+	n, err := cartDao.DeleteCartsIdleSince(ctx, cutoff)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = cartDao.GetCart(ctx, "idle")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = cartDao.GetCart(ctx, "active")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+type CategoryDaoImpl struct {
+	db *sql.DB
+}
+
+var _ CategoryDao = &CategoryDaoImpl{}
+
+func NewCategoryDao(db *sql.DB) CategoryDao {
+	return &CategoryDaoImpl{db: db}
+}
+
+// hiddenCategories selects the IDs of the categories that aren't visible,
+// themselves or through a category they are under.
+const hiddenCategories = `WITH RECURSIVE hidden(id) AS (
+		SELECT id FROM categories WHERE NOT visible
+		UNION SELECT categories.id FROM categories JOIN hidden ON categories.parent_id = hidden.id)
+	SELECT id FROM hidden`
+
+// categoryTree selects the IDs of the category with the slug bound to it and
+// of the categories under it.
+const categoryTree = `WITH RECURSIVE tree(id) AS (
+		SELECT id FROM categories WHERE slug = ?
+		UNION SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id)
+	SELECT id FROM tree`
+
+const categoryColumns = `c.id, c.slug, c.name, c.position, COALESCE(parent.slug, ''),
+	c.id NOT IN (` + hiddenCategories + `)`
+
+const categoryFrom = " FROM categories c LEFT JOIN categories parent ON parent.id = c.parent_id"
+
+func scanCategory(row interface{ Scan(...any) error }) (Category, error) {
+	var c Category
+	err := row.Scan(&c.ID, &c.Slug, &c.Name, &c.Position, &c.ParentSlug, &c.Visible)
+	return c, err
+}
+
+// GetCategories implements CategoryDao.
+func (d *CategoryDaoImpl) GetCategories(ctx context.Context, all bool) ([]Category, error) {
+	query := "SELECT " + categoryColumns + categoryFrom
+	if !all {
+		query += " WHERE c.id NOT IN (" + hiddenCategories + ")"
+	}
+	rows, err := d.db.QueryContext(ctx, query+" ORDER BY c.position, c.name, c.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var categories []Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// GetCategory implements CategoryDao.
+func (d *CategoryDaoImpl) GetCategory(ctx context.Context, slug string) (Category, error) {
+	c, err := scanCategory(d.db.QueryRowContext(ctx, "SELECT "+categoryColumns+categoryFrom+" WHERE c.slug = ?", slug))
+	if err != nil {
+		return Category{}, ErrNotFound(err)
+	}
+	return c, nil
+}
+
+// Slug makes the slug of a category or product name: lower case ASCII
+// letters and digits, with dashes between words.
+func Slug(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(unaccent.Replace(name)) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return slug.String()
+}
+
+// unaccent drops the accents of the letters menus use most.
+var unaccent = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "ö", "o", "õ", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y",
+	"À", "A", "Á", "A", "Â", "A", "Ä", "A", "Ã", "A", "Å", "A",
+	"Ç", "C", "È", "E", "É", "E", "Ê", "E", "Ë", "E",
+	"Ì", "I", "Í", "I", "Î", "I", "Ï", "I", "Ñ", "N",
+	"Ò", "O", "Ó", "O", "Ô", "O", "Ö", "O", "Õ", "O",
+	"Ù", "U", "Ú", "U", "Û", "U", "Ü", "U", "Ý", "Y",
+)
+
+// categoryFor finds the category a product named as being in the category
+// name belongs to, creating it at the end of the menu if there is none. Names
+// are the same category when their slugs are, or when one is the other's
+// plural, so "Waffle" and "waffles" are one category. It returns the
+// category's ID and name.
+func categoryFor(ctx context.Context, tx *sql.Tx, name string) (int64, string, error) {
+	slug := Slug(name)
+	if slug == "" {
+		return 0, "", &InvalidCategoryError{Name: name}
+	}
+	var id int64
+	var existing string
+	err := tx.QueryRowContext(ctx, `SELECT id, name FROM categories WHERE slug IN (?, ?, ?)
+		ORDER BY slug = ? DESC, id LIMIT 1`, slug, slug+"s", strings.TrimSuffix(slug, "s"), slug).Scan(&id, &existing)
+	if err == nil {
+		return id, existing, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return 0, "", err
+	}
+	err = tx.QueryRowContext(ctx, `INSERT INTO categories (slug, name, position)
+		SELECT ?, ?, COALESCE(MAX(position), 0) + 1 FROM categories RETURNING id`, slug, strings.TrimSpace(name)).Scan(&id)
+	return id, strings.TrimSpace(name), err
+}
+
+// linkCategories puts the products that aren't in a category yet in the one
+// their category name belongs to, and names the category the same way on
+// all of them. Categories are created in the order their first product was
+// added.
+func linkCategories(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, `SELECT category FROM products
+		WHERE category_id IS NULL AND category IS NOT NULL
+		GROUP BY category ORDER BY MIN(rowid)`)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if Slug(name) == "" {
+			continue
+		}
+		id, categoryName, err := categoryFor(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET category_id = ?, category = ? WHERE category = ? AND category_id IS NULL",
+			id, categoryName, name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
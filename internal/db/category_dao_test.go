@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryDao_Migrate(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	ctx := context.Background()
+	_, err := sqlDB.Exec(`INSERT INTO products (id, name, price, category) VALUES
+		('1', 'Waffle with Berries', 6.5, 'Waffles'), ('2', 'Vanilla Bean Crème Brûlée', 7, 'Crème Brûlée'),
+		('3', 'Chocolate Waffle', 7, 'waffle'), ('4', 'Bubble Tea', 4, 'Drinks')`)
+	assert.NoError(t, err)
+	// Products are put in categories when the database is opened.
+	assert.NoError(t, db.Migrate(ctx, sqlDB))
+
+	g := db.NewProductDao(sqlDB)
+	p, err := g.GetProduct(ctx, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, "Waffles", p.Category, "names of one category are made the same")
+	assert.Equal(t, "waffles", p.CategorySlug)
+
+	categories := db.NewCategoryDao(sqlDB)
+	_, err = sqlDB.Exec(`INSERT INTO categories (slug, name, position) VALUES ('hot', 'Hot', 0);
+		UPDATE categories SET parent_id = (SELECT id FROM categories WHERE slug = 'hot') WHERE slug = 'drinks';
+		UPDATE categories SET visible = FALSE WHERE slug = 'hot'`)
+	assert.NoError(t, err)
+	visible, err := categories.GetCategories(ctx, false)
+	assert.NoError(t, err)
+	var slugs []string
+	for _, c := range visible {
+		slugs = append(slugs, c.Slug)
+	}
+	assert.Equal(t, []string{"waffles", "creme-brulee"}, slugs, "in the order they were added, without hidden ones")
+	all, err := categories.GetCategories(ctx, true)
+	assert.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	drinks, err := categories.GetCategory(ctx, "drinks")
+	assert.NoError(t, err)
+	assert.Equal(t, "hot", drinks.ParentSlug)
+	assert.False(t, drinks.Visible, "hidden with its parent")
+	_, err = categories.GetCategory(ctx, "cakes")
+	assert.Error(t, err)
+
+	// New products join the category of their plural or singular name.
+	created, err := g.CreateProduct(ctx, db.Product{Name: "Plain Waffle", Price: 5, Category: "Waffle"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Waffles", created.Category)
+	_, err = g.CreateProduct(ctx, db.Product{Name: "Nothing", Price: 5, Category: "!!"})
+	var invalid *db.InvalidCategoryError
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestSlug(t *testing.T) {
+	assert.Equal(t, "creme-brulee", db.Slug(" Crème  Brûlée! "))
+	assert.Equal(t, "hot-drinks-2", db.Slug("Hot drinks #2"))
+	assert.Equal(t, "", db.Slug("☕"))
+}
@@ -1,4 +1,4 @@
-//go:generate go run github.com/golang/mock/mockgen@v1.6.0 -destination=mocks/mock_db.go -package=mocks backend-challenge/internal/db OrderDao,ProductDao,CouponDao,SearchResult
+//go:generate go run github.com/golang/mock/mockgen@v1.6.0 -destination=mocks/mock_db.go -package=mocks backend-challenge/internal/db OrderDao,ProductDao,CouponDao,SearchResult,QuoteDao,CartDao,WebhookDao,DeliveryZoneDao,StockDao,IngredientDao,SlotDao,KitchenDao,CategoryDao
 package db
 
 // NOTE: To regenerate mocks, run `go generate ./...` or `go generate` from this package.
@@ -6,25 +6,449 @@ package db
 import (
 	"backend-challenge/internal/generated/openapi"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 )
 
 type ID = string
 type ErrNotFound error
 
+// maxIDLength is the longest ID or slug ValidID accepts.
+const maxIDLength = 64
+
+// ValidID reports whether id can be the ID or slug of a product: 1 to 64
+// ASCII letters, digits and the other characters that need no escaping in a
+// URL path, "-", ".", "_" and "~".
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		case r == '-', r == '.', r == '_', r == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// UnknownProductsError lists every requested product ID that doesn't exist.
+type UnknownProductsError struct {
+	IDs []ID
+}
+
+func (e *UnknownProductsError) Error() string {
+	return fmt.Sprintf("unknown products: %s", strings.Join(e.IDs, ", "))
+}
+
+// Item is a single order line. Name, Category and UnitPrice are a snapshot of the
+// product taken when the order was placed, so later catalog edits don't change
+// what the customer was charged.
 type Item struct {
+	ProductID ID      `json:"product_id" validate:"required"`
+	Quantity  int32   `json:"quantity" validate:"gt=0"`
+	Name      string  `json:"name,omitempty"`
+	Category  string  `json:"category,omitempty"`
+	UnitPrice float32 `json:"unit_price,omitempty" validate:"gte=0"`
+	// Modifiers are the options chosen for the line, snapshotted like the product.
+	Modifiers []Modifier `json:"modifiers,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	// Images are the product's pictures when the order was placed.
+	Images map[string]string `json:"images,omitempty"`
+	// BumpedAt is when the kitchen finished the line; zero until it has. It
+	// is kept apart from the snapshot and only read.
+	BumpedAt time.Time `json:"-"`
+}
+
+// UnitTotal is the price of one unit of the line: the product plus its modifiers.
+func (i Item) UnitTotal() float32 {
+	total := i.UnitPrice
+	for _, modifier := range i.Modifiers {
+		total += modifier.PriceDelta
+	}
+	return total
+}
+
+// HasSnapshot reports whether the line carries a product snapshot. Orders
+// stored before snapshots were introduced only have the product ID.
+func (i Item) HasSnapshot() bool {
+	return i.Name != ""
+}
+
+// Order statuses. Orders placed synchronously are accepted as soon as they are
+// stored; asynchronous ones stay pending until their coupon has been checked.
+// Accepted orders become ready once the kitchen has bumped every line.
+const (
+	OrderPending   = "pending"
+	OrderAccepted  = "accepted"
+	OrderReady     = "ready"
+	OrderRejected  = "rejected"
+	OrderCancelled = "cancelled"
+)
+
+// OrderStatusError is returned when an order isn't in a state that allows the
+// requested change.
+type OrderStatusError struct {
+	ID     ID
+	Status string
+}
+
+func (e *OrderStatusError) Error() string {
+	return fmt.Sprintf("order %s is %s", e.ID, e.Status)
+}
+
+// OrderPreparingError reports that the kitchen has started on an order, so it
+// can no longer be cancelled.
+type OrderPreparingError struct {
+	ID ID
+}
+
+func (e *OrderPreparingError) Error() string {
+	return fmt.Sprintf("order %s is already being prepared", e.ID)
+}
+
+type Order struct {
+	ID         ID      `json:"id" validate:"required"`
+	Items      []Item  `json:"items" validate:"required,dive"`
+	CouponCode string  `json:"coupon_code,omitempty"`
+	Discounts  float32 `json:"discounts,omitempty" validate:"gte=0"`
+	// Status is one of the Order* constants; empty is stored as OrderAccepted.
+	Status       string `json:"status,omitempty" validate:"omitempty,oneof=pending accepted rejected"`
+	StatusReason string `json:"status_reason,omitempty"`
+	// Fulfilment is nil for orders placed without one.
+	Fulfilment *Fulfilment `json:"fulfilment,omitempty"`
+	// DeliveryFee is charged on top of the discounted items.
+	DeliveryFee float32 `json:"delivery_fee,omitempty" validate:"gte=0"`
+	// ScheduledFor is when a pre-order is wanted; zero for orders wanted now.
+	// ReleasedAt is when a scheduled order was released to the kitchen, and
+	// stays zero until then.
+	ScheduledFor time.Time `json:"scheduled_for,omitempty"`
+	ReleasedAt   time.Time `json:"released_at,omitempty"`
+	// Slot is the pickup slot a scheduled order takes a place in. It is only
+	// read by CreateOrder.
+	Slot *Slot `json:"-"`
+	// QuoteID is the quote the order is priced from. It is only read by
+	// CreateOrder, which uses the quote up.
+	QuoteID ID `json:"-"`
+	// CreatedAt is when the order was stored. CreateOrder sets it if it is
+	// zero; orders stored before it was recorded have none.
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// Slot is a pickup slot and its capacity. A zero MaxOrders or MaxItems
+// leaves that side unlimited.
+type Slot struct {
+	Start     time.Time
+	MaxOrders int32
+	MaxItems  int32
+}
+
+// SlotFullError is returned when a slot has no room left for an order.
+type SlotFullError struct {
+	Start time.Time
+}
+
+func (e *SlotFullError) Error() string {
+	return fmt.Sprintf("slot at %s is full", e.Start.Format(time.RFC3339))
+}
+
+// SlotUsage is how many orders, and items across them, a slot has taken.
+type SlotUsage struct {
+	Start  time.Time
+	Orders int32
+	Items  int32
+}
+
+// SlotDao reads the capacity scheduled orders have taken. Orders take their
+// place in a slot as part of OrderDao.CreateOrder and give it back when they
+// are cancelled or rejected.
+type SlotDao interface {
+	// GetSlotUsage returns the slots starting in [from, to) that have orders,
+	// earliest first.
+	GetSlotUsage(ctx context.Context, from, to time.Time) ([]SlotUsage, error)
+}
+
+// KitchenDao tracks the kitchen's progress through accepted orders, line by
+// line. Lines are identified by their position in the order's Items.
+type KitchenDao interface {
+	// GetOpenOrders returns the orders the kitchen is working on, oldest
+	// first: accepted orders that aren't scheduled, or have been released.
+	// Their lines carry BumpedAt.
+	GetOpenOrders(context.Context) ([]Order, error)
+	// BumpLine records that the kitchen finished a line of an accepted order.
+	// Bumping a line again keeps the first time. Once every line is bumped
+	// the order becomes ready. It fails with *OrderStatusError if the order
+	// isn't accepted, and ErrNotFound if it has no such line.
+	BumpLine(ctx context.Context, orderID ID, line int) error
+}
+
+// Fulfilment types.
+const (
+	FulfilmentDineIn   = "dine_in"
+	FulfilmentTakeaway = "takeaway"
+	FulfilmentDelivery = "delivery"
+)
+
+// Fulfilment is how an order reaches the customer. Only the fields of its
+// type are set: a table for dine-in, a name and time for takeaway and an
+// address for delivery.
+type Fulfilment struct {
+	Type        string    `json:"type" validate:"oneof=dine_in takeaway delivery"`
+	TableNumber string    `json:"table_number,omitempty" validate:"required_if=Type dine_in"`
+	PickupName  string    `json:"pickup_name,omitempty" validate:"required_if=Type takeaway"`
+	PickupTime  time.Time `json:"pickup_time,omitempty"`
+	Address     *Address  `json:"address,omitempty" validate:"required_if=Type delivery"`
+	// Zone is the delivery zone the address fell in when the order was placed.
+	Zone string `json:"zone,omitempty"`
+}
+
+// Address is where a delivery order goes.
+type Address struct {
+	Line1        string `json:"line1" validate:"required"`
+	Line2        string `json:"line2,omitempty"`
+	City         string `json:"city" validate:"required"`
+	Postcode     string `json:"postcode" validate:"required"`
+	Instructions string `json:"instructions,omitempty"`
+	// Lat and Lng locate the address for zones drawn as areas; both are 0
+	// when the address was given without coordinates.
+	Lat float64 `json:"lat,omitempty" validate:"gte=-90,lte=90"`
+	Lng float64 `json:"lng,omitempty" validate:"gte=-180,lte=180"`
+}
+
+// DeliveryZoneDao reads delivery zones kept in the database instead of the
+// config file. Each zone is a JSON object in the shape of delivery.ZoneConfig.
+type DeliveryZoneDao interface {
+	// GetDeliveryZones returns the zone definitions, most specific first.
+	GetDeliveryZones(context.Context) ([]json.RawMessage, error)
+}
+
+// Total is what the customer was charged: the line snapshots less the discount,
+// plus delivery, rounded to cents.
+func (o Order) Total() float32 {
+	var subtotal float32
+	for _, item := range o.Items {
+		subtotal += item.UnitTotal() * float32(item.Quantity)
+	}
+	return float32(math.Round(float64(subtotal-o.Discounts+o.DeliveryFee)*100) / 100)
+}
+
+// RefundLine is a quantity of one order line given back in a refund.
+type RefundLine struct {
 	ProductID ID    `json:"product_id" validate:"required"`
 	Quantity  int32 `json:"quantity" validate:"gt=0"`
 }
 
-type Order struct {
-	ID    ID     `json:"id" validate:"required"`
-	Items []Item `json:"items" validate:"required,dive"`
+// Refund gives back part or all of what was paid for an order. Lines is empty
+// for refunds of a plain amount.
+type Refund struct {
+	ID       ID           `json:"id" validate:"required"`
+	OrderID  ID           `json:"order_id" validate:"required"`
+	Amount   float32      `json:"amount" validate:"gt=0"`
+	Lines    []RefundLine `json:"lines,omitempty" validate:"dive"`
+	Reason   string       `json:"reason" validate:"required"`
+	Operator string       `json:"operator" validate:"required"`
+	// CouponReleased is set when the refund gave the order's coupon redemption back.
+	CouponReleased bool      `json:"coupon_released,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RefundLimitError is returned when a refund would take an order's refunds
+// past what was paid for it.
+type RefundLimitError struct {
+	OrderID   ID
+	Remaining float32
+}
+
+func (e *RefundLimitError) Error() string {
+	return fmt.Sprintf("order %s has only %.2f left to refund", e.OrderID, e.Remaining)
+}
+
+// RefundQuantityError is returned when refund lines would give back more of a
+// product than is left of it on the order.
+type RefundQuantityError struct {
+	OrderID   ID
+	ProductID ID
+	Remaining int32
+}
+
+func (e *RefundQuantityError) Error() string {
+	return fmt.Sprintf("order %s has only %d of product %s left to refund", e.OrderID, e.Remaining, e.ProductID)
 }
 
 // OrderDao defines the persistence operations required to persist orders in storage.
 // Implementations are responsible for validation and writing orders (ID and items) to the DB.
 type OrderDao interface {
+	// CreateOrder stores an order, takes its items out of stock, uses up
+	// the ingredients of their recipes, takes its place in its slot and uses
+	// up its quote. It fails with *OutOfStockError if a tracked product
+	// doesn't have enough, *IngredientShortageError if an ingredient doesn't,
+	// *SlotFullError if the slot doesn't, or *QuoteUsedError if another order
+	// used the quote first.
 	CreateOrder(context.Context, Order) error
+	GetOrder(context.Context, ID) (Order, error)
+	// UpdateOrderStatus records the outcome of an order placed asynchronously,
+	// OrderAccepted or OrderRejected, with the event of the same name. Only
+	// pending orders are updated; others return *OrderStatusError. Rejected
+	// orders give their stock, ingredients and slot back.
+	UpdateOrderStatus(ctx context.Context, id ID, status string, reason string) error
+	// CancelOrder cancels an order that is in one of the from statuses, recording
+	// refund in the same transaction if it isn't nil, and gives its stock,
+	// ingredients and slot back. Orders with a line bumped at the kitchen
+	// return *OrderPreparingError.
+	CancelOrder(ctx context.Context, id ID, reason string, from []string, refund *Refund) error
+	// ReleaseScheduledOrders releases the accepted scheduled orders wanted by
+	// the given time that haven't been released yet, and returns their IDs.
+	ReleaseScheduledOrders(ctx context.Context, by time.Time) ([]ID, error)
+	// CreateRefund records a refund, failing with *RefundLimitError if the
+	// order's refunds would add up to more than its total, or
+	// *RefundQuantityError if its lines would give back more of a product
+	// than was ordered. A refund with CouponReleased gives back the order's
+	// coupon redemption, if it has one; the refund is returned as stored,
+	// with CouponReleased only if there was one to give back.
+	CreateRefund(context.Context, Refund) (Refund, error)
+	// GetRefunds returns an order's refunds, oldest first.
+	GetRefunds(ctx context.Context, orderID ID) ([]Refund, error)
+	// GetRefundsBetween returns the refunds made in [from, to), oldest first.
+	GetRefundsBetween(ctx context.Context, from, to time.Time) ([]Refund, error)
+}
+
+// Quote is a priced cart held for a limited time. An order placed against an
+// unexpired quote is charged the quoted prices.
+type Quote struct {
+	ID         ID        `json:"id" validate:"required"`
+	Items      []Item    `json:"items" validate:"required,dive"`
+	CouponCode string    `json:"coupon_code,omitempty"`
+	Discounts  float32   `json:"discounts,omitempty" validate:"gte=0"`
+	ExpiresAt  time.Time `json:"expires_at" validate:"required"`
+	// UsedBy is the order placed against the quote; empty until one is.
+	UsedBy ID `json:"used_by,omitempty"`
+}
+
+// QuoteUsedError is returned by CreateOrder when the order's quote has
+// already been used by another order.
+type QuoteUsedError struct {
+	ID ID
+}
+
+func (e *QuoteUsedError) Error() string {
+	return fmt.Sprintf("quote %s has already been used", e.ID)
+}
+
+// QuoteDao persists quotes between the quote request and the order that uses it.
+type QuoteDao interface {
+	CreateQuote(context.Context, Quote) error
+	GetQuote(context.Context, ID) (Quote, error)
+}
+
+// Cart is a customer's basket kept on the server between requests. Lines hold
+// product IDs, quantities, notes and the modifiers chosen, by ID only; prices
+// are looked up when the cart is viewed. A cart has one line per product.
+type Cart struct {
+	ID         ID        `json:"id" validate:"required"`
+	Items      []Item    `json:"items" validate:"dive"`
+	CouponCode string    `json:"coupon_code,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CartDao persists carts. Every write refreshes the cart's UpdatedAt, which is
+// what inactivity expiry is measured from. Writes to an unknown cart return ErrNotFound.
+type CartDao interface {
+	CreateCart(context.Context, Cart) error
+	GetCart(context.Context, ID) (Cart, error)
+	// SetCartItem adds item to the cart, or replaces the cart's line for its
+	// product.
+	SetCartItem(ctx context.Context, cartID ID, item Item) error
+	RemoveCartItem(ctx context.Context, cartID ID, productID ID) error
+	SetCartCoupon(ctx context.Context, cartID ID, couponCode string) error
+	DeleteCart(context.Context, ID) error
+	// DeleteCartsIdleSince removes carts not written to since the given time
+	// and returns how many were removed.
+	DeleteCartsIdleSince(context.Context, time.Time) (int64, error)
+}
+
+// Event types written to the outbox. order.created carries the order as
+// placed, status changes (order.ready among them) and order.released carry the
+// order after the change and order.refunded carries the refund. product.low_stock carries the product's Stock after it
+// fell to its threshold.
+const (
+	EventOrderCreated    = "order.created"
+	EventOrderAccepted   = "order.accepted"
+	EventOrderRejected   = "order.rejected"
+	EventOrderCancelled  = "order.cancelled"
+	EventOrderRefunded   = "order.refunded"
+	EventOrderReleased   = "order.released"
+	EventOrderReady      = "order.ready"
+	EventProductLowStock = "product.low_stock"
+)
+
+// Event is an entry in the outbox. It is written in the same transaction as the
+// change it describes, and is what webhooks receive. OrderID is the order the
+// event is about, or that caused it; stock adjustments have none.
+type Event struct {
+	ID        ID              `json:"id"`
+	Type      string          `json:"type"`
+	OrderID   ID              `json:"order_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Webhook is an endpoint that receives order events. An empty Events list
+// subscribes to every event type.
+type Webhook struct {
+	ID        ID        `json:"id" validate:"required"`
+	URL       string    `json:"url" validate:"required,url"`
+	Secret    string    `json:"secret" validate:"required"`
+	Events    []string  `json:"events" validate:"dive,oneof=order.created order.accepted order.rejected order.cancelled order.refunded order.released order.ready product.low_stock"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Delivery statuses. A delivery is retried while pending and gives up as dead.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Delivery is one event queued for one webhook.
+type Delivery struct {
+	ID            ID        `json:"id"`
+	WebhookID     ID        `json:"webhook_id"`
+	Event         Event     `json:"event"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	// URL and Secret are copied from the webhook so the delivery can be sent.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookDao manages webhooks and their deliveries. Deliveries are queued by
+// the outbox when events are written, for every webhook subscribed to the type.
+type WebhookDao interface {
+	CreateWebhook(context.Context, Webhook) error
+	GetWebhooks(context.Context) ([]Webhook, error)
+	// DeleteWebhook removes a webhook along with its deliveries.
+	DeleteWebhook(context.Context, ID) error
+	// GetDueDeliveries returns up to limit pending deliveries whose next attempt is due.
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	// GetDeliveries lists a webhook's deliveries, newest first. An empty status
+	// lists all of them.
+	GetDeliveries(ctx context.Context, webhookID ID, status string) ([]Delivery, error)
+	GetDelivery(context.Context, ID) (Delivery, error)
+	// UpdateDelivery records the outcome of an attempt: status, attempts, next
+	// attempt and last error.
+	UpdateDelivery(context.Context, Delivery) error
+	// ReplayDelivery queues a delivery to be sent again straight away, whatever
+	// its status, with a fresh set of attempts.
+	ReplayDelivery(context.Context, ID) error
 }
 
 type Product struct {
@@ -32,6 +456,156 @@ type Product struct {
 	Name     string  `json:"name" validate:"required"`
 	Price    float32 `json:"price" validate:"gte=0"`
 	Category string  `json:"category" validate:"required"`
+	// Slug is made from the name when the product is created, and made again
+	// when a rename changes what it would be.
+	Slug string `json:"slug,omitempty"`
+	// CategorySlug is the slug of the category named Category. Writes find
+	// the category by Category, creating it if there is none.
+	CategorySlug string `json:"category_slug,omitempty"`
+	// Description and Tags are searched along with the name and category.
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" validate:"dive,required"`
+	// ModifierGroups are the choices offered on the product, in menu order.
+	ModifierGroups []ModifierGroup `json:"modifier_groups,omitempty"`
+	// Images holds the product's pictures by variant (one of the Image*
+	// constants): paths relative to the image base URL, or absolute URLs.
+	Images map[string]string `json:"images,omitempty"`
+	// Version goes up by one with every change made through ProductDao, so
+	// writers can tell whether someone else changed the product first.
+	Version int64 `json:"version,omitempty"`
+	// ArchivedAt is when the product was taken off the menu; zero for
+	// products on sale.
+	ArchivedAt time.Time `json:"archived_at,omitempty"`
+}
+
+// ProductPatch holds the changes PatchProduct makes to a product; nil fields
+// are left as they are.
+type ProductPatch struct {
+	Name        *string
+	Price       *float32
+	Category    *string
+	Description *string
+	Tags        *[]string
+}
+
+// ProductVersionError is returned by versioned product writes when the
+// product is no longer at the version the write was based on.
+type ProductVersionError struct {
+	ID      ID
+	Version int64
+}
+
+func (e *ProductVersionError) Error() string {
+	return fmt.Sprintf("product %s is at version %d", e.ID, e.Version)
+}
+
+// ProductIDError is returned when a product is created with an ID that
+// can't be told apart from a product slug in its URL.
+type ProductIDError struct {
+	ID ID
+}
+
+func (e *ProductIDError) Error() string {
+	return fmt.Sprintf("product ID %s is reserved or another product's slug", e.ID)
+}
+
+// Product sort orders for ProductFilter. Ties are broken by product ID.
+// SortByMenu groups products by category, in the categories' order.
+const (
+	SortByMenu       = "menu"
+	SortByName       = "name"
+	SortByNameDesc   = "-name"
+	SortByPrice      = "price"
+	SortByPriceDesc  = "-price"
+	SortByPopularity = "popularity"
+)
+
+// PopularityWindow is how far back the units sold that rank products by
+// popularity are counted.
+const PopularityWindow = 30 * 24 * time.Hour
+
+// ProductFilter narrows down and orders the products FindProducts returns.
+// Zero fields don't filter.
+type ProductFilter struct {
+	// Category is the slug of a category to list the products of, along
+	// with those of its subcategories.
+	Category string
+	MinPrice float32
+	// MaxPrice is the highest price included; zero means no limit.
+	MaxPrice float32
+	// Name matches products whose name contains it, ignoring case.
+	Name string
+	// Available leaves out products that are out of stock or short of an
+	// ingredient.
+	Available bool
+	// Sort is one of the SortBy* constants; empty sorts by menu.
+	Sort string
+	// Limit is the most products returned; zero or less returns them all.
+	Limit int
+	// Cursor continues a listing where the page it was returned with ended.
+	Cursor string
+}
+
+// InvalidCursorError is returned for cursors FindProducts didn't make, or
+// made for another sort order.
+type InvalidCursorError struct {
+	Cursor string
+}
+
+func (e *InvalidCursorError) Error() string {
+	return fmt.Sprintf("invalid cursor %q", e.Cursor)
+}
+
+// ProductSearch is what SearchProducts found.
+type ProductSearch struct {
+	// Query is the query the hits match: the one searched for, or a
+	// correction of its misspelt words when that found nothing.
+	Query string
+	Hits  []ProductHit
+}
+
+// ProductHit is a product SearchProducts found.
+type ProductHit struct {
+	Product
+	// Snippet is the text that matched, with the matching words between
+	// <mark> and </mark>.
+	Snippet string
+	// Score ranks hits by relevance; higher is better.
+	Score float64
+}
+
+// ErrSearchUnavailable is returned by SearchProducts in builds without the
+// fts5 tag, which leaves SQLite's full-text search out.
+var ErrSearchUnavailable = errors.New("product search needs a build with the fts5 tag")
+
+// Image variants, the sizes a product picture is kept in.
+const (
+	ImageThumbnail = "thumbnail"
+	ImageMobile    = "mobile"
+	ImageTablet    = "tablet"
+	ImageDesktop   = "desktop"
+)
+
+// ModifierGroup is a choice offered on a product, such as size or extras.
+// Required groups need at least one selection, or MinSelect if that is more;
+// optional ones need none, or between MinSelect and MaxSelect. A MaxSelect of
+// 0 leaves the number of selections unlimited.
+type ModifierGroup struct {
+	ID        ID         `json:"id"`
+	Name      string     `json:"name"`
+	Required  bool       `json:"required"`
+	MinSelect int32      `json:"min_select"`
+	MaxSelect int32      `json:"max_select"`
+	Options   []Modifier `json:"options"`
+}
+
+// Modifier is one option of a ModifierGroup. PriceDelta is added to the
+// product price for every unit and may be negative.
+type Modifier struct {
+	ID         ID      `json:"id"`
+	GroupID    ID      `json:"group_id"`
+	Name       string  `json:"name"`
+	PriceDelta float32 `json:"price_delta"`
 }
 
 // ProductDao defines read operations for product data used by services.
@@ -39,7 +613,200 @@ type Product struct {
 type ProductDao interface {
 	// Define Product DAO methods here.
 	GetProduct(context.Context, ID) (Product, error)
+	// GetProductBySlug returns the product with the given slug, or one it had
+	// before it was renamed, or ErrNotFound.
+	GetProductBySlug(ctx context.Context, slug string) (Product, error)
+	// GetAllProducts returns the products on sale, leaving out archived ones.
 	GetAllProducts(context.Context) ([]Product, error)
+	// FindProducts returns the products on sale that match filter, in its
+	// order, a page at a time. next is the cursor of the following page, or
+	// empty on the last one. An unusable cursor returns *InvalidCursorError.
+	FindProducts(ctx context.Context, filter ProductFilter) (products []Product, next string, err error)
+	// SearchProducts finds up to limit products on sale with words in their
+	// name, category, description or tags starting with each word of query,
+	// best match first. When nothing matches, misspelt words are corrected to
+	// the closest indexed ones and the search is tried again.
+	SearchProducts(ctx context.Context, query string, limit int) (ProductSearch, error)
+	// GetProductsByIDs resolves several products in one query. If any ID is unknown
+	// it returns the products it did find along with an *UnknownProductsError.
+	// Archived products are returned too; check ArchivedAt before selling one.
+	GetProductsByIDs(context.Context, []ID) (map[ID]Product, error)
+	// CreateProduct validates and stores a new product at version 1. Products
+	// without an ID are given the next numeric one that isn't a slug, and
+	// every product a slug no other product has or had as its slug, or has as
+	// its ID. It returns a *ProductIDError for IDs that are reserved or are or
+	// were another product's slug.
+	CreateProduct(context.Context, Product) (Product, error)
+	// UpdateProduct validates and replaces the name, price, category,
+	// description and tags of a product at the given version, and moves it to
+	// the next one. Version 0
+	// matches any version. It returns ErrNotFound for unknown products and a
+	// *ProductVersionError if the product is at another version.
+	UpdateProduct(ctx context.Context, product Product, version int64) (Product, error)
+	// PatchProduct changes the fields of a product that patch sets, like
+	// UpdateProduct, reading the rest in the same transaction.
+	PatchProduct(ctx context.Context, id ID, patch ProductPatch, version int64) (Product, error)
+	// SetProductArchived takes a product at the given version off the menu,
+	// or puts it back, like UpdateProduct.
+	SetProductArchived(ctx context.Context, id ID, archived bool, version int64) (Product, error)
+	// SetProductImage stores the picture of one variant of a product,
+	// replacing the one it had. It returns ErrNotFound for unknown products.
+	SetProductImage(ctx context.Context, productID ID, variant, path string) error
+	// DeleteProductImage removes the picture of one variant of a product.
+	DeleteProductImage(ctx context.Context, productID ID, variant string) error
+	// CatalogChangedAt returns when the catalog, or the stock and ingredients
+	// that decide which products are available, last changed, to the second.
+	CatalogChangedAt(context.Context) (time.Time, error)
+}
+
+// Category groups products on the menu. Categories are shown by Position,
+// then name, and may be under a parent category.
+type Category struct {
+	ID       int64  `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Position int32  `json:"position"`
+	// ParentSlug is the slug of the category this one is under; empty for
+	// top-level categories.
+	ParentSlug string `json:"parent_slug,omitempty"`
+	// Visible is false for categories taken off the menu, along with the
+	// categories under them. Their products aren't listed or found.
+	Visible bool `json:"visible"`
+}
+
+// InvalidCategoryError is returned for product categories whose name has no
+// letters or digits to make a slug of.
+type InvalidCategoryError struct {
+	Name string
+}
+
+func (e *InvalidCategoryError) Error() string {
+	return fmt.Sprintf("category %q has no letters or digits", e.Name)
+}
+
+// CategoryDao reads the categories of the menu.
+type CategoryDao interface {
+	// GetCategories returns the categories in menu order, leaving out those
+	// that aren't visible unless all is set.
+	GetCategories(ctx context.Context, all bool) ([]Category, error)
+	// GetCategory returns the category with the given slug, or ErrNotFound.
+	GetCategory(ctx context.Context, slug string) (Category, error)
+}
+
+// Stock is how many units of a product are left to sell. Products without a
+// Stock aren't tracked and never run out.
+type Stock struct {
+	ProductID ID    `json:"product_id" validate:"required"`
+	OnHand    int32 `json:"on_hand" validate:"gte=0"`
+	// LowThreshold is the level at which a product.low_stock event is
+	// written; 0 turns the event off.
+	LowThreshold int32     `json:"low_threshold" validate:"gte=0"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Low reports whether the stock is at or below its threshold.
+func (s Stock) Low() bool {
+	return s.LowThreshold > 0 && s.OnHand <= s.LowThreshold
+}
+
+// OutOfStockError is returned when an order or adjustment needs more of a
+// product than is on hand.
+type OutOfStockError struct {
+	ProductID ID
+	OnHand    int32
+}
+
+func (e *OutOfStockError) Error() string {
+	if e.OnHand == 0 {
+		return fmt.Sprintf("product %s is out of stock", e.ProductID)
+	}
+	return fmt.Sprintf("only %d of product %s left", e.OnHand, e.ProductID)
+}
+
+// StockDao manages stock levels. Orders take stock as part of
+// OrderDao.CreateOrder and give it back when they are cancelled or rejected.
+type StockDao interface {
+	// GetStock returns the stock of the tracked products among ids, or of
+	// every tracked product if ids is nil.
+	GetStock(ctx context.Context, ids []ID) (map[ID]Stock, error)
+	// SetStock sets a product's stock level and threshold, starting to track
+	// it if it wasn't.
+	SetStock(context.Context, Stock) (Stock, error)
+	// AdjustStock adds delta, which may be negative, to a tracked product's
+	// stock. It returns ErrNotFound for untracked products and
+	// *OutOfStockError if the stock would go below zero.
+	AdjustStock(ctx context.Context, productID ID, delta int32) (Stock, error)
+}
+
+// Ingredient is something products are made from, counted in Unit ("g",
+// "ml", "each" and so on).
+type Ingredient struct {
+	ID        ID        `json:"id" validate:"required"`
+	Name      string    `json:"name" validate:"required"`
+	Unit      string    `json:"unit" validate:"required"`
+	OnHand    float64   `json:"on_hand" validate:"gte=0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RecipeLine is how much of an ingredient one unit of a product, or of a
+// modifier, uses.
+type RecipeLine struct {
+	IngredientID ID      `json:"ingredient_id" validate:"required"`
+	Quantity     float64 `json:"quantity" validate:"gt=0"`
+}
+
+// Recipe is what making a product uses. Modifiers lists what choosing one of
+// the product's modifiers uses on top, by modifier ID. Products without a
+// recipe use no ingredients.
+type Recipe struct {
+	ProductID   ID                  `json:"product_id" validate:"required"`
+	Ingredients []RecipeLine        `json:"ingredients" validate:"dive"`
+	Modifiers   map[ID][]RecipeLine `json:"modifiers,omitempty" validate:"dive,dive"`
+}
+
+// RecipeError is returned for recipes that use unknown ingredients, or
+// modifiers the product doesn't offer.
+type RecipeError struct {
+	Reason string
+}
+
+func (e *RecipeError) Error() string {
+	return "invalid recipe: " + e.Reason
+}
+
+// IngredientShortageError is returned when an order or adjustment needs more
+// of an ingredient than is on hand.
+type IngredientShortageError struct {
+	IngredientID ID
+	Name         string
+}
+
+func (e *IngredientShortageError) Error() string {
+	return fmt.Sprintf("not enough %s left", e.Name)
+}
+
+// IngredientDao manages ingredients and recipes. Orders use up the
+// ingredients of their recipes as part of OrderDao.CreateOrder and give them
+// back when they are cancelled or rejected.
+type IngredientDao interface {
+	GetIngredients(context.Context) ([]Ingredient, error)
+	// SetIngredient creates an ingredient or replaces its name, unit and stock.
+	SetIngredient(context.Context, Ingredient) (Ingredient, error)
+	// AdjustIngredient adds delta, which may be negative, to an ingredient's
+	// stock. It returns ErrNotFound for unknown ingredients and
+	// *IngredientShortageError if the stock would go below zero.
+	AdjustIngredient(ctx context.Context, id ID, delta float64) (Ingredient, error)
+	// GetRecipe returns a product's recipe, which is empty if it has none.
+	GetRecipe(ctx context.Context, productID ID) (Recipe, error)
+	// SetRecipe replaces a product's recipe. It returns ErrNotFound for
+	// unknown products and *RecipeError for recipes that don't fit them.
+	SetRecipe(context.Context, Recipe) error
+	// GetShortProducts returns the products that can't be made because an
+	// ingredient of their recipe has run short.
+	GetShortProducts(context.Context) (map[ID]bool, error)
+	// GetIngredientUsage returns how much of each ingredient orders placed
+	// since the given time have used, leaving out orders that gave it back.
+	GetIngredientUsage(ctx context.Context, since time.Time) (map[ID]float64, error)
 }
 
 // SearchResult represents the asynchronous result of searching coupon files.
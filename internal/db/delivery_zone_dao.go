@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type DeliveryZoneDaoImpl struct {
+	db *sql.DB
+}
+
+var _ DeliveryZoneDao = &DeliveryZoneDaoImpl{}
+
+func NewDeliveryZoneDao(db *sql.DB) DeliveryZoneDao {
+	return &DeliveryZoneDaoImpl{db: db}
+}
+
+// GetDeliveryZones implements DeliveryZoneDao.
+func (d *DeliveryZoneDaoImpl) GetDeliveryZones(ctx context.Context) ([]json.RawMessage, error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT definition FROM delivery_zones ORDER BY position, name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var zones []json.RawMessage
+	for rows.Next() {
+		var definition string
+		if err := rows.Scan(&definition); err != nil {
+			return nil, err
+		}
+		zones = append(zones, json.RawMessage(definition))
+	}
+	return zones, rows.Err()
+}
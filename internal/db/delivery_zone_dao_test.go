@@ -0,0 +1,26 @@
+package db_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend-challenge/internal/db"
+)
+
+func TestDeliveryZoneDao_GetDeliveryZones(t *testing.T) {
+	sqlDB := setupTestDB(t)
+	_, err := sqlDB.Exec(`INSERT INTO delivery_zones (name, definition, position) VALUES
+		('suburbs', '{"name": "suburbs", "postcodes": ["3121"]}', 2),
+		('cbd', '{"name": "cbd", "postcodes": ["3000"]}', 1)`)
+	assert.NoError(t, err)
+
+	zones, err := db.NewDeliveryZoneDao(sqlDB).GetDeliveryZones(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []json.RawMessage{
+		json.RawMessage(`{"name": "cbd", "postcodes": ["3000"]}`),
+		json.RawMessage(`{"name": "suburbs", "postcodes": ["3121"]}`),
+	}, zones)
+}
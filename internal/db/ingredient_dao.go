@@ -0,0 +1,329 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ingredientSlack absorbs float rounding in stored quantities, so that using
+// exactly what is left of an ingredient isn't refused.
+const ingredientSlack = 1e-9
+
+type IngredientDaoImpl struct {
+	db *sql.DB
+}
+
+var _ IngredientDao = &IngredientDaoImpl{}
+
+func NewIngredientDao(db *sql.DB) IngredientDao {
+	return &IngredientDaoImpl{db: db}
+}
+
+// GetIngredients implements IngredientDao.
+func (d *IngredientDaoImpl) GetIngredients(ctx context.Context) ([]Ingredient, error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT id, name, unit, on_hand, updated_at FROM ingredients ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ingredients []Ingredient
+	for rows.Next() {
+		var ingredient Ingredient
+		if err := rows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Unit, &ingredient.OnHand, &ingredient.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	return ingredients, rows.Err()
+}
+
+// SetIngredient implements IngredientDao.
+func (d *IngredientDaoImpl) SetIngredient(ctx context.Context, ingredient Ingredient) (Ingredient, error) {
+	validate := validator.New()
+	if err := validate.Struct(ingredient); err != nil {
+		return Ingredient{}, err
+	}
+	ingredient.UpdatedAt = time.Now().UTC()
+	_, err := d.db.ExecContext(ctx, `INSERT INTO ingredients (id, name, unit, on_hand, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, unit = excluded.unit, on_hand = excluded.on_hand, updated_at = excluded.updated_at`,
+		ingredient.ID, ingredient.Name, ingredient.Unit, ingredient.OnHand, ingredient.UpdatedAt)
+	if err != nil {
+		return Ingredient{}, err
+	}
+	return ingredient, nil
+}
+
+// AdjustIngredient implements IngredientDao.
+func (d *IngredientDaoImpl) AdjustIngredient(ctx context.Context, id ID, delta float64) (Ingredient, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Ingredient{}, err
+	}
+	defer tx.Rollback()
+	if delta < 0 {
+		err = takeIngredient(ctx, tx, id, -delta)
+	} else {
+		_, err = tx.ExecContext(ctx, "UPDATE ingredients SET on_hand = on_hand + ?, updated_at = ? WHERE id = ?", delta, time.Now().UTC(), id)
+	}
+	if err != nil {
+		return Ingredient{}, err
+	}
+	var ingredient Ingredient
+	if err := tx.QueryRowContext(ctx, "SELECT id, name, unit, on_hand, updated_at FROM ingredients WHERE id = ?", id).
+		Scan(&ingredient.ID, &ingredient.Name, &ingredient.Unit, &ingredient.OnHand, &ingredient.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Ingredient{}, ErrNotFound(err)
+		}
+		return Ingredient{}, err
+	}
+	return ingredient, tx.Commit()
+}
+
+// GetRecipe implements IngredientDao.
+func (d *IngredientDaoImpl) GetRecipe(ctx context.Context, productID ID) (Recipe, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT modifier_id, ingredient_id, quantity FROM recipes
+		WHERE product_id = ? ORDER BY modifier_id, ingredient_id`, productID)
+	if err != nil {
+		return Recipe{}, err
+	}
+	defer rows.Close()
+	recipe := Recipe{ProductID: productID, Ingredients: []RecipeLine{}}
+	for rows.Next() {
+		var modifierID ID
+		var line RecipeLine
+		if err := rows.Scan(&modifierID, &line.IngredientID, &line.Quantity); err != nil {
+			return Recipe{}, err
+		}
+		if modifierID == "" {
+			recipe.Ingredients = append(recipe.Ingredients, line)
+			continue
+		}
+		if recipe.Modifiers == nil {
+			recipe.Modifiers = make(map[ID][]RecipeLine)
+		}
+		recipe.Modifiers[modifierID] = append(recipe.Modifiers[modifierID], line)
+	}
+	return recipe, rows.Err()
+}
+
+// SetRecipe implements IngredientDao.
+func (d *IngredientDaoImpl) SetRecipe(ctx context.Context, recipe Recipe) error {
+	validate := validator.New()
+	if err := validate.Struct(recipe); err != nil {
+		return err
+	}
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = ?)", recipe.ProductID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound(sql.ErrNoRows)
+	}
+	if err := checkRecipe(ctx, tx, recipe); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recipes WHERE product_id = ?", recipe.ProductID); err != nil {
+		return err
+	}
+	insert := func(modifierID ID, lines []RecipeLine) error {
+		for _, line := range lines {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO recipes (product_id, modifier_id, ingredient_id, quantity) VALUES (?, ?, ?, ?)",
+				recipe.ProductID, modifierID, line.IngredientID, line.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := insert("", recipe.Ingredients); err != nil {
+		return err
+	}
+	for modifierID, lines := range recipe.Modifiers {
+		if err := insert(modifierID, lines); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// checkRecipe makes sure a recipe only uses known ingredients, each once per
+// line list, and only the product's own modifiers.
+func checkRecipe(ctx context.Context, tx *sql.Tx, recipe Recipe) error {
+	ingredients := make(map[ID]bool)
+	lists := [][]RecipeLine{recipe.Ingredients}
+	for _, lines := range recipe.Modifiers {
+		lists = append(lists, lines)
+	}
+	for _, lines := range lists {
+		seen := make(map[ID]bool, len(lines))
+		for _, line := range lines {
+			if seen[line.IngredientID] {
+				return &RecipeError{Reason: fmt.Sprintf("ingredient %s is listed twice", line.IngredientID)}
+			}
+			seen[line.IngredientID] = true
+			ingredients[line.IngredientID] = true
+		}
+	}
+	for id := range ingredients {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM ingredients WHERE id = ?)", id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return &RecipeError{Reason: fmt.Sprintf("unknown ingredient %s", id)}
+		}
+	}
+	for modifierID := range recipe.Modifiers {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM modifiers m JOIN modifier_groups g ON g.id = m.group_id
+			WHERE m.id = ? AND g.product_id = ?)`, modifierID, recipe.ProductID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return &RecipeError{Reason: fmt.Sprintf("product %s has no modifier %s", recipe.ProductID, modifierID)}
+		}
+	}
+	return nil
+}
+
+// GetShortProducts implements IngredientDao.
+func (d *IngredientDaoImpl) GetShortProducts(ctx context.Context) (map[ID]bool, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT DISTINCT r.product_id FROM recipes r JOIN ingredients i ON i.id = r.ingredient_id
+		WHERE r.modifier_id = '' AND i.on_hand + ? < r.quantity`, ingredientSlack)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	short := make(map[ID]bool)
+	for rows.Next() {
+		var productID ID
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+		short[productID] = true
+	}
+	return short, rows.Err()
+}
+
+// GetIngredientUsage implements IngredientDao.
+func (d *IngredientDaoImpl) GetIngredientUsage(ctx context.Context, since time.Time) (map[ID]float64, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT ingredient_id, SUM(quantity) FROM ingredient_usage
+		WHERE used_at >= ? AND released_at IS NULL GROUP BY ingredient_id`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	usage := make(map[ID]float64)
+	for rows.Next() {
+		var id ID
+		var quantity float64
+		if err := rows.Scan(&id, &quantity); err != nil {
+			return nil, err
+		}
+		usage[id] = quantity
+	}
+	return usage, rows.Err()
+}
+
+// takeIngredient removes quantity of an ingredient from stock, guarded like
+// takeStock so concurrent orders can't use more than there is.
+func takeIngredient(ctx context.Context, tx *sql.Tx, id ID, quantity float64) error {
+	res, err := tx.ExecContext(ctx, `UPDATE ingredients SET on_hand = max(on_hand - ?, 0), updated_at = ?
+		WHERE id = ? AND on_hand + ? >= ?`, quantity, time.Now().UTC(), id, ingredientSlack, quantity)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	var name string
+	if err := tx.QueryRowContext(ctx, "SELECT name FROM ingredients WHERE id = ?", id).Scan(&name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound(err)
+		}
+		return err
+	}
+	return &IngredientShortageError{IngredientID: id, Name: name}
+}
+
+// useIngredients takes what an order's recipes need out of stock and records
+// it, so that releaseIngredients gives back exactly that.
+func useIngredients(ctx context.Context, tx *sql.Tx, order Order) error {
+	if len(order.Items) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(order.Items))
+	for _, item := range order.Items {
+		args = append(args, item.ProductID)
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT product_id, modifier_id, ingredient_id, quantity FROM recipes
+		WHERE product_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)`, args...)
+	if err != nil {
+		return err
+	}
+	type recipeKey struct{ productID, modifierID ID }
+	recipes := make(map[recipeKey][]RecipeLine)
+	for rows.Next() {
+		var key recipeKey
+		var line RecipeLine
+		if err := rows.Scan(&key.productID, &key.modifierID, &line.IngredientID, &line.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		recipes[key] = append(recipes[key], line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	needed := make(map[ID]float64)
+	for _, item := range order.Items {
+		lines := recipes[recipeKey{productID: item.ProductID}]
+		for _, modifier := range item.Modifiers {
+			lines = append(slices.Clip(lines), recipes[recipeKey{productID: item.ProductID, modifierID: modifier.ID}]...)
+		}
+		for _, line := range lines {
+			needed[line.IngredientID] += line.Quantity * float64(item.Quantity)
+		}
+	}
+	ids := make([]ID, 0, len(needed))
+	for id := range needed {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	now := time.Now().UTC()
+	for _, id := range ids {
+		if err := takeIngredient(ctx, tx, id, needed[id]); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO ingredient_usage (order_id, ingredient_id, quantity, used_at) VALUES (?, ?, ?, ?)",
+			order.ID, id, needed[id], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseIngredients gives back what an order used. The usage is kept, marked
+// released, so that it no longer counts towards the order rate.
+func releaseIngredients(ctx context.Context, tx *sql.Tx, orderID ID) error {
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `UPDATE ingredients SET on_hand = on_hand + u.quantity, updated_at = ?
+		FROM ingredient_usage u WHERE u.ingredient_id = ingredients.id AND u.order_id = ? AND u.released_at IS NULL`, now, orderID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "UPDATE ingredient_usage SET released_at = ? WHERE order_id = ? AND released_at IS NULL", now, orderID)
+	return err
+}
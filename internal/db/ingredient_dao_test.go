@@ -0,0 +1,104 @@
+package db_test
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIngredientDao_Recipes(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	ingredientDao := db.NewIngredientDao(d)
+	orderDao := db.NewOrderDao(d)
+	_, err := d.Exec(`INSERT INTO modifier_groups (id, product_id, name) VALUES ('extras', '1', 'Extras');
+		INSERT INTO modifiers (id, group_id, name, price_delta) VALUES ('cream', 'extras', 'Cream', 1)`)
+	assert.NoError(t, err)
+
+	for _, ingredient := range []db.Ingredient{
+		{ID: "batter", Name: "Batter", Unit: "ml", OnHand: 1000},
+		{ID: "cream", Name: "Cream", Unit: "ml", OnHand: 100},
+	} {
+		_, err := ingredientDao.SetIngredient(ctx, ingredient)
+		assert.NoError(t, err)
+	}
+	recipe := db.Recipe{
+		ProductID:   "1",
+		Ingredients: []db.RecipeLine{{IngredientID: "batter", Quantity: 150}},
+		Modifiers:   map[db.ID][]db.RecipeLine{"cream": {{IngredientID: "cream", Quantity: 40}}},
+	}
+	assert.NoError(t, ingredientDao.SetRecipe(ctx, recipe))
+	got, err := ingredientDao.GetRecipe(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, recipe, got)
+
+	var recipeErr *db.RecipeError
+	assert.ErrorAs(t, ingredientDao.SetRecipe(ctx, db.Recipe{ProductID: "1", Ingredients: []db.RecipeLine{{IngredientID: "flour", Quantity: 1}}}), &recipeErr)
+	assert.ErrorAs(t, ingredientDao.SetRecipe(ctx, db.Recipe{ProductID: "2", Modifiers: map[db.ID][]db.RecipeLine{"cream": {{IngredientID: "cream", Quantity: 1}}}}), &recipeErr)
+	assert.Error(t, ingredientDao.SetRecipe(ctx, db.Recipe{ProductID: "99"}))
+
+	// Two plain waffles and two with cream: 600ml of batter and 80ml of cream.
+	order := db.Order{ID: "recipe-1", Items: []db.Item{
+		{ProductID: "1", Quantity: 2, Name: "Waffle with Berries", UnitPrice: 6.5},
+		{ProductID: "1", Quantity: 2, Name: "Waffle with Berries", UnitPrice: 6.5, Modifiers: []db.Modifier{{ID: "cream", GroupID: "extras", Name: "Cream", PriceDelta: 1}}},
+	}}
+	assert.NoError(t, orderDao.CreateOrder(ctx, order))
+	assert.Equal(t, map[db.ID]float64{"batter": 400, "cream": 20}, ingredientLevels(t, ingredientDao))
+	usage, err := ingredientDao.GetIngredientUsage(ctx, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, map[db.ID]float64{"batter": 600, "cream": 80}, usage)
+
+	err = orderDao.CreateOrder(ctx, db.Order{ID: "recipe-2", Items: []db.Item{{ProductID: "1", Quantity: 3, Name: "Waffle with Berries", UnitPrice: 6.5}}})
+	assert.Equal(t, &db.IngredientShortageError{IngredientID: "batter", Name: "Batter"}, err)
+
+	// What's left makes two more waffles but not three, so the product isn't short yet.
+	short, err := ingredientDao.GetShortProducts(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, short)
+	_, err = ingredientDao.AdjustIngredient(ctx, "batter", -300)
+	assert.NoError(t, err)
+	short, err = ingredientDao.GetShortProducts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[db.ID]bool{"1": true}, short)
+
+	assert.NoError(t, orderDao.CancelOrder(ctx, order.ID, "changed my mind", []string{db.OrderAccepted}, nil))
+	assert.Equal(t, map[db.ID]float64{"batter": 700, "cream": 100}, ingredientLevels(t, ingredientDao))
+	usage, err = ingredientDao.GetIngredientUsage(ctx, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, usage)
+}
+
+func TestIngredientDao_AdjustIngredient(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	ingredientDao := db.NewIngredientDao(d)
+
+	_, err := ingredientDao.AdjustIngredient(ctx, "milk", 5)
+	assert.Error(t, err)
+	_, err = ingredientDao.SetIngredient(ctx, db.Ingredient{ID: "milk", Name: "Milk", Unit: "ml", OnHand: 0.3})
+	assert.NoError(t, err)
+	_, err = ingredientDao.AdjustIngredient(ctx, "milk", -0.1)
+	assert.NoError(t, err)
+	// 0.3 - 0.1 - 0.2 isn't quite 0 in floating point, but all of it can be used.
+	ingredient, err := ingredientDao.AdjustIngredient(ctx, "milk", -0.2)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, ingredient.OnHand, 1e-9)
+	_, err = ingredientDao.AdjustIngredient(ctx, "milk", -1)
+	assert.Equal(t, &db.IngredientShortageError{IngredientID: "milk", Name: "Milk"}, err)
+}
+
+func ingredientLevels(t *testing.T, dao db.IngredientDao) map[db.ID]float64 {
+	t.Helper()
+	ingredients, err := dao.GetIngredients(context.Background())
+	if err != nil {
+		t.Fatalf("listing ingredients failed: %v", err)
+	}
+	levels := make(map[db.ID]float64, len(ingredients))
+	for _, ingredient := range ingredients {
+		levels[ingredient.ID] = ingredient.OnHand
+	}
+	return levels
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type KitchenDaoImpl struct {
+	db *sql.DB
+}
+
+var _ KitchenDao = &KitchenDaoImpl{}
+
+func NewKitchenDao(db *sql.DB) KitchenDao {
+	return &KitchenDaoImpl{db: db}
+}
+
+// GetOpenOrders implements KitchenDao. Orders stored before their placing
+// time was recorded predate the kitchen display and are left out.
+func (k *KitchenDaoImpl) GetOpenOrders(ctx context.Context) ([]Order, error) {
+	rows, err := k.db.QueryContext(ctx, `SELECT id FROM orders
+		WHERE status = ? AND created_at IS NOT NULL AND (scheduled_for IS NULL OR released_at IS NOT NULL)
+		ORDER BY created_at, id`, OrderAccepted)
+	if err != nil {
+		return nil, err
+	}
+	var ids []ID
+	for rows.Next() {
+		var id ID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	orders := make([]Order, 0, len(ids))
+	for _, id := range ids {
+		order, err := getOrder(ctx, k.db, id)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// BumpLine implements KitchenDao.
+func (k *KitchenDaoImpl) BumpLine(ctx context.Context, orderID ID, line int) error {
+	tx, err := k.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := checkStatus(ctx, tx, orderID, []string{OrderAccepted}); err != nil {
+		return err
+	}
+	order, err := getOrder(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if line < 0 || line >= len(order.Items) {
+		return ErrNotFound(sql.ErrNoRows)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO line_bumps (order_id, line, bumped_at) VALUES (?, ?, ?)
+		ON CONFLICT (order_id, line) DO NOTHING`, orderID, line, time.Now().UTC()); err != nil {
+		return err
+	}
+	var bumped int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM line_bumps WHERE order_id = ?", orderID).Scan(&bumped); err != nil {
+		return err
+	}
+	if bumped == len(order.Items) {
+		if err := setStatus(ctx, tx, orderID, []string{OrderAccepted}, OrderReady, ""); err != nil {
+			return err
+		}
+		if err := writeOrderEvent(ctx, tx, EventOrderReady, orderID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// getLineBumps fills in when the kitchen bumped each of the order's lines.
+func getLineBumps(ctx context.Context, q rowQuerier, order *Order) error {
+	rows, err := q.QueryContext(ctx, "SELECT line, bumped_at FROM line_bumps WHERE order_id = ?", order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var line int
+		var bumpedAt time.Time
+		if err := rows.Scan(&line, &bumpedAt); err != nil {
+			return err
+		}
+		if line < len(order.Items) {
+			order.Items[line].BumpedAt = bumpedAt
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,68 @@
+package db_test
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKitchenDao_BumpLine(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	orderDao := db.NewOrderDao(d)
+	kitchenDao := db.NewKitchenDao(d)
+	now := time.Now().UTC()
+	lines := []db.Item{
+		{ProductID: "1", Quantity: 2, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5},
+		{ProductID: "2", Quantity: 1, Name: "Vanilla Bean Crème Brûlée", Category: "Crème Brûlée", UnitPrice: 7},
+	}
+	for _, order := range []db.Order{
+		{ID: "second", CreatedAt: now.Add(-time.Minute)},
+		{ID: "first", CreatedAt: now.Add(-5 * time.Minute)},
+		{ID: "pending", Status: db.OrderPending},
+		{ID: "scheduled", ScheduledFor: now.Add(2 * time.Hour)},
+	} {
+		order.Items = lines
+		assert.NoError(t, orderDao.CreateOrder(ctx, order))
+	}
+
+	open, err := kitchenDao.GetOpenOrders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ID{"first", "second"}, orderIDs(open), "pending and unreleased orders aren't open")
+
+	assert.NoError(t, kitchenDao.BumpLine(ctx, "first", 1))
+	assert.NoError(t, kitchenDao.BumpLine(ctx, "first", 1), "bumping twice is harmless")
+	order, err := orderDao.GetOrder(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, db.OrderAccepted, order.Status)
+	assert.True(t, order.Items[0].BumpedAt.IsZero())
+	assert.False(t, order.Items[1].BumpedAt.IsZero())
+
+	assert.ErrorIs(t, kitchenDao.BumpLine(ctx, "first", 2), sql.ErrNoRows)
+	assert.ErrorIs(t, kitchenDao.BumpLine(ctx, "missing", 0), sql.ErrNoRows)
+	assert.Equal(t, &db.OrderStatusError{ID: "pending", Status: db.OrderPending}, kitchenDao.BumpLine(ctx, "pending", 0))
+
+	assert.NoError(t, kitchenDao.BumpLine(ctx, "first", 0))
+	order, err = orderDao.GetOrder(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, db.OrderReady, order.Status, "the last bump makes the order ready")
+	var events int
+	assert.NoError(t, d.QueryRow("SELECT COUNT(*) FROM outbox WHERE type = ? AND order_id = ?", db.EventOrderReady, "first").Scan(&events))
+	assert.Equal(t, 1, events)
+
+	open, err = kitchenDao.GetOpenOrders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ID{"second"}, orderIDs(open))
+}
+
+func orderIDs(orders []db.Order) []db.ID {
+	ids := make([]db.ID, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
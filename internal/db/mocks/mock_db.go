@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: backend-challenge/internal/db (interfaces: OrderDao,ProductDao,CouponDao,SearchResult)
+// Source: backend-challenge/internal/db (interfaces: OrderDao,ProductDao,CouponDao,SearchResult,QuoteDao,CartDao,WebhookDao,DeliveryZoneDao,StockDao,IngredientDao,SlotDao,KitchenDao,CategoryDao)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -8,7 +8,9 @@ import (
 	db "backend-challenge/internal/db"
 	openapi "backend-challenge/internal/generated/openapi"
 	context "context"
+	jsontext "encoding/json/jsontext"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -36,6 +38,20 @@ func (m *MockOrderDao) EXPECT() *MockOrderDaoMockRecorder {
 	return m.recorder
 }
 
+// CancelOrder mocks base method.
+func (m *MockOrderDao) CancelOrder(arg0 context.Context, arg1, arg2 string, arg3 []string, arg4 *db.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderDaoMockRecorder) CancelOrder(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderDao)(nil).CancelOrder), arg0, arg1, arg2, arg3, arg4)
+}
+
 // CreateOrder mocks base method.
 func (m *MockOrderDao) CreateOrder(arg0 context.Context, arg1 db.Order) error {
 	m.ctrl.T.Helper()
@@ -50,6 +66,95 @@ func (mr *MockOrderDaoMockRecorder) CreateOrder(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderDao)(nil).CreateOrder), arg0, arg1)
 }
 
+// CreateRefund mocks base method.
+func (m *MockOrderDao) CreateRefund(arg0 context.Context, arg1 db.Refund) (db.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefund", arg0, arg1)
+	ret0, _ := ret[0].(db.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefund indicates an expected call of CreateRefund.
+func (mr *MockOrderDaoMockRecorder) CreateRefund(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefund", reflect.TypeOf((*MockOrderDao)(nil).CreateRefund), arg0, arg1)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrderDao) GetOrder(arg0 context.Context, arg1 string) (db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", arg0, arg1)
+	ret0, _ := ret[0].(db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderDaoMockRecorder) GetOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderDao)(nil).GetOrder), arg0, arg1)
+}
+
+// GetRefunds mocks base method.
+func (m *MockOrderDao) GetRefunds(arg0 context.Context, arg1 string) ([]db.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefunds", arg0, arg1)
+	ret0, _ := ret[0].([]db.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefunds indicates an expected call of GetRefunds.
+func (mr *MockOrderDaoMockRecorder) GetRefunds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefunds", reflect.TypeOf((*MockOrderDao)(nil).GetRefunds), arg0, arg1)
+}
+
+// GetRefundsBetween mocks base method.
+func (m *MockOrderDao) GetRefundsBetween(arg0 context.Context, arg1, arg2 time.Time) ([]db.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundsBetween", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]db.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundsBetween indicates an expected call of GetRefundsBetween.
+func (mr *MockOrderDaoMockRecorder) GetRefundsBetween(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundsBetween", reflect.TypeOf((*MockOrderDao)(nil).GetRefundsBetween), arg0, arg1, arg2)
+}
+
+// ReleaseScheduledOrders mocks base method.
+func (m *MockOrderDao) ReleaseScheduledOrders(arg0 context.Context, arg1 time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseScheduledOrders", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseScheduledOrders indicates an expected call of ReleaseScheduledOrders.
+func (mr *MockOrderDaoMockRecorder) ReleaseScheduledOrders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseScheduledOrders", reflect.TypeOf((*MockOrderDao)(nil).ReleaseScheduledOrders), arg0, arg1)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockOrderDao) UpdateOrderStatus(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockOrderDaoMockRecorder) UpdateOrderStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderDao)(nil).UpdateOrderStatus), arg0, arg1, arg2, arg3)
+}
+
 // MockProductDao is a mock of ProductDao interface.
 type MockProductDao struct {
 	ctrl     *gomock.Controller
@@ -73,6 +178,66 @@ func (m *MockProductDao) EXPECT() *MockProductDaoMockRecorder {
 	return m.recorder
 }
 
+// CatalogChangedAt mocks base method.
+func (m *MockProductDao) CatalogChangedAt(arg0 context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CatalogChangedAt", arg0)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CatalogChangedAt indicates an expected call of CatalogChangedAt.
+func (mr *MockProductDaoMockRecorder) CatalogChangedAt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CatalogChangedAt", reflect.TypeOf((*MockProductDao)(nil).CatalogChangedAt), arg0)
+}
+
+// CreateProduct mocks base method.
+func (m *MockProductDao) CreateProduct(arg0 context.Context, arg1 db.Product) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", arg0, arg1)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockProductDaoMockRecorder) CreateProduct(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockProductDao)(nil).CreateProduct), arg0, arg1)
+}
+
+// DeleteProductImage mocks base method.
+func (m *MockProductDao) DeleteProductImage(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductImage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductImage indicates an expected call of DeleteProductImage.
+func (mr *MockProductDaoMockRecorder) DeleteProductImage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductImage", reflect.TypeOf((*MockProductDao)(nil).DeleteProductImage), arg0, arg1, arg2)
+}
+
+// FindProducts mocks base method.
+func (m *MockProductDao) FindProducts(arg0 context.Context, arg1 db.ProductFilter) ([]db.Product, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProducts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Product)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindProducts indicates an expected call of FindProducts.
+func (mr *MockProductDaoMockRecorder) FindProducts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProducts", reflect.TypeOf((*MockProductDao)(nil).FindProducts), arg0, arg1)
+}
+
 // GetAllProducts mocks base method.
 func (m *MockProductDao) GetAllProducts(arg0 context.Context) ([]db.Product, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +268,110 @@ func (mr *MockProductDaoMockRecorder) GetProduct(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockProductDao)(nil).GetProduct), arg0, arg1)
 }
 
+// GetProductBySlug mocks base method.
+func (m *MockProductDao) GetProductBySlug(arg0 context.Context, arg1 string) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySlug", arg0, arg1)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySlug indicates an expected call of GetProductBySlug.
+func (mr *MockProductDaoMockRecorder) GetProductBySlug(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySlug", reflect.TypeOf((*MockProductDao)(nil).GetProductBySlug), arg0, arg1)
+}
+
+// GetProductsByIDs mocks base method.
+func (m *MockProductDao) GetProductsByIDs(arg0 context.Context, arg1 []string) (map[string]db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductsByIDs", arg0, arg1)
+	ret0, _ := ret[0].(map[string]db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductsByIDs indicates an expected call of GetProductsByIDs.
+func (mr *MockProductDaoMockRecorder) GetProductsByIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsByIDs", reflect.TypeOf((*MockProductDao)(nil).GetProductsByIDs), arg0, arg1)
+}
+
+// PatchProduct mocks base method.
+func (m *MockProductDao) PatchProduct(arg0 context.Context, arg1 string, arg2 db.ProductPatch, arg3 int64) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchProduct", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchProduct indicates an expected call of PatchProduct.
+func (mr *MockProductDaoMockRecorder) PatchProduct(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProduct", reflect.TypeOf((*MockProductDao)(nil).PatchProduct), arg0, arg1, arg2, arg3)
+}
+
+// SearchProducts mocks base method.
+func (m *MockProductDao) SearchProducts(arg0 context.Context, arg1 string, arg2 int) (db.ProductSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.ProductSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockProductDaoMockRecorder) SearchProducts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockProductDao)(nil).SearchProducts), arg0, arg1, arg2)
+}
+
+// SetProductArchived mocks base method.
+func (m *MockProductDao) SetProductArchived(arg0 context.Context, arg1 string, arg2 bool, arg3 int64) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductArchived", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetProductArchived indicates an expected call of SetProductArchived.
+func (mr *MockProductDaoMockRecorder) SetProductArchived(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductArchived", reflect.TypeOf((*MockProductDao)(nil).SetProductArchived), arg0, arg1, arg2, arg3)
+}
+
+// SetProductImage mocks base method.
+func (m *MockProductDao) SetProductImage(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductImage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductImage indicates an expected call of SetProductImage.
+func (mr *MockProductDaoMockRecorder) SetProductImage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductImage", reflect.TypeOf((*MockProductDao)(nil).SetProductImage), arg0, arg1, arg2, arg3)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockProductDao) UpdateProduct(arg0 context.Context, arg1 db.Product, arg2 int64) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockProductDaoMockRecorder) UpdateProduct(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProductDao)(nil).UpdateProduct), arg0, arg1, arg2)
+}
+
 // MockCouponDao is a mock of CouponDao interface.
 type MockCouponDao struct {
 	ctrl     *gomock.Controller
@@ -178,3 +447,693 @@ func (mr *MockSearchResultMockRecorder) Validate() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockSearchResult)(nil).Validate))
 }
+
+// MockQuoteDao is a mock of QuoteDao interface.
+type MockQuoteDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuoteDaoMockRecorder
+}
+
+// MockQuoteDaoMockRecorder is the mock recorder for MockQuoteDao.
+type MockQuoteDaoMockRecorder struct {
+	mock *MockQuoteDao
+}
+
+// NewMockQuoteDao creates a new mock instance.
+func NewMockQuoteDao(ctrl *gomock.Controller) *MockQuoteDao {
+	mock := &MockQuoteDao{ctrl: ctrl}
+	mock.recorder = &MockQuoteDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuoteDao) EXPECT() *MockQuoteDaoMockRecorder {
+	return m.recorder
+}
+
+// CreateQuote mocks base method.
+func (m *MockQuoteDao) CreateQuote(arg0 context.Context, arg1 db.Quote) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockQuoteDaoMockRecorder) CreateQuote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockQuoteDao)(nil).CreateQuote), arg0, arg1)
+}
+
+// GetQuote mocks base method.
+func (m *MockQuoteDao) GetQuote(arg0 context.Context, arg1 string) (db.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuote", arg0, arg1)
+	ret0, _ := ret[0].(db.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote.
+func (mr *MockQuoteDaoMockRecorder) GetQuote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockQuoteDao)(nil).GetQuote), arg0, arg1)
+}
+
+// MockCartDao is a mock of CartDao interface.
+type MockCartDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockCartDaoMockRecorder
+}
+
+// MockCartDaoMockRecorder is the mock recorder for MockCartDao.
+type MockCartDaoMockRecorder struct {
+	mock *MockCartDao
+}
+
+// NewMockCartDao creates a new mock instance.
+func NewMockCartDao(ctrl *gomock.Controller) *MockCartDao {
+	mock := &MockCartDao{ctrl: ctrl}
+	mock.recorder = &MockCartDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCartDao) EXPECT() *MockCartDaoMockRecorder {
+	return m.recorder
+}
+
+// CreateCart mocks base method.
+func (m *MockCartDao) CreateCart(arg0 context.Context, arg1 db.Cart) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCart", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCart indicates an expected call of CreateCart.
+func (mr *MockCartDaoMockRecorder) CreateCart(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCart", reflect.TypeOf((*MockCartDao)(nil).CreateCart), arg0, arg1)
+}
+
+// DeleteCart mocks base method.
+func (m *MockCartDao) DeleteCart(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCart", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCart indicates an expected call of DeleteCart.
+func (mr *MockCartDaoMockRecorder) DeleteCart(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCart", reflect.TypeOf((*MockCartDao)(nil).DeleteCart), arg0, arg1)
+}
+
+// DeleteCartsIdleSince mocks base method.
+func (m *MockCartDao) DeleteCartsIdleSince(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCartsIdleSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCartsIdleSince indicates an expected call of DeleteCartsIdleSince.
+func (mr *MockCartDaoMockRecorder) DeleteCartsIdleSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCartsIdleSince", reflect.TypeOf((*MockCartDao)(nil).DeleteCartsIdleSince), arg0, arg1)
+}
+
+// GetCart mocks base method.
+func (m *MockCartDao) GetCart(arg0 context.Context, arg1 string) (db.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCart", arg0, arg1)
+	ret0, _ := ret[0].(db.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCart indicates an expected call of GetCart.
+func (mr *MockCartDaoMockRecorder) GetCart(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCart", reflect.TypeOf((*MockCartDao)(nil).GetCart), arg0, arg1)
+}
+
+// RemoveCartItem mocks base method.
+func (m *MockCartDao) RemoveCartItem(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCartItem", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCartItem indicates an expected call of RemoveCartItem.
+func (mr *MockCartDaoMockRecorder) RemoveCartItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCartItem", reflect.TypeOf((*MockCartDao)(nil).RemoveCartItem), arg0, arg1, arg2)
+}
+
+// SetCartCoupon mocks base method.
+func (m *MockCartDao) SetCartCoupon(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCartCoupon", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCartCoupon indicates an expected call of SetCartCoupon.
+func (mr *MockCartDaoMockRecorder) SetCartCoupon(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCartCoupon", reflect.TypeOf((*MockCartDao)(nil).SetCartCoupon), arg0, arg1, arg2)
+}
+
+// SetCartItem mocks base method.
+func (m *MockCartDao) SetCartItem(arg0 context.Context, arg1 string, arg2 db.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCartItem", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCartItem indicates an expected call of SetCartItem.
+func (mr *MockCartDaoMockRecorder) SetCartItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCartItem", reflect.TypeOf((*MockCartDao)(nil).SetCartItem), arg0, arg1, arg2)
+}
+
+// MockWebhookDao is a mock of WebhookDao interface.
+type MockWebhookDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookDaoMockRecorder
+}
+
+// MockWebhookDaoMockRecorder is the mock recorder for MockWebhookDao.
+type MockWebhookDaoMockRecorder struct {
+	mock *MockWebhookDao
+}
+
+// NewMockWebhookDao creates a new mock instance.
+func NewMockWebhookDao(ctrl *gomock.Controller) *MockWebhookDao {
+	mock := &MockWebhookDao{ctrl: ctrl}
+	mock.recorder = &MockWebhookDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookDao) EXPECT() *MockWebhookDaoMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookDao) CreateWebhook(arg0 context.Context, arg1 db.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookDaoMockRecorder) CreateWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookDao)(nil).CreateWebhook), arg0, arg1)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookDao) DeleteWebhook(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookDaoMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookDao)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookDao) GetDeliveries(arg0 context.Context, arg1, arg2 string) ([]db.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]db.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookDaoMockRecorder) GetDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookDao)(nil).GetDeliveries), arg0, arg1, arg2)
+}
+
+// GetDelivery mocks base method.
+func (m *MockWebhookDao) GetDelivery(arg0 context.Context, arg1 string) (db.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelivery", arg0, arg1)
+	ret0, _ := ret[0].(db.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelivery indicates an expected call of GetDelivery.
+func (mr *MockWebhookDaoMockRecorder) GetDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelivery", reflect.TypeOf((*MockWebhookDao)(nil).GetDelivery), arg0, arg1)
+}
+
+// GetDueDeliveries mocks base method.
+func (m *MockWebhookDao) GetDueDeliveries(arg0 context.Context, arg1 time.Time, arg2 int) ([]db.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]db.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueDeliveries indicates an expected call of GetDueDeliveries.
+func (mr *MockWebhookDaoMockRecorder) GetDueDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueDeliveries", reflect.TypeOf((*MockWebhookDao)(nil).GetDueDeliveries), arg0, arg1, arg2)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookDao) GetWebhooks(arg0 context.Context) ([]db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0)
+	ret0, _ := ret[0].([]db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookDaoMockRecorder) GetWebhooks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookDao)(nil).GetWebhooks), arg0)
+}
+
+// ReplayDelivery mocks base method.
+func (m *MockWebhookDao) ReplayDelivery(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayDelivery indicates an expected call of ReplayDelivery.
+func (mr *MockWebhookDaoMockRecorder) ReplayDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDelivery", reflect.TypeOf((*MockWebhookDao)(nil).ReplayDelivery), arg0, arg1)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhookDao) UpdateDelivery(arg0 context.Context, arg1 db.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhookDaoMockRecorder) UpdateDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhookDao)(nil).UpdateDelivery), arg0, arg1)
+}
+
+// MockDeliveryZoneDao is a mock of DeliveryZoneDao interface.
+type MockDeliveryZoneDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryZoneDaoMockRecorder
+}
+
+// MockDeliveryZoneDaoMockRecorder is the mock recorder for MockDeliveryZoneDao.
+type MockDeliveryZoneDaoMockRecorder struct {
+	mock *MockDeliveryZoneDao
+}
+
+// NewMockDeliveryZoneDao creates a new mock instance.
+func NewMockDeliveryZoneDao(ctrl *gomock.Controller) *MockDeliveryZoneDao {
+	mock := &MockDeliveryZoneDao{ctrl: ctrl}
+	mock.recorder = &MockDeliveryZoneDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryZoneDao) EXPECT() *MockDeliveryZoneDaoMockRecorder {
+	return m.recorder
+}
+
+// GetDeliveryZones mocks base method.
+func (m *MockDeliveryZoneDao) GetDeliveryZones(arg0 context.Context) ([]jsontext.Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryZones", arg0)
+	ret0, _ := ret[0].([]jsontext.Value)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryZones indicates an expected call of GetDeliveryZones.
+func (mr *MockDeliveryZoneDaoMockRecorder) GetDeliveryZones(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryZones", reflect.TypeOf((*MockDeliveryZoneDao)(nil).GetDeliveryZones), arg0)
+}
+
+// MockStockDao is a mock of StockDao interface.
+type MockStockDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockDaoMockRecorder
+}
+
+// MockStockDaoMockRecorder is the mock recorder for MockStockDao.
+type MockStockDaoMockRecorder struct {
+	mock *MockStockDao
+}
+
+// NewMockStockDao creates a new mock instance.
+func NewMockStockDao(ctrl *gomock.Controller) *MockStockDao {
+	mock := &MockStockDao{ctrl: ctrl}
+	mock.recorder = &MockStockDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockDao) EXPECT() *MockStockDaoMockRecorder {
+	return m.recorder
+}
+
+// AdjustStock mocks base method.
+func (m *MockStockDao) AdjustStock(arg0 context.Context, arg1 string, arg2 int32) (db.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockStockDaoMockRecorder) AdjustStock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockStockDao)(nil).AdjustStock), arg0, arg1, arg2)
+}
+
+// GetStock mocks base method.
+func (m *MockStockDao) GetStock(arg0 context.Context, arg1 []string) (map[string]db.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStock", arg0, arg1)
+	ret0, _ := ret[0].(map[string]db.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStock indicates an expected call of GetStock.
+func (mr *MockStockDaoMockRecorder) GetStock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStock", reflect.TypeOf((*MockStockDao)(nil).GetStock), arg0, arg1)
+}
+
+// SetStock mocks base method.
+func (m *MockStockDao) SetStock(arg0 context.Context, arg1 db.Stock) (db.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStock", arg0, arg1)
+	ret0, _ := ret[0].(db.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStock indicates an expected call of SetStock.
+func (mr *MockStockDaoMockRecorder) SetStock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStock", reflect.TypeOf((*MockStockDao)(nil).SetStock), arg0, arg1)
+}
+
+// MockIngredientDao is a mock of IngredientDao interface.
+type MockIngredientDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngredientDaoMockRecorder
+}
+
+// MockIngredientDaoMockRecorder is the mock recorder for MockIngredientDao.
+type MockIngredientDaoMockRecorder struct {
+	mock *MockIngredientDao
+}
+
+// NewMockIngredientDao creates a new mock instance.
+func NewMockIngredientDao(ctrl *gomock.Controller) *MockIngredientDao {
+	mock := &MockIngredientDao{ctrl: ctrl}
+	mock.recorder = &MockIngredientDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngredientDao) EXPECT() *MockIngredientDaoMockRecorder {
+	return m.recorder
+}
+
+// AdjustIngredient mocks base method.
+func (m *MockIngredientDao) AdjustIngredient(arg0 context.Context, arg1 string, arg2 float64) (db.Ingredient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustIngredient", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Ingredient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustIngredient indicates an expected call of AdjustIngredient.
+func (mr *MockIngredientDaoMockRecorder) AdjustIngredient(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustIngredient", reflect.TypeOf((*MockIngredientDao)(nil).AdjustIngredient), arg0, arg1, arg2)
+}
+
+// GetIngredientUsage mocks base method.
+func (m *MockIngredientDao) GetIngredientUsage(arg0 context.Context, arg1 time.Time) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIngredientUsage", arg0, arg1)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIngredientUsage indicates an expected call of GetIngredientUsage.
+func (mr *MockIngredientDaoMockRecorder) GetIngredientUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIngredientUsage", reflect.TypeOf((*MockIngredientDao)(nil).GetIngredientUsage), arg0, arg1)
+}
+
+// GetIngredients mocks base method.
+func (m *MockIngredientDao) GetIngredients(arg0 context.Context) ([]db.Ingredient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIngredients", arg0)
+	ret0, _ := ret[0].([]db.Ingredient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIngredients indicates an expected call of GetIngredients.
+func (mr *MockIngredientDaoMockRecorder) GetIngredients(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIngredients", reflect.TypeOf((*MockIngredientDao)(nil).GetIngredients), arg0)
+}
+
+// GetRecipe mocks base method.
+func (m *MockIngredientDao) GetRecipe(arg0 context.Context, arg1 string) (db.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecipe", arg0, arg1)
+	ret0, _ := ret[0].(db.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecipe indicates an expected call of GetRecipe.
+func (mr *MockIngredientDaoMockRecorder) GetRecipe(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecipe", reflect.TypeOf((*MockIngredientDao)(nil).GetRecipe), arg0, arg1)
+}
+
+// GetShortProducts mocks base method.
+func (m *MockIngredientDao) GetShortProducts(arg0 context.Context) (map[string]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShortProducts", arg0)
+	ret0, _ := ret[0].(map[string]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShortProducts indicates an expected call of GetShortProducts.
+func (mr *MockIngredientDaoMockRecorder) GetShortProducts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShortProducts", reflect.TypeOf((*MockIngredientDao)(nil).GetShortProducts), arg0)
+}
+
+// SetIngredient mocks base method.
+func (m *MockIngredientDao) SetIngredient(arg0 context.Context, arg1 db.Ingredient) (db.Ingredient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetIngredient", arg0, arg1)
+	ret0, _ := ret[0].(db.Ingredient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetIngredient indicates an expected call of SetIngredient.
+func (mr *MockIngredientDaoMockRecorder) SetIngredient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIngredient", reflect.TypeOf((*MockIngredientDao)(nil).SetIngredient), arg0, arg1)
+}
+
+// SetRecipe mocks base method.
+func (m *MockIngredientDao) SetRecipe(arg0 context.Context, arg1 db.Recipe) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRecipe", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRecipe indicates an expected call of SetRecipe.
+func (mr *MockIngredientDaoMockRecorder) SetRecipe(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRecipe", reflect.TypeOf((*MockIngredientDao)(nil).SetRecipe), arg0, arg1)
+}
+
+// MockSlotDao is a mock of SlotDao interface.
+type MockSlotDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockSlotDaoMockRecorder
+}
+
+// MockSlotDaoMockRecorder is the mock recorder for MockSlotDao.
+type MockSlotDaoMockRecorder struct {
+	mock *MockSlotDao
+}
+
+// NewMockSlotDao creates a new mock instance.
+func NewMockSlotDao(ctrl *gomock.Controller) *MockSlotDao {
+	mock := &MockSlotDao{ctrl: ctrl}
+	mock.recorder = &MockSlotDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSlotDao) EXPECT() *MockSlotDaoMockRecorder {
+	return m.recorder
+}
+
+// GetSlotUsage mocks base method.
+func (m *MockSlotDao) GetSlotUsage(arg0 context.Context, arg1, arg2 time.Time) ([]db.SlotUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSlotUsage", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]db.SlotUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSlotUsage indicates an expected call of GetSlotUsage.
+func (mr *MockSlotDaoMockRecorder) GetSlotUsage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSlotUsage", reflect.TypeOf((*MockSlotDao)(nil).GetSlotUsage), arg0, arg1, arg2)
+}
+
+// MockKitchenDao is a mock of KitchenDao interface.
+type MockKitchenDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockKitchenDaoMockRecorder
+}
+
+// MockKitchenDaoMockRecorder is the mock recorder for MockKitchenDao.
+type MockKitchenDaoMockRecorder struct {
+	mock *MockKitchenDao
+}
+
+// NewMockKitchenDao creates a new mock instance.
+func NewMockKitchenDao(ctrl *gomock.Controller) *MockKitchenDao {
+	mock := &MockKitchenDao{ctrl: ctrl}
+	mock.recorder = &MockKitchenDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKitchenDao) EXPECT() *MockKitchenDaoMockRecorder {
+	return m.recorder
+}
+
+// BumpLine mocks base method.
+func (m *MockKitchenDao) BumpLine(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BumpLine", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BumpLine indicates an expected call of BumpLine.
+func (mr *MockKitchenDaoMockRecorder) BumpLine(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BumpLine", reflect.TypeOf((*MockKitchenDao)(nil).BumpLine), arg0, arg1, arg2)
+}
+
+// GetOpenOrders mocks base method.
+func (m *MockKitchenDao) GetOpenOrders(arg0 context.Context) ([]db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenOrders", arg0)
+	ret0, _ := ret[0].([]db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenOrders indicates an expected call of GetOpenOrders.
+func (mr *MockKitchenDaoMockRecorder) GetOpenOrders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenOrders", reflect.TypeOf((*MockKitchenDao)(nil).GetOpenOrders), arg0)
+}
+
+// MockCategoryDao is a mock of CategoryDao interface.
+type MockCategoryDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoryDaoMockRecorder
+}
+
+// MockCategoryDaoMockRecorder is the mock recorder for MockCategoryDao.
+type MockCategoryDaoMockRecorder struct {
+	mock *MockCategoryDao
+}
+
+// NewMockCategoryDao creates a new mock instance.
+func NewMockCategoryDao(ctrl *gomock.Controller) *MockCategoryDao {
+	mock := &MockCategoryDao{ctrl: ctrl}
+	mock.recorder = &MockCategoryDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategoryDao) EXPECT() *MockCategoryDaoMockRecorder {
+	return m.recorder
+}
+
+// GetCategories mocks base method.
+func (m *MockCategoryDao) GetCategories(arg0 context.Context, arg1 bool) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", arg0, arg1)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockCategoryDaoMockRecorder) GetCategories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockCategoryDao)(nil).GetCategories), arg0, arg1)
+}
+
+// GetCategory mocks base method.
+func (m *MockCategoryDao) GetCategory(arg0 context.Context, arg1 string) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockCategoryDaoMockRecorder) GetCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockCategoryDao)(nil).GetCategory), arg0, arg1)
+}
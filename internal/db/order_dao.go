@@ -4,6 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -18,19 +23,398 @@ func NewOrderDao(db *sql.DB) OrderDao {
 	return &OrderDaoImpl{db: db}
 }
 
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
 func (generalOrder *OrderDaoImpl) CreateOrder(ctx context.Context, order Order) error {
 	// Validate order structure
 	validate := validator.New()
 	if err := validate.Struct(order); err != nil {
 		return err
 	}
-	query := "INSERT INTO orders (id, items) VALUES (?, ?)"
+	if order.Status == "" {
+		order.Status = OrderAccepted
+	}
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now()
+	}
+	query := `INSERT INTO orders (id, items, coupon_code, discounts, status, status_reason, fulfilment, delivery_fee, scheduled_for, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	items, err := json.Marshal(order.Items)
 	if err != nil {
 		return err
 	}
-	if _, err := generalOrder.db.ExecContext(ctx, query, order.ID, items); err != nil {
+	var fulfilment *string
+	if order.Fulfilment != nil {
+		data, err := json.Marshal(order.Fulfilment)
+		if err != nil {
+			return err
+		}
+		fulfilment = new(string)
+		*fulfilment = string(data)
+	}
+	var scheduledFor *time.Time
+	if !order.ScheduledFor.IsZero() {
+		scheduledFor = new(time.Time)
+		*scheduledFor = order.ScheduledFor.UTC()
+	}
+	tx, err := generalOrder.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, query, order.ID, items, order.CouponCode, order.Discounts, order.Status, order.StatusReason, fulfilment, order.DeliveryFee, scheduledFor,
+		order.CreatedAt.UTC()); err != nil {
+		return err
+	}
+	if err := reserveStock(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := useIngredients(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := reserveSlot(ctx, tx, order); err != nil {
 		return err
 	}
+	if err := useQuote(ctx, tx, order); err != nil {
+		return err
+	}
+	if order.Status == OrderAccepted {
+		if err := redeemCoupon(ctx, tx, order.ID); err != nil {
+			return err
+		}
+	}
+	if err := writeOrderEvent(ctx, tx, EventOrderCreated, order.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetOrder implements OrderDao.
+func (generalOrder *OrderDaoImpl) GetOrder(ctx context.Context, id ID) (Order, error) {
+	return getOrder(ctx, generalOrder.db, id)
+}
+
+func getOrder(ctx context.Context, q rowQuerier, id ID) (Order, error) {
+	row := q.QueryRowContext(ctx, `SELECT id, items, coupon_code, discounts, status, status_reason, fulfilment, delivery_fee, scheduled_for, released_at,
+		created_at FROM orders WHERE id = ?`, id)
+	var order Order
+	var itemsJSON []byte
+	var fulfilment sql.NullString
+	var scheduledFor, releasedAt, createdAt sql.NullTime
+	if err := row.Scan(&order.ID, &itemsJSON, &order.CouponCode, &order.Discounts, &order.Status, &order.StatusReason, &fulfilment, &order.DeliveryFee,
+		&scheduledFor, &releasedAt, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Order{}, ErrNotFound(err)
+		}
+		return Order{}, err
+	}
+	if err := json.Unmarshal(itemsJSON, &order.Items); err != nil {
+		return Order{}, err
+	}
+	if fulfilment.Valid {
+		if err := json.Unmarshal([]byte(fulfilment.String), &order.Fulfilment); err != nil {
+			return Order{}, err
+		}
+	}
+	order.ScheduledFor = scheduledFor.Time
+	order.ReleasedAt = releasedAt.Time
+	order.CreatedAt = createdAt.Time
+	if err := getLineBumps(ctx, q, &order); err != nil {
+		return Order{}, err
+	}
+	return order, nil
+}
+
+// UpdateOrderStatus implements OrderDao.
+func (generalOrder *OrderDaoImpl) UpdateOrderStatus(ctx context.Context, id ID, status string, reason string) error {
+	tx, err := generalOrder.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var eventType string
+	switch status {
+	case OrderAccepted:
+		eventType = EventOrderAccepted
+	case OrderRejected:
+		eventType = EventOrderRejected
+	default:
+		return fmt.Errorf("a pending order can't become %s", status)
+	}
+	if err := setStatus(ctx, tx, id, []string{OrderPending}, status, reason); err != nil {
+		return err
+	}
+	if status == OrderRejected {
+		if err := releaseStock(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := releaseIngredients(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := releaseSlot(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	if status == OrderAccepted {
+		if err := redeemCoupon(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	if err := writeOrderEvent(ctx, tx, eventType, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CancelOrder implements OrderDao.
+func (generalOrder *OrderDaoImpl) CancelOrder(ctx context.Context, id ID, reason string, from []string, refund *Refund) error {
+	tx, err := generalOrder.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := checkStatus(ctx, tx, id, from); err != nil {
+		return err
+	}
+	// What the kitchen has made can't go back into stock.
+	var bumped bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM line_bumps WHERE order_id = ?)", id).Scan(&bumped); err != nil {
+		return err
+	} else if bumped {
+		return &OrderPreparingError{ID: id}
+	}
+	// The refund is checked against what was paid before the order stops
+	// counting as charged.
+	if refund != nil {
+		if _, err := createRefund(ctx, tx, *refund); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE orders SET status = ?, status_reason = ? WHERE id = ?", OrderCancelled, reason, id); err != nil {
+		return err
+	}
+	if err := releaseStock(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := releaseIngredients(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := releaseSlot(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := writeOrderEvent(ctx, tx, EventOrderCancelled, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReleaseScheduledOrders implements OrderDao.
+func (generalOrder *OrderDaoImpl) ReleaseScheduledOrders(ctx context.Context, by time.Time) ([]ID, error) {
+	tx, err := generalOrder.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, `UPDATE orders SET released_at = ?
+		WHERE released_at IS NULL AND scheduled_for <= ? AND status = ? RETURNING id`,
+		time.Now().UTC(), by.UTC(), OrderAccepted)
+	if err != nil {
+		return nil, err
+	}
+	var ids []ID
+	for rows.Next() {
+		var id ID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := writeOrderEvent(ctx, tx, EventOrderReleased, id); err != nil {
+			return nil, err
+		}
+	}
+	return ids, tx.Commit()
+}
+
+// setStatus moves an order in one of the from statuses to status.
+func setStatus(ctx context.Context, tx *sql.Tx, id ID, from []string, status, reason string) error {
+	if err := checkStatus(ctx, tx, id, from); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "UPDATE orders SET status = ?, status_reason = ? WHERE id = ?", status, reason, id)
+	return err
+}
+
+// checkStatus fails unless the order exists and is in one of the from statuses.
+func checkStatus(ctx context.Context, tx *sql.Tx, id ID, from []string) error {
+	var current string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = ?", id).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound(err)
+		}
+		return err
+	}
+	if !slices.Contains(from, current) {
+		return &OrderStatusError{ID: id, Status: current}
+	}
+	return nil
+}
+
+// redeemCoupon records that an accepted order used its coupon, if it has one.
+func redeemCoupon(ctx context.Context, tx *sql.Tx, id ID) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO coupon_redemptions (order_id, coupon_code, redeemed_at)
+		SELECT id, coupon_code, ? FROM orders WHERE id = ? AND coupon_code != ''
+		ON CONFLICT (order_id) DO NOTHING`, time.Now().UTC(), id)
+	return err
+}
+
+// CreateRefund implements OrderDao.
+func (generalOrder *OrderDaoImpl) CreateRefund(ctx context.Context, refund Refund) (Refund, error) {
+	tx, err := generalOrder.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Refund{}, err
+	}
+	defer tx.Rollback()
+	if refund, err = createRefund(ctx, tx, refund); err != nil {
+		return Refund{}, err
+	}
+	return refund, tx.Commit()
+}
+
+// createRefund checks refund against what is left to refund on the order and
+// stores it. Pending and rejected orders were never charged, and cancelled ones
+// were refunded when they were cancelled, so nothing can be refunded on them.
+func createRefund(ctx context.Context, tx *sql.Tx, refund Refund) (Refund, error) {
+	validate := validator.New()
+	if err := validate.Struct(refund); err != nil {
+		return Refund{}, err
+	}
+	order, err := getOrder(ctx, tx, refund.OrderID)
+	if err != nil {
+		return Refund{}, err
+	}
+	var paid float32
+	if order.Status != OrderPending && order.Status != OrderRejected && order.Status != OrderCancelled {
+		paid = order.Total()
+	}
+	var refunded float64
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE order_id = ?", refund.OrderID).Scan(&refunded); err != nil {
+		return Refund{}, err
+	}
+	// Compare in cents so float rounding can't block refunding the last cent.
+	remaining := math.Round(float64(paid)*100) - math.Round(refunded*100)
+	if math.Round(float64(refund.Amount)*100) > remaining {
+		return Refund{}, &RefundLimitError{OrderID: refund.OrderID, Remaining: float32(max(remaining, 0) / 100)}
+	}
+	if len(refund.Lines) > 0 {
+		if err := checkRefundLines(ctx, tx, order, refund.Lines); err != nil {
+			return Refund{}, err
+		}
+	}
+
+	if refund.CouponReleased {
+		res, err := tx.ExecContext(ctx, "UPDATE coupon_redemptions SET released_at = ? WHERE order_id = ? AND released_at IS NULL",
+			time.Now().UTC(), refund.OrderID)
+		if err != nil {
+			return Refund{}, err
+		}
+		// Only claim a release if there was a redemption to give back.
+		n, err := res.RowsAffected()
+		if err != nil {
+			return Refund{}, err
+		}
+		refund.CouponReleased = n > 0
+	}
+	lines, err := json.Marshal(refund.Lines)
+	if err != nil {
+		return Refund{}, err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO refunds (id, order_id, amount, lines, reason, operator, coupon_released, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		refund.ID, refund.OrderID, refund.Amount, lines, refund.Reason, refund.Operator, refund.CouponReleased, refund.CreatedAt.UTC())
+	if err != nil {
+		return Refund{}, err
+	}
+	return refund, writeEvent(ctx, tx, EventOrderRefunded, refund.OrderID, refund)
+}
+
+// checkRefundLines fails with *RefundQuantityError if lines give back more of
+// a product than the order has left of it after its earlier refunds.
+func checkRefundLines(ctx context.Context, tx *sql.Tx, order Order, lines []RefundLine) error {
+	left := make(map[ID]int32, len(order.Items))
+	for _, item := range order.Items {
+		left[item.ProductID] += item.Quantity
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT lines FROM refunds WHERE order_id = ?", order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var linesJSON []byte
+		var refunded []RefundLine
+		if err := rows.Scan(&linesJSON); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(linesJSON, &refunded); err != nil {
+			return err
+		}
+		for _, line := range refunded {
+			left[line.ProductID] -= line.Quantity
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	wanted := make(map[ID]int32, len(lines))
+	for _, line := range lines {
+		wanted[line.ProductID] += line.Quantity
+		if wanted[line.ProductID] > left[line.ProductID] {
+			return &RefundQuantityError{OrderID: order.ID, ProductID: line.ProductID, Remaining: max(left[line.ProductID], 0)}
+		}
+	}
 	return nil
 }
+
+// GetRefunds implements OrderDao.
+func (generalOrder *OrderDaoImpl) GetRefunds(ctx context.Context, orderID ID) ([]Refund, error) {
+	return generalOrder.queryRefunds(ctx, "WHERE order_id = ?", orderID)
+}
+
+// GetRefundsBetween implements OrderDao.
+func (generalOrder *OrderDaoImpl) GetRefundsBetween(ctx context.Context, from, to time.Time) ([]Refund, error) {
+	return generalOrder.queryRefunds(ctx, "WHERE created_at >= ? AND created_at < ?", from.UTC(), to.UTC())
+}
+
+func (generalOrder *OrderDaoImpl) queryRefunds(ctx context.Context, where string, args ...any) ([]Refund, error) {
+	rows, err := generalOrder.db.QueryContext(ctx, `SELECT id, order_id, amount, lines, reason, operator, coupon_released, created_at
+		FROM refunds `+where+` ORDER BY created_at, rowid`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var refunds []Refund
+	for rows.Next() {
+		var refund Refund
+		var linesJSON []byte
+		if err := rows.Scan(&refund.ID, &refund.OrderID, &refund.Amount, &linesJSON, &refund.Reason, &refund.Operator,
+			&refund.CouponReleased, &refund.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(linesJSON, &refund.Lines); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds, rows.Err()
+}
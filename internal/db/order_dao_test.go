@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"testing"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
@@ -13,16 +14,12 @@ import (
 
 func setupTestDB(t *testing.T) *sql.DB {
 	t.Helper()
-	d, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+	d, err := sql.Open("sqlite3", "file:order_dao_test?mode=memory&cache=shared")
 	if err != nil {
 		t.Fatalf("sql.Open failed: %v", err)
 	}
-	sqlStmt := `CREATE TABLE IF NOT EXISTS orders (
-		id TEXT PRIMARY KEY,
-		items BLOB
-	);`
-	if _, err := d.Exec(sqlStmt); err != nil {
-		t.Fatalf("creating table failed: %v", err)
+	if err := db.Migrate(context.Background(), d); err != nil {
+		t.Fatalf("migrating schema failed: %v", err)
 	}
 	return d
 }
@@ -109,3 +106,182 @@ func TestGeneralOrder_CreateOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneralOrder_GetOrder(t *testing.T) {
+	sqlDB := setupTestDB(t)
+	orderDao := db.NewOrderDao(sqlDB)
+	placedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	placed := db.Order{
+		ID: "order-snapshot",
+		Items: []db.Item{
+			{ProductID: "1", Quantity: 2, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5},
+		},
+		Status:    db.OrderAccepted,
+		CreatedAt: placedAt,
+	}
+	assert.NoError(t, orderDao.CreateOrder(context.Background(), placed))
+	delivery := db.Order{
+		ID:     "order-delivery",
+		Items:  []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5}},
+		Status: db.OrderAccepted,
+		Fulfilment: &db.Fulfilment{Type: db.FulfilmentDelivery, Zone: "CBD", Address: &db.Address{
+			Line1: "1 Collins St", City: "Melbourne", Postcode: "3000", Instructions: "Leave at the door", Lat: -37.8136, Lng: 144.9631,
+		}},
+		DeliveryFee: 3,
+		CreatedAt:   placedAt,
+	}
+	assert.NoError(t, orderDao.CreateOrder(context.Background(), delivery))
+	// Delivery orders can't be stored without an address.
+	assert.Error(t, orderDao.CreateOrder(context.Background(), db.Order{
+		ID:         "order-no-address",
+		Items:      delivery.Items,
+		Fulfilment: &db.Fulfilment{Type: db.FulfilmentDelivery},
+	}))
+
+	tests := []struct {
+		name    string
+		id      db.ID
+		want    db.Order
+		wantErr bool
+	}{
+		{name: "existing order keeps snapshot", id: "order-snapshot", want: placed},
+		{name: "fulfilment is kept", id: "order-delivery", want: delivery},
+		{name: "non-existing order", id: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := orderDao.GetOrder(context.Background(), tt.id)
+			if tt.wantErr {
+				assert.ErrorIs(t, gotErr, sql.ErrNoRows)
+				return
+			}
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGeneralOrder_UpdateOrderStatus(t *testing.T) {
+	sqlDB := setupTestDB(t)
+	orderDao := db.NewOrderDao(sqlDB)
+	ctx := context.Background()
+	pending := db.Order{
+		ID:     "order-pending",
+		Items:  []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5}},
+		Status: db.OrderPending,
+	}
+	assert.NoError(t, orderDao.CreateOrder(ctx, pending))
+
+	assert.NoError(t, orderDao.UpdateOrderStatus(ctx, "order-pending", db.OrderRejected, "invalid coupon code"))
+	got, err := orderDao.GetOrder(ctx, "order-pending")
+	assert.NoError(t, err)
+	assert.Equal(t, db.OrderRejected, got.Status)
+	assert.Equal(t, "invalid coupon code", got.StatusReason)
+
+	assert.ErrorIs(t, orderDao.UpdateOrderStatus(ctx, "missing", db.OrderAccepted, ""), sql.ErrNoRows)
+
+	accepted := pending
+	accepted.ID = "order-accepted"
+	assert.NoError(t, orderDao.CreateOrder(ctx, accepted))
+	assert.Error(t, orderDao.UpdateOrderStatus(ctx, "order-accepted", db.OrderReady, ""), "only accepting or rejecting has an event")
+	assert.NoError(t, orderDao.UpdateOrderStatus(ctx, "order-accepted", db.OrderAccepted, ""))
+	events := map[string]string{}
+	rows, err := sqlDB.Query("SELECT order_id, type FROM outbox WHERE order_id IN ('order-pending', 'order-accepted') AND type != ?", db.EventOrderCreated)
+	assert.NoError(t, err)
+	for rows.Next() {
+		var id, eventType string
+		assert.NoError(t, rows.Scan(&id, &eventType))
+		events[id] = eventType
+	}
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, map[string]string{"order-pending": db.EventOrderRejected, "order-accepted": db.EventOrderAccepted}, events)
+}
+
+func TestGeneralOrder_CreateRefund(t *testing.T) {
+	sqlDB := setupTestDB(t)
+	orderDao := db.NewOrderDao(sqlDB)
+	ctx := context.Background()
+	order := db.Order{
+		ID:         "order-refunded",
+		Items:      []db.Item{{ProductID: "1", Quantity: 2, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5}},
+		CouponCode: "HAPPYHRS",
+		Discounts:  1.3,
+	}
+	assert.NoError(t, orderDao.CreateOrder(ctx, order))
+
+	partial := db.Refund{ID: "refund-1", OrderID: order.ID, Amount: 5, Reason: "cold food", Operator: "jane",
+		Lines: []db.RefundLine{{ProductID: "1", Quantity: 1}}, CreatedAt: time.Now()}
+	_, err := orderDao.CreateRefund(ctx, partial)
+	assert.NoError(t, err)
+
+	var quantity *db.RefundQuantityError
+	twoMore := db.Refund{ID: "refund-2", OrderID: order.ID, Amount: 2, Reason: "cold food", Operator: "jane",
+		Lines: []db.RefundLine{{ProductID: "1", Quantity: 1}, {ProductID: "1", Quantity: 1}}, CreatedAt: time.Now()}
+	_, err = orderDao.CreateRefund(ctx, twoMore)
+	assert.ErrorAs(t, err, &quantity)
+	assert.Equal(t, int32(1), quantity.Remaining)
+
+	var limit *db.RefundLimitError
+	tooMuch := db.Refund{ID: "refund-2", OrderID: order.ID, Amount: 6.71, Reason: "cold food", Operator: "jane", CreatedAt: time.Now()}
+	_, err = orderDao.CreateRefund(ctx, tooMuch)
+	assert.ErrorAs(t, err, &limit)
+	assert.Equal(t, float32(6.7), limit.Remaining)
+
+	rest := db.Refund{ID: "refund-3", OrderID: order.ID, Amount: 6.7, Reason: "cold food", Operator: "jane", CouponReleased: true, CreatedAt: time.Now()}
+	stored, err := orderDao.CreateRefund(ctx, rest)
+	assert.NoError(t, err)
+	assert.True(t, stored.CouponReleased)
+
+	refunds, err := orderDao.GetRefunds(ctx, order.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, refunds, 2) {
+		assert.Equal(t, partial.Lines, refunds[0].Lines)
+		assert.True(t, refunds[1].CouponReleased)
+	}
+	var released sql.NullTime
+	assert.NoError(t, sqlDB.QueryRow("SELECT released_at FROM coupon_redemptions WHERE order_id = ?", order.ID).Scan(&released))
+	assert.True(t, released.Valid)
+
+	between, err := orderDao.GetRefundsBetween(ctx, time.Now().Add(-time.Minute), time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(between), 2)
+}
+
+func TestGeneralOrder_CancelOrder(t *testing.T) {
+	sqlDB := setupTestDB(t)
+	orderDao := db.NewOrderDao(sqlDB)
+	ctx := context.Background()
+	order := db.Order{
+		ID:    "order-cancelled",
+		Items: []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5}},
+	}
+	assert.NoError(t, orderDao.CreateOrder(ctx, order))
+	from := []string{db.OrderPending, db.OrderAccepted}
+	refund := &db.Refund{ID: "refund-cancel", OrderID: order.ID, Amount: 6.5, Reason: "changed my mind", Operator: "customer", CreatedAt: time.Now()}
+
+	assert.NoError(t, orderDao.CancelOrder(ctx, order.ID, "changed my mind", from, refund))
+	got, err := orderDao.GetOrder(ctx, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, db.OrderCancelled, got.Status)
+	assert.Equal(t, "changed my mind", got.StatusReason)
+	refunds, err := orderDao.GetRefunds(ctx, order.ID)
+	assert.NoError(t, err)
+	assert.Len(t, refunds, 1)
+
+	var status *db.OrderStatusError
+	assert.ErrorAs(t, orderDao.CancelOrder(ctx, order.ID, "again", from, nil), &status)
+	assert.Equal(t, db.OrderCancelled, status.Status)
+	assert.ErrorIs(t, orderDao.CancelOrder(ctx, "missing", "", from, nil), sql.ErrNoRows)
+
+	bumped := db.Order{ID: "order-bumped", Status: db.OrderAccepted, Items: []db.Item{
+		{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5},
+		{ProductID: "2", Quantity: 1, Name: "Vanilla Bean Crème Brûlée", Category: "Crème Brûlée", UnitPrice: 7},
+	}}
+	assert.NoError(t, orderDao.CreateOrder(ctx, bumped))
+	assert.NoError(t, db.NewKitchenDao(sqlDB).BumpLine(ctx, bumped.ID, 0))
+	var preparing *db.OrderPreparingError
+	assert.ErrorAs(t, orderDao.CancelOrder(ctx, bumped.ID, "too late", from, nil), &preparing)
+	got, err = orderDao.GetOrder(ctx, bumped.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, db.OrderAccepted, got.Status, "orders the kitchen has started stay")
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// writeEvent adds an event to the outbox inside tx and queues a delivery for
+// every webhook subscribed to its type, so the event is published if and only
+// if the change it describes is committed.
+func writeEvent(ctx context.Context, tx *sql.Tx, eventType string, orderID ID, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	id := uuid.New().String()
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, "INSERT INTO outbox (id, type, order_id, payload, created_at) VALUES (?, ?, ?, ?, ?)",
+		id, eventType, orderID, payload, now); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO webhook_deliveries (id, webhook_id, event_id, status, attempts, next_attempt_at, last_error, updated_at)
+		SELECT lower(hex(randomblob(16))), id, ?, ?, 0, ?, '', ? FROM webhooks
+		WHERE json_array_length(events) = 0 OR EXISTS (SELECT 1 FROM json_each(webhooks.events) WHERE value = ?)`,
+		id, DeliveryPending, now, now, eventType)
+	return err
+}
+
+// orderEventData is the payload of order events: the stored order plus the
+// total it was charged, which isn't stored.
+type orderEventData struct {
+	Order
+	Total float32 `json:"total"`
+}
+
+// writeOrderEvent reads the order back inside tx and writes it as an event.
+func writeOrderEvent(ctx context.Context, tx *sql.Tx, eventType string, id ID) error {
+	order, err := getOrder(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	return writeEvent(ctx, tx, eventType, id, orderEventData{Order: order, Total: order.Total()})
+}
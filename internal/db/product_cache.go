@@ -0,0 +1,336 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCachedPages caps the FindProducts results kept per snapshot, since
+// name filters make the possible filters endless.
+const maxCachedPages = 1024
+
+// CachedProductDao is a ProductDao that serves reads of the catalog from an
+// immutable snapshot held in memory, loaded the first time it is needed.
+// Writes made through it drop the snapshot straight away; changes made
+// elsewhere, by another process or by hand, are noticed by Check through the
+// catalog version the database's triggers keep.
+//
+// Listings that depend on more than the catalog, those of available products
+// or sorted by popularity, and searches still go to the database. Products
+// it returns share their tags, modifiers and images with the snapshot, so
+// callers must not change them.
+type CachedProductDao struct {
+	dao *ProductDaoImpl
+	// snapshot is nil until the catalog is next read.
+	snapshot atomic.Pointer[catalogSnapshot]
+	// generation goes up whenever the snapshot is dropped, so that a load
+	// that started before a write doesn't store what it read.
+	generation atomic.Int64
+	// loading makes concurrent reads of a dropped snapshot load it once.
+	loading sync.Mutex
+}
+
+var _ ProductDao = &CachedProductDao{}
+
+func NewCachedProductDao(db *sql.DB) *CachedProductDao {
+	return &CachedProductDao{dao: &ProductDaoImpl{db: db}}
+}
+
+// catalogSnapshot is every product, archived or not, at one catalog version.
+type catalogSnapshot struct {
+	version int64
+	byID    map[ID]Product
+	bySlug  map[string]ID
+	// onSale are the products that aren't archived, as GetAllProducts
+	// returns them.
+	onSale []Product
+
+	mu sync.Mutex
+	// pages are the FindProducts results worked out so far.
+	pages map[ProductFilter]productPage
+}
+
+type productPage struct {
+	products []Product
+	next     string
+}
+
+// catalogVersion reads the version the catalog triggers bump.
+func catalogVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	var version int64
+	err := db.QueryRowContext(ctx, "SELECT version FROM catalog_version").Scan(&version)
+	return version, err
+}
+
+// current returns the snapshot, loading it if it was dropped.
+func (c *CachedProductDao) current(ctx context.Context) (*catalogSnapshot, error) {
+	if s := c.snapshot.Load(); s != nil {
+		return s, nil
+	}
+	c.loading.Lock()
+	defer c.loading.Unlock()
+	if s := c.snapshot.Load(); s != nil {
+		return s, nil
+	}
+	generation := c.generation.Load()
+	s, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.generation.Load() == generation {
+		c.snapshot.Store(s)
+	}
+	return s, nil
+}
+
+// load reads the whole catalog. The version is read first, so a change made
+// while the products are read makes the snapshot look stale rather than
+// current.
+func (c *CachedProductDao) load(ctx context.Context) (*catalogSnapshot, error) {
+	version, err := catalogVersion(ctx, c.dao.db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.dao.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products ORDER BY rowid")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var products []Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	products, err = c.dao.withAllDetails(ctx, products, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &catalogSnapshot{
+		version: version,
+		byID:    make(map[ID]Product, len(products)),
+		bySlug:  make(map[string]ID, len(products)),
+		pages:   make(map[ProductFilter]productPage),
+	}
+	// Old slugs go in first so that no current one is hidden by them.
+	if err := c.loadOldSlugs(ctx, s.bySlug); err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		s.byID[p.Id] = p
+		if p.Slug != "" {
+			s.bySlug[p.Slug] = p.Id
+		}
+		if p.ArchivedAt.IsZero() {
+			s.onSale = append(s.onSale, p)
+		}
+	}
+	return s, nil
+}
+
+// loadOldSlugs adds the slugs products had before they were renamed to
+// bySlug.
+func (c *CachedProductDao) loadOldSlugs(ctx context.Context, bySlug map[string]ID) error {
+	rows, err := c.dao.db.QueryContext(ctx, "SELECT slug, product_id FROM product_slugs")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var slug string
+		var id ID
+		if err := rows.Scan(&slug, &id); err != nil {
+			return err
+		}
+		bySlug[slug] = id
+	}
+	return rows.Err()
+}
+
+// invalidate drops the snapshot, so the next read loads the catalog again.
+func (c *CachedProductDao) invalidate() {
+	c.generation.Add(1)
+	c.snapshot.Store(nil)
+}
+
+// Check compares the snapshot with the catalog version in the database, and
+// reloads it if the catalog has changed since it was loaded. It reports
+// whether it did.
+func (c *CachedProductDao) Check(ctx context.Context) (bool, error) {
+	s := c.snapshot.Load()
+	if s == nil {
+		return false, nil
+	}
+	version, err := catalogVersion(ctx, c.dao.db)
+	if err != nil || version == s.version {
+		return false, err
+	}
+	c.invalidate()
+	_, err = c.current(ctx)
+	return true, err
+}
+
+// CheckEvery runs Check every interval until ctx is done.
+func (c *CachedProductDao) CheckEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if changed, err := c.Check(ctx); err != nil {
+				log.Printf("checking the catalog cache: %v", err)
+			} else if changed {
+				log.Printf("reloaded the catalog cache after a change elsewhere")
+			}
+		}
+	}
+}
+
+// GetProduct implements ProductDao.
+func (c *CachedProductDao) GetProduct(ctx context.Context, id ID) (Product, error) {
+	s, err := c.current(ctx)
+	if err != nil {
+		return Product{}, err
+	}
+	p, ok := s.byID[id]
+	if !ok {
+		return Product{}, ErrNotFound(sql.ErrNoRows)
+	}
+	return p, nil
+}
+
+// GetProductBySlug implements ProductDao.
+func (c *CachedProductDao) GetProductBySlug(ctx context.Context, slug string) (Product, error) {
+	s, err := c.current(ctx)
+	if err != nil {
+		return Product{}, err
+	}
+	id, ok := s.bySlug[slug]
+	if !ok {
+		return Product{}, ErrNotFound(sql.ErrNoRows)
+	}
+	return s.byID[id], nil
+}
+
+// GetAllProducts implements ProductDao.
+func (c *CachedProductDao) GetAllProducts(ctx context.Context) ([]Product, error) {
+	s, err := c.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(s.onSale), nil
+}
+
+// GetProductsByIDs implements ProductDao.
+func (c *CachedProductDao) GetProductsByIDs(ctx context.Context, ids []ID) (map[ID]Product, error) {
+	s, err := c.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products := make(map[ID]Product, len(ids))
+	var unknown []ID
+	for _, id := range ids {
+		if p, ok := s.byID[id]; ok {
+			products[id] = p
+		} else if !slices.Contains(unknown, id) {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return products, &UnknownProductsError{IDs: unknown}
+	}
+	return products, nil
+}
+
+// FindProducts implements ProductDao. The pages of the snapshot's catalog are
+// only worked out by the database once.
+func (c *CachedProductDao) FindProducts(ctx context.Context, filter ProductFilter) ([]Product, string, error) {
+	if filter.Available || filter.Sort == SortByPopularity {
+		// Stock and orders change all the time.
+		return c.dao.FindProducts(ctx, filter)
+	}
+	s, err := c.current(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	s.mu.Lock()
+	page, ok := s.pages[filter]
+	s.mu.Unlock()
+	if ok {
+		return slices.Clone(page.products), page.next, nil
+	}
+	products, next, err := c.dao.FindProducts(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	// Pages read from a newer catalog than the snapshot's are fine to return,
+	// but not to keep with it.
+	if version, err := catalogVersion(ctx, c.dao.db); err == nil && version == s.version {
+		s.mu.Lock()
+		if len(s.pages) < maxCachedPages {
+			s.pages[filter] = productPage{products: slices.Clone(products), next: next}
+		}
+		s.mu.Unlock()
+	}
+	return products, next, nil
+}
+
+// SearchProducts implements ProductDao.
+func (c *CachedProductDao) SearchProducts(ctx context.Context, query string, limit int) (ProductSearch, error) {
+	return c.dao.SearchProducts(ctx, query, limit)
+}
+
+// CreateProduct implements ProductDao.
+func (c *CachedProductDao) CreateProduct(ctx context.Context, product Product) (Product, error) {
+	defer c.invalidate()
+	return c.dao.CreateProduct(ctx, product)
+}
+
+// UpdateProduct implements ProductDao.
+func (c *CachedProductDao) UpdateProduct(ctx context.Context, product Product, version int64) (Product, error) {
+	defer c.invalidate()
+	return c.dao.UpdateProduct(ctx, product, version)
+}
+
+// PatchProduct implements ProductDao.
+func (c *CachedProductDao) PatchProduct(ctx context.Context, id ID, patch ProductPatch, version int64) (Product, error) {
+	defer c.invalidate()
+	return c.dao.PatchProduct(ctx, id, patch, version)
+}
+
+// SetProductArchived implements ProductDao.
+func (c *CachedProductDao) SetProductArchived(ctx context.Context, id ID, archived bool, version int64) (Product, error) {
+	defer c.invalidate()
+	return c.dao.SetProductArchived(ctx, id, archived, version)
+}
+
+// SetProductImage implements ProductDao.
+func (c *CachedProductDao) SetProductImage(ctx context.Context, productID ID, variant, path string) error {
+	defer c.invalidate()
+	return c.dao.SetProductImage(ctx, productID, variant, path)
+}
+
+// DeleteProductImage implements ProductDao.
+func (c *CachedProductDao) DeleteProductImage(ctx context.Context, productID ID, variant string) error {
+	defer c.invalidate()
+	return c.dao.DeleteProductImage(ctx, productID, variant)
+}
+
+// CatalogChangedAt implements ProductDao. Stock and ingredients change
+// without the catalog, so it is always read from the database.
+func (c *CachedProductDao) CatalogChangedAt(ctx context.Context) (time.Time, error) {
+	return c.dao.CatalogChangedAt(ctx)
+}
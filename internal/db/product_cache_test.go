@@ -0,0 +1,149 @@
+package db_test
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedProductDao(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	ctx := context.Background()
+	g := db.NewProductDao(sqlDB)
+	for _, p := range []db.Product{
+		{Name: "Waffle with Berries", Price: 6.5, Category: "Waffle"},
+		{Name: "Vanilla Bean Crème Brûlée", Price: 7, Category: "Crème Brûlée"},
+		{Name: "Chocolate Waffle", Price: 7.3, Category: "Waffle"},
+	} {
+		_, err := g.CreateProduct(ctx, p)
+		assert.NoError(t, err)
+	}
+	_, err := sqlDB.Exec(`INSERT INTO modifier_groups (id, product_id, name) VALUES ('g1', '1', 'Extras');
+		INSERT INTO modifiers (id, group_id, name, price_delta) VALUES ('m1', 'g1', 'Cream', 1)`)
+	assert.NoError(t, err)
+	cache := db.NewCachedProductDao(sqlDB)
+
+	// Reads come from the snapshot the first read loads, with the same
+	// results the database gives.
+	for _, filter := range []db.ProductFilter{
+		{}, {Sort: db.SortByPriceDesc}, {Category: "waffle", Sort: db.SortByName}, {Limit: 2}, {Name: "waffle"},
+	} {
+		want, wantNext, err := g.FindProducts(ctx, filter)
+		assert.NoError(t, err)
+		for range 2 {
+			got, next, err := cache.FindProducts(ctx, filter)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got, filter)
+			assert.Equal(t, wantNext, next, filter)
+		}
+	}
+	want, err := g.GetProduct(ctx, "1")
+	assert.NoError(t, err)
+	got, err := cache.GetProduct(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Len(t, got.ModifierGroups, 1)
+	got, err = cache.GetProductBySlug(ctx, "chocolate-waffle")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("3"), got.Id)
+	_, err = cache.GetProduct(ctx, "99")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	byID, err := cache.GetProductsByIDs(ctx, []db.ID{"2", "98", "2", "99", "98"})
+	var unknown *db.UnknownProductsError
+	if assert.ErrorAs(t, err, &unknown) {
+		assert.Equal(t, []db.ID{"98", "99"}, unknown.IDs)
+	}
+	assert.Equal(t, "Vanilla Bean Crème Brûlée", byID["2"].Name)
+
+	// Changes made elsewhere show once they are checked for.
+	_, err = sqlDB.Exec("UPDATE products SET price = 8 WHERE id = '2'")
+	assert.NoError(t, err)
+	got, err = cache.GetProduct(ctx, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(7), got.Price, "the snapshot is kept until it is checked")
+	changed, err := cache.Check(ctx)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	got, err = cache.GetProduct(ctx, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(8), got.Price)
+	changed, err = cache.Check(ctx)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// Writes through the cache show straight away.
+	_, err = cache.SetProductArchived(ctx, "1", true, 0)
+	assert.NoError(t, err)
+	all, err := cache.GetAllProducts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ID{"2", "3"}, productIDs(all))
+	products, _, err := cache.FindProducts(ctx, db.ProductFilter{Category: "waffle"})
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ID{"3"}, productIDs(products))
+	archived, err := cache.GetProduct(ctx, "1")
+	assert.NoError(t, err)
+	assert.False(t, archived.ArchivedAt.IsZero(), "archived products are still found by ID")
+	assert.NoError(t, cache.SetProductImage(ctx, "3", db.ImageThumbnail, "waffle.jpg"))
+	got, err = cache.GetProduct(ctx, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, "waffle.jpg", got.Images[db.ImageThumbnail])
+	_, err = cache.UpdateProduct(ctx, db.Product{Id: "3", Name: "Dark Chocolate Waffle", Price: 7.3, Category: "Waffle"}, 0)
+	assert.NoError(t, err)
+	got, err = cache.GetProductBySlug(ctx, "chocolate-waffle")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("3"), got.Id, "old slugs still lead to the product")
+	assert.Equal(t, "dark-chocolate-waffle", got.Slug)
+
+	// Patches are based on the product as it is, not as the snapshot has it.
+	_, err = cache.GetProduct(ctx, "2")
+	assert.NoError(t, err)
+	_, err = sqlDB.Exec("UPDATE products SET price = 9, version = version + 1 WHERE id = '2'")
+	assert.NoError(t, err)
+	name := "Vanilla Crème Brûlée"
+	got, err = cache.PatchProduct(ctx, "2", db.ProductPatch{Name: &name}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(9), got.Price)
+	assert.Equal(t, name, got.Name)
+}
+
+func TestCachedProductDao_Concurrent(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "catalog.sqlite3")+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	ctx := context.Background()
+	if err := db.Migrate(ctx, sqlDB); err != nil {
+		t.Fatalf("migrating schema failed: %v", err)
+	}
+	cache := db.NewCachedProductDao(sqlDB)
+	_, err = cache.CreateProduct(ctx, db.Product{Name: "Waffle with Berries", Price: 1, Category: "Waffle"})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if _, err := cache.GetProductsByIDs(ctx, []db.ID{"1"}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for price := float32(2); price <= 20; price++ {
+		_, err := cache.UpdateProduct(ctx, db.Product{Id: "1", Name: "Waffle with Berries", Price: price, Category: "Waffle"}, 0)
+		assert.NoError(t, err)
+	}
+	wg.Wait()
+	p, err := cache.GetProduct(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(20), p.Price, "no read keeps a snapshot from before the last write")
+}
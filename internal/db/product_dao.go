@@ -3,10 +3,17 @@ package db
 import (
 	"context"
 	"database/sql"
-)
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-// product_dao.go is intentionally minimal for now to avoid parse errors during build.
-// TODO: Implement Product DAO methods.
+	"github.com/go-playground/validator/v10"
+)
 
 type ProductDaoImpl struct {
 	db *sql.DB
@@ -17,34 +24,716 @@ func NewProductDao(db *sql.DB) ProductDao {
 	return &ProductDaoImpl{db: db}
 }
 
+// productColumns are the columns of products that scanProduct reads.
+const productColumns = `products.id, products.slug, products.name, products.price, products.category,
+	(SELECT slug FROM categories WHERE categories.id = products.category_id),
+	products.description, products.tags, products.version, products.archived_at`
+
+// scanProduct reads the productColumns of row, and then any extra columns
+// into extra.
+func scanProduct(row interface{ Scan(...any) error }, extra ...any) (Product, error) {
+	var p Product
+	var slug, categorySlug sql.NullString
+	var tags string
+	var archivedAt sql.NullTime
+	dest := append([]any{&p.Id, &slug, &p.Name, &p.Price, &p.Category, &categorySlug, &p.Description, &tags, &p.Version, &archivedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return Product{}, err
+	}
+	p.Slug = slug.String
+	p.CategorySlug = categorySlug.String
+	if err := json.Unmarshal([]byte(tags), &p.Tags); err != nil {
+		return Product{}, fmt.Errorf("tags of product %s: %w", p.Id, err)
+	}
+	if len(p.Tags) == 0 {
+		p.Tags = nil
+	}
+	p.ArchivedAt = archivedAt.Time
+	return p, nil
+}
+
+// productTags is the stored form of the tags of p.
+func productTags(p Product) (string, error) {
+	if len(p.Tags) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal(p.Tags)
+	return string(data), err
+}
+
 // GetAllProducts implements ProductDao.
 func (g *ProductDaoImpl) GetAllProducts(ctx context.Context) (products []Product, err error) {
-	rows, err := g.db.QueryContext(ctx, "SELECT id, name, price, category FROM products")
+	rows, err := g.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products WHERE archived_at IS NULL")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var p Product
-		if err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.Category); err != nil {
+		p, err := scanProduct(rows)
+		if err != nil {
 			return nil, err
 		}
 		products = append(products, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return g.withAllDetails(ctx, products, nil)
+}
+
+// productSortKeys are the expressions products are ordered by for each sort
+// order, whether the order is descending, and the join the expressions need.
+var productSortKeys = map[string]struct {
+	exprs []string
+	desc  bool
+	join  string
+}{
+	SortByMenu: {
+		[]string{"COALESCE(categories.position, 2147483647)", "products.category", "products.name"}, false,
+		" LEFT JOIN categories ON categories.id = products.category_id",
+	},
+	SortByName:       {[]string{"products.name"}, false, ""},
+	SortByNameDesc:   {[]string{"products.name"}, true, ""},
+	SortByPrice:      {[]string{"products.price"}, false, ""},
+	SortByPriceDesc:  {[]string{"products.price"}, true, ""},
+	SortByPopularity: {[]string{"COALESCE(sold.units, 0)"}, true, " LEFT JOIN sold ON sold.product_id = products.id"},
+}
+
+// productCursor is where a page of FindProducts ended: the sort keys and ID
+// of its last product.
+type productCursor struct {
+	Sort string `json:"s"`
+	Keys []any  `json:"k"`
+	ID   ID     `json:"id"`
+}
+
+// FindProducts implements ProductDao.
+func (g *ProductDaoImpl) FindProducts(ctx context.Context, filter ProductFilter) ([]Product, string, error) {
+	if filter.Sort == "" {
+		filter.Sort = SortByMenu
+	}
+	sortKey, ok := productSortKeys[filter.Sort]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown sort order %q", filter.Sort)
+	}
+
+	var query strings.Builder
+	var args []any
+	if filter.Sort == SortByPopularity {
+		// Orders keep their lines as JSON, stored as a BLOB.
+		query.WriteString(`WITH sold(product_id, units) AS (
+			SELECT json_extract(line.value, '$.product_id'), SUM(json_extract(line.value, '$.quantity'))
+			FROM orders, json_each(CAST(orders.items AS TEXT)) AS line
+			WHERE orders.status IN (?, ?) AND orders.created_at >= ?
+			GROUP BY 1)
+		`)
+		args = append(args, OrderAccepted, OrderReady, time.Now().Add(-PopularityWindow).UTC())
+	}
+	keys := strings.Join(sortKey.exprs, ", ")
+	query.WriteString("SELECT " + productColumns + ", " + keys + " FROM products" + sortKey.join)
+	query.WriteString(" WHERE products.archived_at IS NULL AND IFNULL(products.category_id, 0) NOT IN (" + hiddenCategories + ")")
+	if filter.Category != "" {
+		query.WriteString(" AND products.category_id IN (" + categoryTree + ")")
+		args = append(args, filter.Category)
+	}
+	if filter.MinPrice > 0 {
+		query.WriteString(" AND products.price >= ?")
+		args = append(args, filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		query.WriteString(" AND products.price <= ?")
+		args = append(args, filter.MaxPrice)
+	}
+	if filter.Name != "" {
+		query.WriteString(` AND products.name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+	if filter.Available {
+		// Like the menu's availability: tracked stock left, and enough of
+		// every ingredient for one.
+		query.WriteString(` AND NOT EXISTS (SELECT 1 FROM stock WHERE stock.product_id = products.id AND stock.on_hand <= 0)
+			AND NOT EXISTS (SELECT 1 FROM recipes r JOIN ingredients i ON i.id = r.ingredient_id
+				WHERE r.product_id = products.id AND r.modifier_id = '' AND i.on_hand + ? < r.quantity)`)
+		args = append(args, ingredientSlack)
+	}
+	op, dir := ">", " ASC"
+	if sortKey.desc {
+		op, dir = "<", " DESC"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeProductCursor(filter.Cursor)
+		if err != nil || cursor.Sort != filter.Sort || len(cursor.Keys) != len(sortKey.exprs) {
+			return nil, "", &InvalidCursorError{Cursor: filter.Cursor}
+		}
+		query.WriteString(" AND (" + keys + ", products.id) " + op + " (?" + strings.Repeat(", ?", len(cursor.Keys)) + ")")
+		args = append(append(args, cursor.Keys...), cursor.ID)
+	}
+	query.WriteString(" ORDER BY " + strings.Join(sortKey.exprs, dir+", ") + dir + ", products.id" + dir)
+	if filter.Limit > 0 {
+		// One more than asked for tells whether there is another page.
+		query.WriteString(" LIMIT ?")
+		args = append(args, filter.Limit+1)
+	}
+
+	rows, err := g.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	var products []Product
+	var productKeys [][]any
+	for rows.Next() {
+		key := make([]any, len(sortKey.exprs))
+		dest := make([]any, len(key))
+		for i := range key {
+			dest[i] = &key[i]
+		}
+		p, err := scanProduct(rows, dest...)
+		if err != nil {
+			return nil, "", err
+		}
+		products = append(products, p)
+		productKeys = append(productKeys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if filter.Limit > 0 && len(products) > filter.Limit {
+		products = products[:filter.Limit]
+		last := products[len(products)-1]
+		next, err = encodeProductCursor(productCursor{Sort: filter.Sort, Keys: productKeys[len(products)-1], ID: last.Id})
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	ids := make([]any, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.Id)
+	}
+	products, err = g.withAllDetails(ctx, products, ids)
+	if err != nil {
+		return nil, "", err
+	}
+	return products, next, nil
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, for ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func encodeProductCursor(c productCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeProductCursor(s string) (productCursor, error) {
+	var c productCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	for _, key := range c.Keys {
+		switch key.(type) {
+		case string, float64:
+		default:
+			return c, fmt.Errorf("cursor key %v is not a string or number", key)
+		}
+	}
+	return c, nil
+}
+
+// withAllDetails fills in the modifier groups and pictures of products, which
+// are the products with the given IDs, or every product if ids is nil.
+func (g *ProductDaoImpl) withAllDetails(ctx context.Context, products []Product, ids []any) ([]Product, error) {
+	groups, err := g.getModifierGroups(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	images, err := g.getImages(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].ModifierGroups = groups[products[i].Id]
+		products[i].Images = images[products[i].Id]
+	}
 	return products, nil
 }
 
 // GetProduct implements ProductDao.
 func (g *ProductDaoImpl) GetProduct(ctx context.Context, id ID) (Product, error) {
-	row := g.db.QueryRowContext(ctx, "SELECT id, name, price, category FROM products WHERE id = ?", id)
+	row := g.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = ?", id)
 	if row == nil {
 		return Product{}, ErrNotFound(sql.ErrNoRows)
 	}
-	var p Product
-	if err := row.Scan(&p.Id, &p.Name, &p.Price, &p.Category); err != nil {
+	p, err := scanProduct(row)
+	if err != nil {
+		return Product{}, err
+	}
+	return g.withDetails(ctx, p)
+}
+
+// GetProductBySlug implements ProductDao.
+func (g *ProductDaoImpl) GetProductBySlug(ctx context.Context, slug string) (Product, error) {
+	p, err := scanProduct(g.db.QueryRowContext(ctx, "SELECT "+productColumns+` FROM products
+		WHERE id = COALESCE((SELECT id FROM products WHERE slug = ?), (SELECT product_id FROM product_slugs WHERE slug = ?))`,
+		slug, slug))
+	if err != nil {
+		return Product{}, ErrNotFound(err)
+	}
+	return g.withDetails(ctx, p)
+}
+
+// withDetails fills in the modifier groups and pictures of p.
+func (g *ProductDaoImpl) withDetails(ctx context.Context, p Product) (Product, error) {
+	groups, err := g.getModifierGroups(ctx, []any{p.Id})
+	if err != nil {
 		return Product{}, err
 	}
+	images, err := g.getImages(ctx, []any{p.Id})
+	if err != nil {
+		return Product{}, err
+	}
+	p.ModifierGroups = groups[p.Id]
+	p.Images = images[p.Id]
 	return p, nil
 }
 
+// GetProductsByIDs implements ProductDao.
+func (g *ProductDaoImpl) GetProductsByIDs(ctx context.Context, ids []ID) (map[ID]Product, error) {
+	products := make(map[ID]Product, len(ids))
+	if len(ids) == 0 {
+		return products, nil
+	}
+	args := make([]any, 0, len(ids))
+	seen := make(map[ID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			args = append(args, id)
+		}
+	}
+	query := "SELECT " + productColumns + " FROM products WHERE id IN (?" + strings.Repeat(", ?", len(args)-1) + ")"
+	rows, err := g.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products[p.Id] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	groups, err := g.getModifierGroups(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	images, err := g.getImages(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	for id, p := range products {
+		p.ModifierGroups = groups[id]
+		p.Images = images[id]
+		products[id] = p
+	}
+	var unknown []ID
+	for _, id := range args {
+		if _, ok := products[id.(ID)]; !ok {
+			unknown = append(unknown, id.(ID))
+		}
+	}
+	if len(unknown) > 0 {
+		return products, &UnknownProductsError{IDs: unknown}
+	}
+	return products, nil
+}
+
+// getModifierGroups loads the modifier groups of the given products, or of
+// every product if ids is nil, keyed by product ID and in menu order.
+func (g *ProductDaoImpl) getModifierGroups(ctx context.Context, ids []any) (map[ID][]ModifierGroup, error) {
+	query := `SELECT g.product_id, g.id, g.name, g.required, g.min_select, g.max_select, m.id, m.name, m.price_delta
+		FROM modifier_groups g LEFT JOIN modifiers m ON m.group_id = g.id`
+	if ids != nil {
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		query += " WHERE g.product_id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+	}
+	query += " ORDER BY g.product_id, g.position, g.id, m.position, m.id"
+	rows, err := g.db.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	groups := make(map[ID][]ModifierGroup)
+	for rows.Next() {
+		var productID ID
+		var group ModifierGroup
+		var modifierID, modifierName sql.NullString
+		var priceDelta sql.NullFloat64
+		if err := rows.Scan(&productID, &group.ID, &group.Name, &group.Required, &group.MinSelect, &group.MaxSelect, &modifierID, &modifierName, &priceDelta); err != nil {
+			return nil, err
+		}
+		productGroups := groups[productID]
+		if n := len(productGroups); n == 0 || productGroups[n-1].ID != group.ID {
+			productGroups = append(productGroups, group)
+		}
+		if modifierID.Valid {
+			last := &productGroups[len(productGroups)-1]
+			last.Options = append(last.Options, Modifier{
+				ID:         modifierID.String,
+				GroupID:    group.ID,
+				Name:       modifierName.String,
+				PriceDelta: float32(priceDelta.Float64),
+			})
+		}
+		groups[productID] = productGroups
+	}
+	return groups, rows.Err()
+}
+
+// getImages loads the pictures of the given products, or of every product if
+// ids is nil, keyed by product ID and then variant.
+func (g *ProductDaoImpl) getImages(ctx context.Context, ids []any) (map[ID]map[string]string, error) {
+	query := "SELECT product_id, variant, path FROM product_images"
+	if ids != nil {
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		query += " WHERE product_id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+	}
+	rows, err := g.db.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	images := make(map[ID]map[string]string)
+	for rows.Next() {
+		var productID ID
+		var variant, path string
+		if err := rows.Scan(&productID, &variant, &path); err != nil {
+			return nil, err
+		}
+		if images[productID] == nil {
+			images[productID] = make(map[string]string)
+		}
+		images[productID][variant] = path
+	}
+	return images, rows.Err()
+}
+
+// SetProductImage implements ProductDao.
+func (g *ProductDaoImpl) SetProductImage(ctx context.Context, productID ID, variant, path string) error {
+	res, err := g.db.ExecContext(ctx, `INSERT INTO product_images (product_id, variant, path)
+		SELECT id, ?, ? FROM products WHERE id = ?
+		ON CONFLICT (product_id, variant) DO UPDATE SET path = excluded.path`, variant, path, productID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound(sql.ErrNoRows)
+	}
+	return nil
+}
+
+// DeleteProductImage implements ProductDao.
+func (g *ProductDaoImpl) DeleteProductImage(ctx context.Context, productID ID, variant string) error {
+	_, err := g.db.ExecContext(ctx, "DELETE FROM product_images WHERE product_id = ? AND variant = ?", productID, variant)
+	return err
+}
+
+// CreateProduct implements ProductDao.
+func (g *ProductDaoImpl) CreateProduct(ctx context.Context, product Product) (Product, error) {
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Product{}, err
+	}
+	defer tx.Rollback()
+	if product.Id == "" {
+		var next int64
+		if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(CAST(id AS INTEGER)), 0) + 1 FROM products").Scan(&next); err != nil {
+			return Product{}, err
+		}
+		// Numbers already taken as slugs are skipped.
+		for ; ; next++ {
+			product.Id = strconv.FormatInt(next, 10)
+			taken, err := idTaken(ctx, tx, product.Id)
+			if err != nil {
+				return Product{}, err
+			} else if !taken {
+				break
+			}
+		}
+	} else if taken, err := idTaken(ctx, tx, product.Id); err != nil {
+		return Product{}, err
+	} else if taken {
+		return Product{}, &ProductIDError{ID: product.Id}
+	}
+	validate := validator.New()
+	if err := validate.Struct(product); err != nil {
+		return Product{}, err
+	}
+	tags, err := productTags(product)
+	if err != nil {
+		return Product{}, err
+	}
+	categoryID, category, err := categoryFor(ctx, tx, product.Category)
+	if err != nil {
+		return Product{}, err
+	}
+	slug, err := productSlug(ctx, tx, product.Id, product.Name)
+	if err != nil {
+		return Product{}, err
+	}
+	row := tx.QueryRowContext(ctx, `INSERT INTO products (id, slug, name, price, category, category_id, description, tags, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1) RETURNING `+productColumns,
+		product.Id, slug, product.Name, product.Price, category, categoryID, product.Description, tags)
+	created, err := scanProduct(row)
+	if err != nil {
+		return Product{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Product{}, err
+	}
+	return created, nil
+}
+
+// UpdateProduct implements ProductDao.
+func (g *ProductDaoImpl) UpdateProduct(ctx context.Context, product Product, version int64) (Product, error) {
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Product{}, err
+	}
+	defer tx.Rollback()
+	return g.update(ctx, tx, product, version)
+}
+
+// PatchProduct implements ProductDao.
+func (g *ProductDaoImpl) PatchProduct(ctx context.Context, id ID, patch ProductPatch, version int64) (Product, error) {
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Product{}, err
+	}
+	defer tx.Rollback()
+	product, err := scanProduct(tx.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = ?", id))
+	if err != nil {
+		return Product{}, err
+	}
+	if version != 0 && version != product.Version {
+		return Product{}, &ProductVersionError{ID: id, Version: product.Version}
+	}
+	if patch.Name != nil {
+		product.Name = *patch.Name
+	}
+	if patch.Price != nil {
+		product.Price = *patch.Price
+	}
+	if patch.Category != nil {
+		product.Category = *patch.Category
+	}
+	if patch.Description != nil {
+		product.Description = *patch.Description
+	}
+	if patch.Tags != nil {
+		product.Tags = *patch.Tags
+	}
+	return g.update(ctx, tx, product, product.Version)
+}
+
+// update replaces the product within tx, at the given version, and commits.
+func (g *ProductDaoImpl) update(ctx context.Context, tx *sql.Tx, product Product, version int64) (Product, error) {
+	validate := validator.New()
+	if err := validate.Struct(product); err != nil {
+		return Product{}, err
+	}
+	tags, err := productTags(product)
+	if err != nil {
+		return Product{}, err
+	}
+	categoryID, category, err := categoryFor(ctx, tx, product.Category)
+	if err != nil {
+		return Product{}, err
+	}
+	slug, err := renamedSlug(ctx, tx, product.Id, product.Name)
+	if err != nil {
+		return Product{}, err
+	}
+	row := tx.QueryRowContext(ctx, `UPDATE products SET name = ?, slug = COALESCE(?, slug), price = ?, category = ?, category_id = ?,
+		description = ?, tags = ?, version = version + 1 WHERE id = ? AND (? = 0 OR version = ?) RETURNING `+productColumns,
+		product.Name, slug, product.Price, category, categoryID, product.Description, tags, product.Id, version, version)
+	p, err := scanProduct(row)
+	if err != nil {
+		// Leave any category made for the product out, too.
+		tx.Rollback()
+		return Product{}, g.writeFailure(ctx, err, product.Id)
+	}
+	if err := tx.Commit(); err != nil {
+		return Product{}, err
+	}
+	return g.withDetails(ctx, p)
+}
+
+// SetProductArchived implements ProductDao.
+func (g *ProductDaoImpl) SetProductArchived(ctx context.Context, id ID, archived bool, version int64) (Product, error) {
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now().UTC()
+		archivedAt = &now
+	}
+	row := g.db.QueryRowContext(ctx, `UPDATE products SET archived_at = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?) RETURNING `+productColumns,
+		archivedAt, id, version, version)
+	return g.written(ctx, row, id)
+}
+
+// written reads back the product a versioned write returned.
+func (g *ProductDaoImpl) written(ctx context.Context, row *sql.Row, id ID) (Product, error) {
+	p, err := scanProduct(row)
+	if err != nil {
+		return Product{}, g.writeFailure(ctx, err, id)
+	}
+	return g.withDetails(ctx, p)
+}
+
+// writeFailure explains why a versioned write of a product failed. When the
+// write matched no row, it finds out whether the product is missing or has
+// moved on to another version.
+func (g *ProductDaoImpl) writeFailure(ctx context.Context, err error, id ID) error {
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	var current int64
+	if err := g.db.QueryRowContext(ctx, "SELECT version FROM products WHERE id = ?", id).Scan(&current); err != nil {
+		return err
+	}
+	return &ProductVersionError{ID: id, Version: current}
+}
+
+// CatalogChangedAt implements ProductDao.
+func (g *ProductDaoImpl) CatalogChangedAt(ctx context.Context) (time.Time, error) {
+	var changedAt time.Time
+	err := g.db.QueryRowContext(ctx, "SELECT changed_at FROM catalog_version").Scan(&changedAt)
+	return changedAt, err
+}
+
 var _ ProductDao = &ProductDaoImpl{}
+
+// reservedSlugs are the paths under /product that aren't products, so no
+// product's slug may be one of them.
+var reservedSlugs = []string{"search"}
+
+// productSlug makes the slug of the product with the given ID and name: the
+// slug of its name, numbered when another product has that slug or ID, since
+// lookups try IDs first, or when it is reserved. Names without letters or
+// digits fall back to the ID.
+func productSlug(ctx context.Context, tx *sql.Tx, id ID, name string) (string, error) {
+	base := Slug(name)
+	if base == "" {
+		base = "product-" + Slug(id)
+	}
+	for n := 1; ; n++ {
+		slug := base
+		if n > 1 {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		if slices.Contains(reservedSlugs, slug) {
+			continue
+		}
+		var taken bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE (slug = ? OR id = ?) AND id != ?)
+			OR EXISTS (SELECT 1 FROM product_slugs WHERE slug = ? AND product_id != ?)`,
+			slug, slug, id, slug, id).Scan(&taken)
+		if err != nil || !taken {
+			return slug, err
+		}
+	}
+}
+
+// idTaken tells whether a new product can't have the given ID because it is
+// reserved or another product's slug, which would hide one of them.
+func idTaken(ctx context.Context, tx *sql.Tx, id ID) (bool, error) {
+	if slices.Contains(reservedSlugs, id) {
+		return true, nil
+	}
+	var taken bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE slug = ?)
+		OR EXISTS (SELECT 1 FROM product_slugs WHERE slug = ?)`, id, id).Scan(&taken)
+	return taken, err
+}
+
+// renamedSlug makes the new slug of the product with the given ID when it is
+// renamed to name, and keeps the old one in product_slugs so links to it
+// still find the product. It is nil when the product keeps its slug: when its
+// name makes the same slug, or there is no such product.
+func renamedSlug(ctx context.Context, tx *sql.Tx, id ID, name string) (*string, error) {
+	var current string
+	var old sql.NullString
+	err := tx.QueryRowContext(ctx, "SELECT name, slug FROM products WHERE id = ?", id).Scan(&current, &old)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && Slug(current) == Slug(name) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	slug, err := productSlug(ctx, tx, id, name)
+	if err != nil || !old.Valid || old.String == slug {
+		return &slug, err
+	}
+	// Going back to an old name takes its slug back out of the history.
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_slugs WHERE slug = ?", slug); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT OR REPLACE INTO product_slugs (slug, product_id) VALUES (?, ?)", old.String, id)
+	return &slug, err
+}
+
+// slugProducts gives the products that have no slug yet theirs, in the order
+// they were added.
+func slugProducts(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, "SELECT id, name FROM products WHERE slug IS NULL ORDER BY rowid")
+	if err != nil {
+		return err
+	}
+	var products []Product
+	for rows.Next() {
+		var p Product
+		if err := rows.Scan(&p.Id, &p.Name); err != nil {
+			rows.Close()
+			return err
+		}
+		products = append(products, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, p := range products {
+		slug, err := productSlug(ctx, tx, p.Id, p.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET slug = ? WHERE id = ?", slug, p.Id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
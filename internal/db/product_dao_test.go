@@ -3,7 +3,9 @@ package db_test
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	_ "github.com/mattn/go-sqlite3"
@@ -18,14 +20,8 @@ func setupProductTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("sql.Open failed: %v", err)
 	}
-	createStmt := `CREATE TABLE IF NOT EXISTS products (
-		id TEXT PRIMARY KEY,
-		name TEXT,
-		price INTEGER,
-		category TEXT
-	);`
-	if _, err := d.Exec(createStmt); err != nil {
-		t.Fatalf("creating table failed: %v", err)
+	if err := db.Migrate(context.Background(), d); err != nil {
+		t.Fatalf("db.Migrate failed: %v", err)
 	}
 	t.Cleanup(func() { _ = d.Close() })
 	return d
@@ -88,6 +84,7 @@ func TestGeneralProduct_GetAllProducts(t *testing.T) {
 			for _, want := range tt.products {
 				got, exists := productMap[want.Id]
 				assert.True(t, exists, "product ID %s not found in DB", want.Id)
+				want.Version = 1
 				assert.Equal(t, want, got)
 			}
 		})
@@ -108,7 +105,7 @@ func TestGeneralProduct_GetProduct(t *testing.T) {
 		{
 			name:    "existing product",
 			id:      "1",
-			want:    db.Product{Id: "1", Name: "chicken waffle", Price: 120, Category: "Waffles"},
+			want:    db.Product{Id: "1", Name: "chicken waffle", Price: 120, Category: "Waffles", Version: 1},
 			wantErr: false,
 		},
 		{
@@ -129,3 +126,399 @@ func TestGeneralProduct_GetProduct(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneralProduct_GetProductsByIDs(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	err := CreateProducts(t, []db.Product{
+		{Id: "1", Name: "chicken waffle", Price: 120, Category: "Waffles"},
+		{Id: "2", Name: "lemon pie", Price: 50, Category: "Pie"},
+	}, sqlDB)
+	assert.NoError(t, err)
+	tests := []struct {
+		name        string
+		ids         []db.ID
+		want        map[db.ID]db.Product
+		wantUnknown []db.ID
+	}{
+		{
+			name: "all products exist",
+			ids:  []db.ID{"2", "1", "2"},
+			want: map[db.ID]db.Product{
+				"1": {Id: "1", Name: "chicken waffle", Price: 120, Category: "Waffles", Version: 1},
+				"2": {Id: "2", Name: "lemon pie", Price: 50, Category: "Pie", Version: 1},
+			},
+		},
+		{
+			name: "every unknown product is reported",
+			ids:  []db.ID{"x", "1", "y"},
+			want: map[db.ID]db.Product{
+				"1": {Id: "1", Name: "chicken waffle", Price: 120, Category: "Waffles", Version: 1},
+			},
+			wantUnknown: []db.ID{"x", "y"},
+		},
+		{
+			name: "no ids",
+			ids:  nil,
+			want: map[db.ID]db.Product{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := db.NewProductDao(sqlDB)
+			got, gotErr := g.GetProductsByIDs(context.Background(), tt.ids)
+			assert.Equal(t, tt.want, got)
+			if tt.wantUnknown == nil {
+				assert.NoError(t, gotErr)
+				return
+			}
+			var unknown *db.UnknownProductsError
+			if assert.ErrorAs(t, gotErr, &unknown) {
+				assert.Equal(t, tt.wantUnknown, unknown.IDs)
+			}
+		})
+	}
+}
+
+func TestGeneralProduct_ModifierGroups(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	err := CreateProducts(t, []db.Product{
+		{Id: "1", Name: "chicken waffle", Price: 12, Category: "Waffles"},
+		{Id: "2", Name: "lemon pie", Price: 5, Category: "Pie"},
+	}, sqlDB)
+	assert.NoError(t, err)
+	for _, stmt := range []string{
+		`INSERT INTO modifier_groups (id, product_id, name, required, min_select, max_select, position) VALUES
+			('extras', '1', 'Extras', 0, 0, 2, 2), ('size', '1', 'Size', 1, 1, 1, 1), ('empty', '1', 'Empty', 0, 0, 0, 3)`,
+		`INSERT INTO modifiers (id, group_id, name, price_delta, position) VALUES
+			('syrup', 'extras', 'Extra syrup', 0.5, 1), ('no-nuts', 'extras', 'No nuts', 0, 2),
+			('large', 'size', 'Large', 1.5, 2), ('small', 'size', 'Small', -1, 1)`,
+	} {
+		_, err := sqlDB.Exec(stmt)
+		assert.NoError(t, err)
+	}
+	want := []db.ModifierGroup{
+		{ID: "size", Name: "Size", Required: true, MinSelect: 1, MaxSelect: 1, Options: []db.Modifier{
+			{ID: "small", GroupID: "size", Name: "Small", PriceDelta: -1},
+			{ID: "large", GroupID: "size", Name: "Large", PriceDelta: 1.5},
+		}},
+		{ID: "extras", Name: "Extras", MaxSelect: 2, Options: []db.Modifier{
+			{ID: "syrup", GroupID: "extras", Name: "Extra syrup", PriceDelta: 0.5},
+			{ID: "no-nuts", GroupID: "extras", Name: "No nuts"},
+		}},
+		{ID: "empty", Name: "Empty"},
+	}
+	g := db.NewProductDao(sqlDB)
+
+	product, err := g.GetProduct(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, want, product.ModifierGroups)
+
+	products, err := g.GetProductsByIDs(context.Background(), []db.ID{"1", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, want, products["1"].ModifierGroups)
+	assert.Nil(t, products["2"].ModifierGroups)
+
+	all, err := g.GetAllProducts(context.Background())
+	assert.NoError(t, err)
+	for _, p := range all {
+		if p.Id == "1" {
+			assert.Equal(t, want, p.ModifierGroups)
+		} else {
+			assert.Nil(t, p.ModifierGroups)
+		}
+	}
+}
+
+func TestGeneralProduct_Images(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	err := CreateProducts(t, []db.Product{
+		{Id: "1", Name: "chicken waffle", Price: 12, Category: "Waffles"},
+		{Id: "2", Name: "lemon pie", Price: 5, Category: "Pie"},
+	}, sqlDB)
+	assert.NoError(t, err)
+	g := db.NewProductDao(sqlDB)
+	ctx := context.Background()
+
+	assert.NoError(t, g.SetProductImage(ctx, "1", db.ImageThumbnail, "waffle/old.jpg"))
+	assert.NoError(t, g.SetProductImage(ctx, "1", db.ImageThumbnail, "waffle/thumbnail.jpg"))
+	assert.NoError(t, g.SetProductImage(ctx, "1", db.ImageDesktop, "https://cdn.example.com/waffle.jpg"))
+	assert.NoError(t, g.SetProductImage(ctx, "2", db.ImageMobile, "pie/mobile.jpg"))
+	assert.ErrorIs(t, g.SetProductImage(ctx, "99", db.ImageMobile, "missing.jpg"), sql.ErrNoRows)
+	assert.Error(t, g.SetProductImage(ctx, "1", "poster", "waffle/poster.jpg"), "unknown variants are refused")
+	assert.NoError(t, g.DeleteProductImage(ctx, "2", db.ImageMobile))
+
+	want := map[string]string{db.ImageThumbnail: "waffle/thumbnail.jpg", db.ImageDesktop: "https://cdn.example.com/waffle.jpg"}
+	product, err := g.GetProduct(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, want, product.Images)
+
+	products, err := g.GetProductsByIDs(ctx, []db.ID{"1", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, want, products["1"].Images)
+	assert.Nil(t, products["2"].Images)
+
+	all, err := g.GetAllProducts(ctx)
+	assert.NoError(t, err)
+	for _, p := range all {
+		if p.Id == "1" {
+			assert.Equal(t, want, p.Images)
+		} else {
+			assert.Nil(t, p.Images)
+		}
+	}
+}
+
+func TestGeneralProduct_Write(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	err := CreateProducts(t, []db.Product{{Id: "7", Name: "chicken waffle", Price: 12, Category: "Waffles"}}, sqlDB)
+	assert.NoError(t, err)
+	g := db.NewProductDao(sqlDB)
+	ctx := context.Background()
+
+	created, err := g.CreateProduct(ctx, db.Product{Name: "lemon pie", Price: 5, Category: "Pie"})
+	assert.NoError(t, err)
+	assert.Equal(t, db.Product{Id: "8", Name: "lemon pie", Price: 5, Category: "Pie", Slug: "lemon-pie", CategorySlug: "pie", Version: 1}, created, "IDs follow the highest numeric one")
+	_, err = g.CreateProduct(ctx, db.Product{Name: "free pie", Price: -1, Category: "Pie"})
+	assert.Error(t, err, "prices can't be negative")
+	_, err = g.CreateProduct(ctx, db.Product{Price: 5, Category: "Pie"})
+	assert.Error(t, err, "names are required")
+
+	updated, err := g.UpdateProduct(ctx, db.Product{Id: "8", Name: "lemon meringue pie", Price: 6, Category: "Pie"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Product{Id: "8", Name: "lemon meringue pie", Price: 6, Category: "Pie", Slug: "lemon-meringue-pie", CategorySlug: "pie", Version: 2}, updated, "slugs follow renames")
+	_, err = g.UpdateProduct(ctx, db.Product{Id: "8", Name: "key lime pie", Price: 6, Category: "Pie"}, 1)
+	var stale *db.ProductVersionError
+	if assert.ErrorAs(t, err, &stale) {
+		assert.Equal(t, int64(2), stale.Version)
+	}
+	_, err = g.UpdateProduct(ctx, db.Product{Id: "99", Name: "key lime pie", Price: 6, Category: "Pie"}, 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	archived, err := g.SetProductArchived(ctx, "8", true, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), archived.Version)
+	assert.False(t, archived.ArchivedAt.IsZero())
+	all, err := g.GetAllProducts(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, all, 1, "archived products are off the menu")
+	byID, err := g.GetProductsByIDs(ctx, []db.ID{"8"})
+	assert.NoError(t, err)
+	assert.False(t, byID["8"].ArchivedAt.IsZero())
+
+	restored, err := g.SetProductArchived(ctx, "8", false, 0)
+	assert.NoError(t, err, "version 0 matches any version")
+	assert.Equal(t, int64(4), restored.Version)
+	assert.True(t, restored.ArchivedAt.IsZero())
+
+	name := "key lime pie"
+	patched, err := g.PatchProduct(ctx, "8", db.ProductPatch{Name: &name}, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Product{Id: "8", Name: "key lime pie", Price: 6, Category: "Pie", Slug: "key-lime-pie", CategorySlug: "pie", Version: 5}, patched,
+		"only the fields set change")
+	_, err = g.PatchProduct(ctx, "8", db.ProductPatch{Name: &name}, 4)
+	if assert.ErrorAs(t, err, &stale) {
+		assert.Equal(t, int64(5), stale.Version)
+	}
+	price := float32(-1)
+	_, err = g.PatchProduct(ctx, "8", db.ProductPatch{Price: &price}, 0)
+	assert.Error(t, err, "patched products are validated")
+	_, err = g.PatchProduct(ctx, "99", db.ProductPatch{Name: &name}, 0)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGeneralProduct_Slugs(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	err := CreateProducts(t, []db.Product{
+		{Id: "1", Name: "Chicken Waffle", Price: 12, Category: "Waffles"},
+		{Id: "2", Name: "chicken waffle!", Price: 12, Category: "Waffles"},
+		{Id: "lemon-pie", Name: "Lemon Meringue", Price: 5, Category: "Pie"},
+	}, sqlDB)
+	assert.NoError(t, err)
+	// Products are given slugs when the database is opened.
+	assert.NoError(t, db.Migrate(context.Background(), sqlDB))
+	g := db.NewProductDao(sqlDB)
+	ctx := context.Background()
+
+	p, err := g.GetProductBySlug(ctx, "chicken-waffle-2")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("2"), p.Id, "slugs are numbered in the order products were added")
+	created, err := g.CreateProduct(ctx, db.Product{Name: "Lemon Pie", Price: 5, Category: "Pie"})
+	assert.NoError(t, err)
+	assert.Equal(t, "lemon-pie-2", created.Slug, "slugs don't take other products' IDs")
+	created, err = g.CreateProduct(ctx, db.Product{Id: "x1", Name: "☕", Price: 3, Category: "Drinks"})
+	assert.NoError(t, err)
+	assert.Equal(t, "product-x1", created.Slug)
+	_, err = g.GetProductBySlug(ctx, "lemon-meringue-pie")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	created, err = g.CreateProduct(ctx, db.Product{Id: "x2", Name: "Search", Price: 3, Category: "Drinks"})
+	assert.NoError(t, err)
+	assert.Equal(t, "search-2", created.Slug, "slugs aren't paths of the API")
+	var idErr *db.ProductIDError
+	_, err = g.CreateProduct(ctx, db.Product{Id: "search", Name: "Finder", Price: 3, Category: "Drinks"})
+	assert.ErrorAs(t, err, &idErr, "IDs aren't paths of the API")
+	_, err = g.CreateProduct(ctx, db.Product{Id: "chicken-waffle", Name: "Waffle", Price: 3, Category: "Waffles"})
+	assert.ErrorAs(t, err, &idErr, "IDs aren't other products' slugs")
+	_, err = g.CreateProduct(ctx, db.Product{Id: "4", Name: "6", Price: 3, Category: "Numbers"})
+	assert.NoError(t, err)
+	created, err = g.CreateProduct(ctx, db.Product{Name: "Pecan Pie", Price: 5, Category: "Pie"})
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("5"), created.Id)
+	created, err = g.CreateProduct(ctx, db.Product{Name: "Apple Pie", Price: 5, Category: "Pie"})
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("7"), created.Id, "numbers taken as slugs are skipped")
+
+	renamed, err := g.UpdateProduct(ctx, db.Product{Id: "lemon-pie", Name: "Lemon Meringue Pie", Price: 5, Category: "Pie"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "lemon-meringue-pie", renamed.Slug, "renaming makes the slug again")
+	p, err = g.GetProductBySlug(ctx, "lemon-meringue")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("lemon-pie"), p.Id, "old slugs still lead to the product")
+	created, err = g.CreateProduct(ctx, db.Product{Name: "Lemon Meringue", Price: 5, Category: "Pie"})
+	assert.NoError(t, err)
+	assert.Equal(t, "lemon-meringue-2", created.Slug, "old slugs aren't given to other products")
+	_, err = g.CreateProduct(ctx, db.Product{Id: "lemon-meringue", Name: "Tart", Price: 5, Category: "Pie"})
+	assert.ErrorAs(t, err, &idErr, "nor taken as IDs")
+	renamed, err = g.UpdateProduct(ctx, db.Product{Id: "lemon-pie", Name: "Lemon Meringue", Price: 5, Category: "Pie"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "lemon-meringue", renamed.Slug, "going back to an old name takes its slug back")
+	p, err = g.GetProductBySlug(ctx, "lemon-meringue-pie")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("lemon-pie"), p.Id)
+	renamed, err = g.UpdateProduct(ctx, db.Product{Id: "2", Name: "Chicken Waffle", Price: 13, Category: "Waffles"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "chicken-waffle-2", renamed.Slug, "names that make the same slug keep it")
+}
+
+func TestGeneralProduct_CatalogChangedAt(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	ctx := context.Background()
+	g := db.NewProductDao(sqlDB)
+	created, err := g.CreateProduct(ctx, db.Product{Name: "Waffle", Price: 6.5, Category: "Waffle"})
+	assert.NoError(t, err)
+	long := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	changedAt := func() time.Time {
+		t.Helper()
+		changed, err := g.CatalogChangedAt(ctx)
+		assert.NoError(t, err)
+		return changed
+	}
+	for _, change := range []func() error{
+		func() error {
+			_, err := g.UpdateProduct(ctx, db.Product{Id: created.Id, Name: "Waffle", Price: 7, Category: "Waffle"}, 0)
+			return err
+		},
+		func() error {
+			_, err := db.NewStockDao(sqlDB).SetStock(ctx, db.Stock{ProductID: created.Id, OnHand: 3})
+			return err
+		},
+	} {
+		_, err := sqlDB.Exec("UPDATE catalog_version SET changed_at = ?", long)
+		assert.NoError(t, err)
+		assert.Equal(t, long, changedAt())
+		assert.NoError(t, change())
+		assert.True(t, changedAt().After(long), "products and their stock date the catalog")
+	}
+}
+
+func TestValidID(t *testing.T) {
+	for _, id := range []string{"7", "chicken-waffle", "SKU_12.b~2"} {
+		assert.True(t, db.ValidID(id), id)
+	}
+	for _, id := range []string{"", "a b", "crème", "7/8", strings.Repeat("7", 65)} {
+		assert.False(t, db.ValidID(id), id)
+	}
+}
+
+func TestGeneralProduct_FindProducts(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	err := CreateProducts(t, []db.Product{
+		{Id: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle"},
+		{Id: "2", Name: "Vanilla Bean Crème Brûlée", Price: 7, Category: "Crème Brûlée"},
+		{Id: "3", Name: "Macaron Mix of Five", Price: 8, Category: "Macaron"},
+		{Id: "4", Name: "Classic Tiramisu", Price: 5.5, Category: "Tiramisu"},
+		{Id: "5", Name: "Chocolate Waffle", Price: 7, Category: "Waffle"},
+		{Id: "6", Name: "100% Cocoa_Waffle", Price: 9, Category: "Waffle"},
+	}, sqlDB)
+	assert.NoError(t, err)
+	now := time.Now().UTC()
+	_, err = sqlDB.Exec(`INSERT INTO stock (product_id, on_hand, updated_at) VALUES ('3', 0, ?), ('4', 2, ?), ('5', 0, ?);
+		INSERT INTO orders (id, items, status, created_at) VALUES
+			('a', CAST('[{"product_id":"4","quantity":3},{"product_id":"2","quantity":1}]' AS BLOB), 'accepted', ?),
+			('b', CAST('[{"product_id":"2","quantity":1}]' AS BLOB), 'ready', ?),
+			('c', CAST('[{"product_id":"1","quantity":9}]' AS BLOB), 'cancelled', ?),
+			('d', CAST('[{"product_id":"1","quantity":9}]' AS BLOB), 'accepted', ?)`,
+		now, now, now, now, now, now, now.Add(-db.PopularityWindow-time.Hour))
+	assert.NoError(t, err)
+	// Puts the products in categories, Waffle first, and hides Macaron.
+	assert.NoError(t, db.Migrate(context.Background(), sqlDB))
+	_, err = sqlDB.Exec("UPDATE categories SET visible = FALSE WHERE slug = 'macaron'")
+	assert.NoError(t, err)
+	g := db.NewProductDao(sqlDB)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		filter db.ProductFilter
+		want   []db.ID
+	}{
+		{"by menu", db.ProductFilter{}, []db.ID{"6", "5", "1", "2", "4"}},
+		{"by name", db.ProductFilter{Sort: db.SortByName}, []db.ID{"6", "5", "4", "2", "1"}},
+		{"by name descending", db.ProductFilter{Sort: db.SortByNameDesc}, []db.ID{"1", "2", "4", "5", "6"}},
+		{"by price, ties by ID", db.ProductFilter{Sort: db.SortByPrice}, []db.ID{"4", "1", "2", "5", "6"}},
+		{"by price descending", db.ProductFilter{Sort: db.SortByPriceDesc}, []db.ID{"6", "5", "2", "1", "4"}},
+		{"by units sold in recent orders", db.ProductFilter{Sort: db.SortByPopularity}, []db.ID{"4", "2", "6", "5", "1"}},
+		{"category", db.ProductFilter{Category: "waffle"}, []db.ID{"6", "5", "1"}},
+		{"hidden category", db.ProductFilter{Category: "macaron"}, nil},
+		{"price range", db.ProductFilter{Sort: db.SortByName, MinPrice: 6.5, MaxPrice: 7}, []db.ID{"5", "2", "1"}},
+		{"name, ignoring case", db.ProductFilter{Name: "WAFFLE"}, []db.ID{"6", "5", "1"}},
+		{"name with wildcards", db.ProductFilter{Name: "% Cocoa_"}, []db.ID{"6"}},
+		{"available", db.ProductFilter{Available: true, Category: "waffle"}, []db.ID{"6", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, next, err := g.FindProducts(ctx, tt.filter)
+			assert.NoError(t, err)
+			assert.Empty(t, next)
+			assert.Equal(t, tt.want, productIDs(products))
+		})
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		for _, sort := range []string{db.SortByMenu, db.SortByName, db.SortByPriceDesc, db.SortByPopularity} {
+			all, _, err := g.FindProducts(ctx, db.ProductFilter{Sort: sort})
+			assert.NoError(t, err)
+			var paged []db.ID
+			filter := db.ProductFilter{Sort: sort, Limit: 4}
+			for {
+				products, next, err := g.FindProducts(ctx, filter)
+				if !assert.NoError(t, err) {
+					return
+				}
+				paged = append(paged, productIDs(products)...)
+				if next == "" {
+					break
+				}
+				filter.Cursor = next
+			}
+			assert.Equal(t, productIDs(all), paged, sort)
+		}
+	})
+
+	t.Run("cursors", func(t *testing.T) {
+		_, next, err := g.FindProducts(ctx, db.ProductFilter{Limit: 1})
+		assert.NoError(t, err)
+		var invalid *db.InvalidCursorError
+		_, _, err = g.FindProducts(ctx, db.ProductFilter{Sort: db.SortByPrice, Limit: 1, Cursor: next})
+		assert.ErrorAs(t, err, &invalid, "cursors are for one sort order")
+		_, _, err = g.FindProducts(ctx, db.ProductFilter{Limit: 1, Cursor: "not-a-cursor"})
+		assert.ErrorAs(t, err, &invalid)
+	})
+}
+
+func productIDs(products []db.Product) []db.ID {
+	var ids []db.ID
+	for _, p := range products {
+		ids = append(ids, p.Id)
+	}
+	return ids
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type QuoteDaoImpl struct {
+	db *sql.DB
+}
+
+var _ QuoteDao = &QuoteDaoImpl{}
+
+func NewQuoteDao(db *sql.DB) QuoteDao {
+	return &QuoteDaoImpl{db: db}
+}
+
+// CreateQuote implements QuoteDao.
+func (q *QuoteDaoImpl) CreateQuote(ctx context.Context, quote Quote) error {
+	validate := validator.New()
+	if err := validate.Struct(quote); err != nil {
+		return err
+	}
+	items, err := json.Marshal(quote.Items)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO quotes (id, items, coupon_code, discounts, expires_at) VALUES (?, ?, ?, ?, ?)"
+	if _, err := q.db.ExecContext(ctx, query, quote.ID, items, quote.CouponCode, quote.Discounts, quote.ExpiresAt.UTC()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetQuote implements QuoteDao. Expired quotes are still returned; callers
+// decide whether ExpiresAt has passed.
+func (q *QuoteDaoImpl) GetQuote(ctx context.Context, id ID) (Quote, error) {
+	row := q.db.QueryRowContext(ctx, "SELECT id, items, coupon_code, discounts, expires_at, used_by FROM quotes WHERE id = ?", id)
+	var quote Quote
+	var itemsJSON []byte
+	var usedBy sql.NullString
+	if err := row.Scan(&quote.ID, &itemsJSON, &quote.CouponCode, &quote.Discounts, &quote.ExpiresAt, &usedBy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Quote{}, ErrNotFound(err)
+		}
+		return Quote{}, err
+	}
+	if err := json.Unmarshal(itemsJSON, &quote.Items); err != nil {
+		return Quote{}, err
+	}
+	quote.UsedBy = usedBy.String
+	return quote, nil
+}
+
+// useQuote marks the quote an order is priced from as used by it. Only a
+// quote no order has used yet is marked, so two orders can't both use one.
+func useQuote(ctx context.Context, tx *sql.Tx, order Order) error {
+	if order.QuoteID == "" {
+		return nil
+	}
+	res, err := tx.ExecContext(ctx, "UPDATE quotes SET used_by = ? WHERE id = ? AND used_by IS NULL", order.ID, order.QuoteID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return &QuoteUsedError{ID: order.QuoteID}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"backend-challenge/internal/db"
+)
+
+func TestQuoteDao(t *testing.T) {
+	sqlDB := setupTestDB(t)
+	quoteDao := db.NewQuoteDao(sqlDB)
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	quote := db.Quote{
+		ID:         "quote-1",
+		Items:      []db.Item{{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5}},
+		CouponCode: "HAPPYHRS",
+		Discounts:  1.3,
+		ExpiresAt:  expiresAt,
+	}
+
+	assert.NoError(t, quoteDao.CreateQuote(context.Background(), quote))
+	assert.Error(t, quoteDao.CreateQuote(context.Background(), db.Quote{ID: "quote-2", ExpiresAt: expiresAt}), "quote without items")
+
+	got, err := quoteDao.GetQuote(context.Background(), "quote-1")
+	assert.NoError(t, err)
+	assert.Equal(t, quote, got)
+
+	_, err = quoteDao.GetQuote(context.Background(), "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A quote places one order only.
+	orderDao := db.NewOrderDao(sqlDB)
+	order := db.Order{ID: "quoted-1", Items: quote.Items, CouponCode: quote.CouponCode, Discounts: quote.Discounts, QuoteID: "quote-1"}
+	assert.NoError(t, orderDao.CreateOrder(context.Background(), order))
+	got, err = quoteDao.GetQuote(context.Background(), "quote-1")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ID("quoted-1"), got.UsedBy)
+	order.ID = "quoted-2"
+	var used *db.QuoteUsedError
+	assert.ErrorAs(t, orderDao.CreateOrder(context.Background(), order), &used)
+	_, err = orderDao.GetOrder(context.Background(), "quoted-2")
+	assert.ErrorIs(t, err, sql.ErrNoRows, "the second order isn't stored")
+}
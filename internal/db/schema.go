@@ -0,0 +1,321 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// migrations are applied in order and tracked with SQLite's user_version pragma,
+// so each entry runs exactly once per database. Never edit an entry that has
+// shipped; append a new one instead.
+var migrations = []string{
+	// 1: tables as they exist in the checked-in db.sqlite3.
+	`CREATE TABLE IF NOT EXISTS orders (
+		id TEXT PRIMARY KEY,
+		items BLOB
+	);
+	CREATE TABLE IF NOT EXISTS products (
+		id TEXT PRIMARY KEY,
+		name TEXT,
+		price REAL,
+		category TEXT,
+		image TEXT
+	);`,
+	// 2: coupon and discount on orders, priced quotes.
+	`ALTER TABLE orders ADD COLUMN coupon_code TEXT NOT NULL DEFAULT '';
+	ALTER TABLE orders ADD COLUMN discounts REAL NOT NULL DEFAULT 0;
+	CREATE TABLE IF NOT EXISTS quotes (
+		id TEXT PRIMARY KEY,
+		items BLOB NOT NULL,
+		coupon_code TEXT NOT NULL DEFAULT '',
+		discounts REAL NOT NULL DEFAULT 0,
+		expires_at DATETIME NOT NULL
+	);`,
+	// 3: server-side carts.
+	`CREATE TABLE IF NOT EXISTS carts (
+		id TEXT PRIMARY KEY,
+		coupon_code TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS cart_items (
+		cart_id TEXT NOT NULL REFERENCES carts(id),
+		product_id TEXT NOT NULL,
+		quantity INTEGER NOT NULL,
+		PRIMARY KEY (cart_id, product_id)
+	);
+	CREATE INDEX IF NOT EXISTS carts_updated_at ON carts(updated_at);`,
+	// 4: status of orders placed asynchronously.
+	`ALTER TABLE orders ADD COLUMN status TEXT NOT NULL DEFAULT 'accepted';
+	ALTER TABLE orders ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';`,
+	// 5: refunds, and the coupon redemptions they can give back.
+	`CREATE TABLE IF NOT EXISTS refunds (
+		id TEXT PRIMARY KEY,
+		order_id TEXT NOT NULL REFERENCES orders(id),
+		amount REAL NOT NULL,
+		lines BLOB,
+		reason TEXT NOT NULL,
+		operator TEXT NOT NULL,
+		coupon_released INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS refunds_order_id ON refunds(order_id);
+	CREATE INDEX IF NOT EXISTS refunds_created_at ON refunds(created_at);
+	CREATE TABLE IF NOT EXISTS coupon_redemptions (
+		order_id TEXT PRIMARY KEY REFERENCES orders(id),
+		coupon_code TEXT NOT NULL,
+		redeemed_at DATETIME NOT NULL,
+		released_at DATETIME
+	);
+	INSERT INTO coupon_redemptions (order_id, coupon_code, redeemed_at)
+		SELECT id, coupon_code, CURRENT_TIMESTAMP FROM orders WHERE coupon_code != '' AND status = 'accepted';`,
+	// 6: order event outbox and webhook deliveries.
+	`CREATE TABLE IF NOT EXISTS outbox (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		order_id TEXT NOT NULL,
+		payload BLOB NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL DEFAULT '[]',
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		webhook_id TEXT NOT NULL REFERENCES webhooks(id),
+		event_id TEXT NOT NULL REFERENCES outbox(id),
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		last_error TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook ON webhook_deliveries(webhook_id, updated_at);`,
+	// 7: product modifier groups and their options.
+	`CREATE TABLE IF NOT EXISTS modifier_groups (
+		id TEXT PRIMARY KEY,
+		product_id TEXT NOT NULL REFERENCES products(id),
+		name TEXT NOT NULL,
+		required INTEGER NOT NULL DEFAULT 0,
+		min_select INTEGER NOT NULL DEFAULT 0,
+		max_select INTEGER NOT NULL DEFAULT 0,
+		position INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE IF NOT EXISTS modifiers (
+		id TEXT PRIMARY KEY,
+		group_id TEXT NOT NULL REFERENCES modifier_groups(id),
+		name TEXT NOT NULL,
+		price_delta REAL NOT NULL DEFAULT 0,
+		position INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS modifier_groups_product ON modifier_groups(product_id, position);
+	CREATE INDEX IF NOT EXISTS modifiers_group ON modifiers(group_id, position);`,
+	// 8: how orders are fulfilled, as JSON; NULL for orders placed without.
+	`ALTER TABLE orders ADD COLUMN fulfilment TEXT;`,
+	// 9: delivery fees, and delivery zones managed in the database.
+	`ALTER TABLE orders ADD COLUMN delivery_fee REAL NOT NULL DEFAULT 0;
+	CREATE TABLE IF NOT EXISTS delivery_zones (
+		name TEXT PRIMARY KEY,
+		definition TEXT NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0
+	);`,
+	// 10: stock levels, and the stock each order has taken so it can be given back.
+	`CREATE TABLE IF NOT EXISTS stock (
+		product_id TEXT PRIMARY KEY REFERENCES products(id),
+		on_hand INTEGER NOT NULL CHECK (on_hand >= 0),
+		low_threshold INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS stock_reservations (
+		order_id TEXT NOT NULL REFERENCES orders(id),
+		product_id TEXT NOT NULL,
+		quantity INTEGER NOT NULL,
+		PRIMARY KEY (order_id, product_id)
+	);`,
+	// 11: ingredients, the recipes that use them and what each order used.
+	// Recipe lines of a product itself have an empty modifier_id.
+	`CREATE TABLE IF NOT EXISTS ingredients (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		unit TEXT NOT NULL,
+		on_hand REAL NOT NULL CHECK (on_hand >= 0),
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS recipes (
+		product_id TEXT NOT NULL REFERENCES products(id),
+		modifier_id TEXT NOT NULL DEFAULT '',
+		ingredient_id TEXT NOT NULL REFERENCES ingredients(id),
+		quantity REAL NOT NULL,
+		PRIMARY KEY (product_id, modifier_id, ingredient_id)
+	);
+	CREATE TABLE IF NOT EXISTS ingredient_usage (
+		order_id TEXT NOT NULL REFERENCES orders(id),
+		ingredient_id TEXT NOT NULL REFERENCES ingredients(id),
+		quantity REAL NOT NULL,
+		used_at DATETIME NOT NULL,
+		released_at DATETIME,
+		PRIMARY KEY (order_id, ingredient_id)
+	);
+	CREATE INDEX IF NOT EXISTS ingredient_usage_used_at ON ingredient_usage(used_at);`,
+	// 12: scheduled orders, and the pickup slot each one takes a place in.
+	`ALTER TABLE orders ADD COLUMN scheduled_for DATETIME;
+	ALTER TABLE orders ADD COLUMN released_at DATETIME;
+	CREATE INDEX IF NOT EXISTS orders_unreleased ON orders(scheduled_for) WHERE released_at IS NULL;
+	CREATE TABLE IF NOT EXISTS slot_reservations (
+		order_id TEXT PRIMARY KEY REFERENCES orders(id),
+		slot_start DATETIME NOT NULL,
+		items INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS slot_reservations_start ON slot_reservations(slot_start);`,
+	// 13: when orders were placed, taken from their order.created event for
+	// existing ones, and the order lines the kitchen has bumped.
+	`ALTER TABLE orders ADD COLUMN created_at DATETIME;
+	UPDATE orders SET created_at = (SELECT MIN(created_at) FROM outbox
+		WHERE outbox.order_id = orders.id AND outbox.type = 'order.created');
+	CREATE INDEX IF NOT EXISTS orders_open ON orders(created_at) WHERE status = 'accepted';
+	CREATE TABLE IF NOT EXISTS line_bumps (
+		order_id TEXT NOT NULL REFERENCES orders(id),
+		line INTEGER NOT NULL,
+		bumped_at DATETIME NOT NULL,
+		PRIMARY KEY (order_id, line)
+	);`,
+	// 14: product pictures, one per size, with the published pictures of the
+	// original menu.
+	`CREATE TABLE IF NOT EXISTS product_images (
+		product_id TEXT NOT NULL REFERENCES products(id),
+		variant TEXT NOT NULL CHECK (variant IN ('thumbnail', 'mobile', 'tablet', 'desktop')),
+		path TEXT NOT NULL,
+		PRIMARY KEY (product_id, variant)
+	);
+	WITH menu(name, slug) AS (VALUES ('Waffle with Berries', 'waffle'),
+		('Vanilla Bean Crème Brûlée', 'creme-brulee'), ('Macaron Mix of Five', 'macaron'),
+		('Classic Tiramisu', 'tiramisu'), ('Pistachio Baklava', 'baklava'),
+		('Lemon Meringue Pie', 'meringue'), ('Red Velvet Cake', 'cake'),
+		('Salted Caramel Brownie', 'brownie'), ('Vanilla Panna Cotta', 'panna-cotta')),
+	variants(variant) AS (VALUES ('thumbnail'), ('mobile'), ('tablet'), ('desktop'))
+	INSERT OR IGNORE INTO product_images (product_id, variant, path)
+	SELECT products.id, variants.variant, 'image-' || menu.slug || '-' || variants.variant || '.jpg'
+	FROM products JOIN menu ON menu.name = products.name CROSS JOIN variants;`,
+	// 15: product versions for optimistic concurrency, and archiving.
+	`ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+	ALTER TABLE products ADD COLUMN archived_at DATETIME;`,
+	// 16: product descriptions and tags, a JSON array, for search.
+	`ALTER TABLE products ADD COLUMN description TEXT NOT NULL DEFAULT '';
+	ALTER TABLE products ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';`,
+	// 17: categories. Products are put in them by linkCategories, which
+	// merges category names that only differ in spelling.
+	`CREATE TABLE IF NOT EXISTS categories (
+		id INTEGER PRIMARY KEY,
+		slug TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		parent_id INTEGER REFERENCES categories(id),
+		visible INTEGER NOT NULL DEFAULT 1
+	);
+	ALTER TABLE products ADD COLUMN category_id INTEGER REFERENCES categories(id);
+	CREATE INDEX IF NOT EXISTS products_category ON products(category_id);`,
+	// 18: product slugs, made from their names by slugProducts.
+	`ALTER TABLE products ADD COLUMN slug TEXT;
+	CREATE UNIQUE INDEX IF NOT EXISTS products_slug ON products(slug);`,
+	// 19: a counter bumped by every change to the catalog, which tells
+	// catalog caches when to reload.
+	`CREATE TABLE IF NOT EXISTS catalog_version (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version INTEGER NOT NULL
+	);
+	INSERT OR IGNORE INTO catalog_version (id, version) VALUES (1, 0);` +
+		catalogTriggers("products", "modifier_groups", "modifiers", "product_images", "categories"),
+	// 20: the order each quote was used by, so it is only used once.
+	`ALTER TABLE quotes ADD COLUMN used_by TEXT;`,
+	// 21: the modifiers, a JSON array of their IDs, and note of cart lines.
+	`ALTER TABLE cart_items ADD COLUMN modifiers TEXT NOT NULL DEFAULT '[]';
+	ALTER TABLE cart_items ADD COLUMN note TEXT NOT NULL DEFAULT '';`,
+	// 22: when the catalog, or the stock and ingredients products' availability
+	// follows, last changed, which dates product reads.
+	`ALTER TABLE catalog_version ADD COLUMN changed_at DATETIME;
+	UPDATE catalog_version SET changed_at = CURRENT_TIMESTAMP;` +
+		changeTriggers(catalogTables, "version = version + 1, changed_at = CURRENT_TIMESTAMP") +
+		changeTriggers([]string{"stock", "ingredients", "recipes"}, "changed_at = CURRENT_TIMESTAMP"),
+	// 23: the slugs products had before they were renamed, which still lead
+	// to them.
+	`CREATE TABLE IF NOT EXISTS product_slugs (
+		slug TEXT PRIMARY KEY,
+		product_id TEXT NOT NULL REFERENCES products(id)
+	);`,
+}
+
+// catalogTriggers makes the triggers that bump the catalog version on every
+// insert, update and delete on tables.
+func catalogTriggers(tables ...string) string {
+	var triggers strings.Builder
+	for _, table := range tables {
+		for _, event := range []string{"insert", "update", "delete"} {
+			fmt.Fprintf(&triggers, `
+	CREATE TRIGGER IF NOT EXISTS %[1]s_catalog_%[2]s AFTER %[3]s ON %[1]s BEGIN
+		UPDATE catalog_version SET version = version + 1;
+	END;`, table, event, strings.ToUpper(event))
+		}
+	}
+	return triggers.String()
+}
+
+// catalogTables are the tables whose changes bump the catalog version.
+var catalogTables = []string{"products", "modifier_groups", "modifiers", "product_images", "categories"}
+
+// changeTriggers makes the triggers that set the catalog_version row on every
+// insert, update and delete on tables, replacing any of the same name.
+func changeTriggers(tables []string, set string) string {
+	var triggers strings.Builder
+	for _, table := range tables {
+		for _, event := range []string{"insert", "update", "delete"} {
+			fmt.Fprintf(&triggers, `
+	DROP TRIGGER IF EXISTS %[1]s_catalog_%[2]s;
+	CREATE TRIGGER %[1]s_catalog_%[2]s AFTER %[3]s ON %[1]s BEGIN
+		UPDATE catalog_version SET %[4]s;
+	END;`, table, event, strings.ToUpper(event), set)
+		}
+	}
+	return triggers.String()
+}
+
+// Migrate brings the schema of db up to date.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// PRAGMA doesn't take bind parameters.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	// Products written before categories, or by hand, are put in theirs.
+	if err := linkCategories(ctx, db); err != nil {
+		return fmt.Errorf("linking categories: %w", err)
+	}
+	if err := slugProducts(ctx, db); err != nil {
+		return fmt.Errorf("making product slugs: %w", err)
+	}
+	// The search index depends on how the binary was built, so it is set up
+	// on every start rather than by a migration.
+	return migrateSearch(ctx, db)
+}
@@ -0,0 +1,243 @@
+//go:build fts5
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// searchSchema is the full-text index over the searchable columns of
+// products, the triggers that keep it in step with them, and the table of its
+// terms misspellings are corrected against. The index reads its text from
+// products, and is rebuilt in case products changed while the triggers were
+// missing.
+const searchSchema = `CREATE VIRTUAL TABLE IF NOT EXISTS products_fts USING fts5(
+		name, category, description, tags,
+		content = 'products', tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3');
+	CREATE VIRTUAL TABLE IF NOT EXISTS products_fts_terms USING fts5vocab(products_fts, 'row');
+	CREATE TRIGGER IF NOT EXISTS products_fts_insert AFTER INSERT ON products BEGIN
+		INSERT INTO products_fts (rowid, name, category, description, tags)
+		VALUES (new.rowid, new.name, new.category, new.description, new.tags);
+	END;
+	CREATE TRIGGER IF NOT EXISTS products_fts_delete AFTER DELETE ON products BEGIN
+		INSERT INTO products_fts (products_fts, rowid, name, category, description, tags)
+		VALUES ('delete', old.rowid, old.name, old.category, old.description, old.tags);
+	END;
+	CREATE TRIGGER IF NOT EXISTS products_fts_update AFTER UPDATE OF name, category, description, tags ON products BEGIN
+		INSERT INTO products_fts (products_fts, rowid, name, category, description, tags)
+		VALUES ('delete', old.rowid, old.name, old.category, old.description, old.tags);
+		INSERT INTO products_fts (rowid, name, category, description, tags)
+		VALUES (new.rowid, new.name, new.category, new.description, new.tags);
+	END;
+	INSERT INTO products_fts (products_fts) VALUES ('rebuild');`
+
+// searchRank is the BM25 rank of a hit, weighting matches in the name above
+// the category, tags and description, in that order. Lower ranks are better.
+const searchRank = "bm25(products_fts, 10.0, 4.0, 1.0, 2.0)"
+
+// maxSearchWords caps the words of a query that are searched for.
+const maxSearchWords = 8
+
+// migrateSearch sets up the search index.
+func migrateSearch(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, searchSchema); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SearchProducts implements ProductDao.
+func (g *ProductDaoImpl) SearchProducts(ctx context.Context, query string, limit int) (ProductSearch, error) {
+	words := searchWords(query)
+	if len(words) == 0 {
+		return ProductSearch{Query: query}, nil
+	}
+	hits, err := g.searchFor(ctx, words, limit)
+	if err != nil || len(hits) > 0 {
+		return ProductSearch{Query: query, Hits: hits}, err
+	}
+	corrected, err := g.correctWords(ctx, words)
+	if err != nil {
+		return ProductSearch{}, err
+	}
+	if slices.Equal(corrected, words) {
+		return ProductSearch{Query: query}, nil
+	}
+	hits, err = g.searchFor(ctx, corrected, limit)
+	if err != nil {
+		return ProductSearch{}, err
+	}
+	return ProductSearch{Query: strings.Join(corrected, " "), Hits: hits}, nil
+}
+
+// searchFor returns the products on sale with a word starting with each of
+// words, best match first.
+func (g *ProductDaoImpl) searchFor(ctx context.Context, words []string, limit int) ([]ProductHit, error) {
+	// Words only have letters and digits, so quoting them is enough to
+	// keep them from being read as FTS5 syntax.
+	phrases := make([]string, len(words))
+	for i, word := range words {
+		phrases[i] = `"` + word + `"*`
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := g.db.QueryContext(ctx, `SELECT `+productColumns+`,
+			snippet(products_fts, -1, '<mark>', '</mark>', '…', 12), `+searchRank+`
+		FROM products_fts JOIN products ON products.rowid = products_fts.rowid
+		WHERE products_fts MATCH ? AND products.archived_at IS NULL
+			AND IFNULL(products.category_id, 0) NOT IN (`+hiddenCategories+`)
+		ORDER BY `+searchRank+`, products.id LIMIT ?`, strings.Join(phrases, " "), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hits []ProductHit
+	for rows.Next() {
+		var hit ProductHit
+		var rank float64
+		hit.Product, err = scanProduct(rows, &hit.Snippet, &rank)
+		if err != nil {
+			return nil, err
+		}
+		hit.Score = -rank
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	products := make([]Product, len(hits))
+	ids := make([]any, len(hits))
+	for i, hit := range hits {
+		products[i] = hit.Product
+		ids[i] = hit.Id
+	}
+	products, err = g.withAllDetails(ctx, products, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Product = products[i]
+	}
+	return hits, nil
+}
+
+// correctWords replaces the words that no indexed term starts with by the
+// closest term, if one is close enough. Terms in more products win ties.
+func (g *ProductDaoImpl) correctWords(ctx context.Context, words []string) ([]string, error) {
+	type term struct {
+		text string
+		docs int
+	}
+	rows, err := g.db.QueryContext(ctx, "SELECT term, doc FROM products_fts_terms")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var terms []term
+	for rows.Next() {
+		var t term
+		if err := rows.Scan(&t.text, &t.docs); err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	corrected := slices.Clone(words)
+	for i, word := range words {
+		if slices.ContainsFunc(terms, func(t term) bool { return strings.HasPrefix(t.text, word) }) {
+			continue
+		}
+		best, bestDistance, bestDocs := word, allowedEdits(word)+1, 0
+		for _, t := range terms {
+			// Words may be the start of a term someone is still typing.
+			distance := editDistance(word, t.text)
+			if prefix := runePrefix(t.text, utf8.RuneCountInString(word)); prefix != t.text {
+				distance = min(distance, editDistance(word, prefix))
+			}
+			if distance < bestDistance || distance == bestDistance && t.docs > bestDocs {
+				best, bestDistance, bestDocs = t.text, distance, t.docs
+			}
+		}
+		corrected[i] = best
+	}
+	return corrected, nil
+}
+
+// searchWords splits a query into lower case words of letters and digits.
+func searchWords(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) > maxSearchWords {
+		words = words[:maxSearchWords]
+	}
+	return words
+}
+
+// allowedEdits is how many typos a word is corrected for: none in short
+// words, where one edit makes another word, and up to two in long ones.
+func allowedEdits(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// runePrefix returns the first n runes of s.
+func runePrefix(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// editDistance is the number of runes inserted, deleted, replaced or swapped
+// with their neighbour to turn a into b (the optimal string alignment
+// distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// Three rows of the distance matrix: two back, one back and current.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,77 @@
+//go:build fts5
+
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend-challenge/internal/db"
+)
+
+func TestGeneralProduct_SearchProducts(t *testing.T) {
+	sqlDB := setupProductTestDB(t)
+	g := db.NewProductDao(sqlDB)
+	ctx := context.Background()
+	for _, p := range []db.Product{
+		{Id: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle", Description: "Belgian waffle with fresh berries"},
+		{Id: "2", Name: "Vanilla Bean Crème Brûlée", Price: 7, Category: "Crème Brûlée", Description: "Served with fresh berries", Tags: []string{"vanilla", "gluten-free"}},
+		{Id: "3", Name: "Classic Tiramisu", Price: 5.5, Category: "Tiramisu", Description: "Coffee soaked ladyfingers"},
+		{Id: "4", Name: "Berry Pavlova", Price: 8, Category: "Pavlova", Description: "Meringue topped with berries", Tags: []string{"gluten-free"}},
+	} {
+		if _, err := g.CreateProduct(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := func(query string) (string, []db.ID) {
+		t.Helper()
+		res, err := g.SearchProducts(ctx, query, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []db.ID
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.Id)
+		}
+		return res.Query, ids
+	}
+
+	_, ids := search("berr")
+	if assert.Len(t, ids, 3, "prefixes match") {
+		assert.Equal(t, db.ID("2"), ids[2], "names rank above descriptions")
+	}
+	_, ids = search("brulee")
+	assert.Equal(t, []db.ID{"2"}, ids, "accents are ignored")
+	_, ids = search("GLUTEN free")
+	assert.ElementsMatch(t, []db.ID{"2", "4"}, ids, "tags are searched")
+	query, ids := search("wafle")
+	assert.Equal(t, "waffle", query, "misspellings are corrected")
+	assert.Equal(t, []db.ID{"1"}, ids)
+	query, ids = search("tiramsiu")
+	assert.Equal(t, "tiramisu", query, "swapped letters are one typo")
+	assert.Equal(t, []db.ID{"3"}, ids)
+	_, ids = search("xyz")
+	assert.Empty(t, ids)
+	_, ids = search(`"wa* NEAR(`)
+	assert.Equal(t, []db.ID{"1"}, ids, "query syntax is searched as words")
+
+	res, err := g.SearchProducts(ctx, "tiramisu", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "Classic <mark>Tiramisu</mark>", res.Hits[0].Snippet)
+	assert.Greater(t, res.Hits[0].Score, 0.0)
+
+	// The index follows changes to products.
+	_, err = g.UpdateProduct(ctx, db.Product{Id: "3", Name: "Affogato", Price: 5, Category: "Coffee"}, 0)
+	assert.NoError(t, err)
+	_, ids = search("tiramisu")
+	assert.Empty(t, ids)
+	_, ids = search("affogato")
+	assert.Equal(t, []db.ID{"3"}, ids)
+	_, err = g.SetProductArchived(ctx, "3", true, 0)
+	assert.NoError(t, err)
+	_, ids = search("affogato")
+	assert.Empty(t, ids, "archived products aren't found")
+}
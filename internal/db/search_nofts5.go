@@ -0,0 +1,23 @@
+//go:build !fts5
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// migrateSearch drops the triggers a build with the fts5 tag keeps the search
+// index up to date with, as they fail without it. That build rebuilds the
+// index when it next starts.
+func migrateSearch(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `DROP TRIGGER IF EXISTS products_fts_insert;
+	DROP TRIGGER IF EXISTS products_fts_delete;
+	DROP TRIGGER IF EXISTS products_fts_update;`)
+	return err
+}
+
+// SearchProducts implements ProductDao.
+func (g *ProductDaoImpl) SearchProducts(ctx context.Context, query string, limit int) (ProductSearch, error) {
+	return ProductSearch{}, ErrSearchUnavailable
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type SlotDaoImpl struct {
+	db *sql.DB
+}
+
+var _ SlotDao = &SlotDaoImpl{}
+
+func NewSlotDao(db *sql.DB) SlotDao {
+	return &SlotDaoImpl{db: db}
+}
+
+// GetSlotUsage implements SlotDao.
+func (s *SlotDaoImpl) GetSlotUsage(ctx context.Context, from, to time.Time) ([]SlotUsage, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT slot_start, COUNT(*), SUM(items) FROM slot_reservations
+		WHERE slot_start >= ? AND slot_start < ? GROUP BY slot_start ORDER BY slot_start`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var usage []SlotUsage
+	for rows.Next() {
+		var slot SlotUsage
+		if err := rows.Scan(&slot.Start, &slot.Orders, &slot.Items); err != nil {
+			return nil, err
+		}
+		usage = append(usage, slot)
+	}
+	return usage, rows.Err()
+}
+
+// reserveSlot takes a place for a scheduled order in its slot. The insert
+// only happens while the slot has room, and writers are serialized, so two
+// orders can't both take the last place.
+func reserveSlot(ctx context.Context, tx *sql.Tx, order Order) error {
+	if order.Slot == nil {
+		return nil
+	}
+	var items int32
+	for _, item := range order.Items {
+		items += item.Quantity
+	}
+	start := order.Slot.Start.UTC()
+	res, err := tx.ExecContext(ctx, `INSERT INTO slot_reservations (order_id, slot_start, items)
+		SELECT ?, ?, ? WHERE (? = 0 OR (SELECT COUNT(*) FROM slot_reservations WHERE slot_start = ?) < ?)
+		AND (? = 0 OR (SELECT COALESCE(SUM(items), 0) FROM slot_reservations WHERE slot_start = ?) + ? <= ?)`,
+		order.ID, start, items,
+		order.Slot.MaxOrders, start, order.Slot.MaxOrders,
+		order.Slot.MaxItems, start, items, order.Slot.MaxItems)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &SlotFullError{Start: start}
+	}
+	return nil
+}
+
+// releaseSlot gives back the place an order took in its slot, if any.
+func releaseSlot(ctx context.Context, tx *sql.Tx, orderID ID) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM slot_reservations WHERE order_id = ?", orderID)
+	return err
+}
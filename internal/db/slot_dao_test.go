@@ -0,0 +1,112 @@
+package db_test
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotDao_Reserve(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	orderDao := db.NewOrderDao(d)
+	slotDao := db.NewSlotDao(d)
+	start := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	slot := &db.Slot{Start: start, MaxOrders: 2, MaxItems: 5}
+	scheduled := func(id string, quantity int32) db.Order {
+		return db.Order{ID: id, ScheduledFor: start.Add(5 * time.Minute), Slot: slot,
+			Items: []db.Item{{ProductID: "1", Quantity: quantity, Name: "Waffle with Berries", UnitPrice: 6.5}}}
+	}
+
+	assert.NoError(t, orderDao.CreateOrder(ctx, scheduled("slot-1", 3)))
+	assert.Equal(t, &db.SlotFullError{Start: start}, orderDao.CreateOrder(ctx, scheduled("slot-2", 3)), "too many items")
+	assert.NoError(t, orderDao.CreateOrder(ctx, scheduled("slot-3", 2)))
+	assert.Equal(t, &db.SlotFullError{Start: start}, orderDao.CreateOrder(ctx, scheduled("slot-4", 1)), "too many orders")
+
+	order, err := orderDao.GetOrder(ctx, "slot-1")
+	assert.NoError(t, err)
+	assert.True(t, order.ScheduledFor.Equal(start.Add(5*time.Minute)))
+	assert.True(t, order.ReleasedAt.IsZero())
+
+	usage, err := slotDao.GetSlotUsage(ctx, start, start.Add(time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, usage, 1) {
+		assert.True(t, usage[0].Start.Equal(start))
+		assert.Equal(t, db.SlotUsage{Start: usage[0].Start, Orders: 2, Items: 5}, usage[0])
+	}
+
+	assert.NoError(t, orderDao.CancelOrder(ctx, "slot-3", "changed my mind", []string{db.OrderAccepted}, nil))
+	assert.NoError(t, orderDao.CreateOrder(ctx, scheduled("slot-5", 1)), "cancelling gives the place back")
+}
+
+func TestOrderDao_ReleaseScheduledOrders(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	orderDao := db.NewOrderDao(d)
+	now := time.Now().UTC()
+	for id, order := range map[string]db.Order{
+		"soon":    {ScheduledFor: now.Add(10 * time.Minute)},
+		"later":   {ScheduledFor: now.Add(2 * time.Hour)},
+		"pending": {ScheduledFor: now.Add(10 * time.Minute), Status: db.OrderPending},
+		"now":     {},
+	} {
+		order.ID = id
+		order.Items = []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", UnitPrice: 6.5}}
+		assert.NoError(t, orderDao.CreateOrder(ctx, order))
+	}
+
+	released, err := orderDao.ReleaseScheduledOrders(ctx, now.Add(20*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ID{"soon"}, released)
+	order, err := orderDao.GetOrder(ctx, "soon")
+	assert.NoError(t, err)
+	assert.False(t, order.ReleasedAt.IsZero())
+
+	released, err = orderDao.ReleaseScheduledOrders(ctx, now.Add(20*time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, released, "orders are released once")
+
+	// Asynchronous orders are released once they are accepted.
+	assert.NoError(t, orderDao.UpdateOrderStatus(ctx, "pending", db.OrderAccepted, ""))
+	released, err = orderDao.ReleaseScheduledOrders(ctx, now.Add(20*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ID{"pending"}, released)
+
+	var events int
+	assert.NoError(t, d.QueryRow("SELECT COUNT(*) FROM outbox WHERE type = ?", db.EventOrderReleased).Scan(&events))
+	assert.Equal(t, 2, events)
+}
+
+func TestSlotDao_NoOverbooking(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	orderDao := db.NewOrderDao(d)
+	start := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = orderDao.CreateOrder(ctx, db.Order{ID: fmt.Sprintf("race-%d", i), ScheduledFor: start, Slot: &db.Slot{Start: start, MaxOrders: 3},
+				Items: []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", UnitPrice: 6.5}}})
+		}()
+	}
+	wg.Wait()
+	placed := 0
+	for _, err := range errs {
+		var full *db.SlotFullError
+		if err == nil {
+			placed++
+		} else if !assert.ErrorAs(t, err, &full) {
+			t.Logf("unexpected error: %v", err)
+		}
+	}
+	assert.Equal(t, 3, placed)
+}
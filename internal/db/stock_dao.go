@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type StockDaoImpl struct {
+	db *sql.DB
+}
+
+var _ StockDao = &StockDaoImpl{}
+
+func NewStockDao(db *sql.DB) StockDao {
+	return &StockDaoImpl{db: db}
+}
+
+// GetStock implements StockDao.
+func (s *StockDaoImpl) GetStock(ctx context.Context, ids []ID) (map[ID]Stock, error) {
+	query := "SELECT product_id, on_hand, low_threshold, updated_at FROM stock"
+	args := make([]any, 0, len(ids))
+	if ids != nil {
+		if len(ids) == 0 {
+			return map[ID]Stock{}, nil
+		}
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		query += " WHERE product_id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stock := make(map[ID]Stock)
+	for rows.Next() {
+		var level Stock
+		if err := rows.Scan(&level.ProductID, &level.OnHand, &level.LowThreshold, &level.UpdatedAt); err != nil {
+			return nil, err
+		}
+		stock[level.ProductID] = level
+	}
+	return stock, rows.Err()
+}
+
+// SetStock implements StockDao.
+func (s *StockDaoImpl) SetStock(ctx context.Context, stock Stock) (Stock, error) {
+	validate := validator.New()
+	if err := validate.Struct(stock); err != nil {
+		return Stock{}, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Stock{}, err
+	}
+	defer tx.Rollback()
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = ?)", stock.ProductID).Scan(&exists); err != nil {
+		return Stock{}, err
+	}
+	if !exists {
+		return Stock{}, ErrNotFound(sql.ErrNoRows)
+	}
+	before, err := getStock(ctx, tx, stock.ProductID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Stock{}, err
+	}
+	wasLow := err == nil && before.Low()
+	stock.UpdatedAt = time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `INSERT INTO stock (product_id, on_hand, low_threshold, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (product_id) DO UPDATE SET on_hand = excluded.on_hand, low_threshold = excluded.low_threshold, updated_at = excluded.updated_at`,
+		stock.ProductID, stock.OnHand, stock.LowThreshold, stock.UpdatedAt); err != nil {
+		return Stock{}, err
+	}
+	if stock.Low() && !wasLow {
+		if err := writeEvent(ctx, tx, EventProductLowStock, "", stock); err != nil {
+			return Stock{}, err
+		}
+	}
+	return stock, tx.Commit()
+}
+
+// AdjustStock implements StockDao.
+func (s *StockDaoImpl) AdjustStock(ctx context.Context, productID ID, delta int32) (Stock, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Stock{}, err
+	}
+	defer tx.Rollback()
+	var stock Stock
+	if delta < 0 {
+		stock, err = takeStock(ctx, tx, productID, -delta, "")
+	} else {
+		stock, err = addStock(ctx, tx, productID, delta)
+	}
+	if err != nil {
+		return Stock{}, err
+	}
+	if stock.ProductID == "" {
+		return Stock{}, ErrNotFound(sql.ErrNoRows)
+	}
+	return stock, tx.Commit()
+}
+
+func getStock(ctx context.Context, q rowQuerier, productID ID) (Stock, error) {
+	var stock Stock
+	err := q.QueryRowContext(ctx, "SELECT product_id, on_hand, low_threshold, updated_at FROM stock WHERE product_id = ?", productID).
+		Scan(&stock.ProductID, &stock.OnHand, &stock.LowThreshold, &stock.UpdatedAt)
+	return stock, err
+}
+
+// takeStock removes quantity units of a product from stock. The update only
+// applies while enough is on hand, so concurrent orders can't oversell: the
+// loser of a race finds the stock already gone. A product that isn't tracked
+// returns a zero Stock. Falling to the threshold writes a low-stock event on
+// behalf of orderID.
+func takeStock(ctx context.Context, tx *sql.Tx, productID ID, quantity int32, orderID ID) (Stock, error) {
+	stock := Stock{ProductID: productID, UpdatedAt: time.Now().UTC()}
+	err := tx.QueryRowContext(ctx, `UPDATE stock SET on_hand = on_hand - ?, updated_at = ?
+		WHERE product_id = ? AND on_hand >= ? RETURNING on_hand, low_threshold`,
+		quantity, stock.UpdatedAt, productID, quantity).Scan(&stock.OnHand, &stock.LowThreshold)
+	if errors.Is(err, sql.ErrNoRows) {
+		current, err := getStock(ctx, tx, productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return Stock{}, nil
+		}
+		if err != nil {
+			return Stock{}, err
+		}
+		return Stock{}, &OutOfStockError{ProductID: productID, OnHand: current.OnHand}
+	}
+	if err != nil {
+		return Stock{}, err
+	}
+	before := stock
+	before.OnHand += quantity
+	if stock.Low() && !before.Low() {
+		if err := writeEvent(ctx, tx, EventProductLowStock, orderID, stock); err != nil {
+			return Stock{}, err
+		}
+	}
+	return stock, nil
+}
+
+// addStock puts quantity units of a product back. A product that isn't
+// tracked returns a zero Stock.
+func addStock(ctx context.Context, tx *sql.Tx, productID ID, quantity int32) (Stock, error) {
+	stock := Stock{ProductID: productID, UpdatedAt: time.Now().UTC()}
+	err := tx.QueryRowContext(ctx, `UPDATE stock SET on_hand = on_hand + ?, updated_at = ?
+		WHERE product_id = ? RETURNING on_hand, low_threshold`,
+		quantity, stock.UpdatedAt, productID).Scan(&stock.OnHand, &stock.LowThreshold)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Stock{}, nil
+	}
+	return stock, err
+}
+
+// reserveStock takes an order's items out of stock and records what was
+// taken, so that releaseStock gives back exactly that.
+func reserveStock(ctx context.Context, tx *sql.Tx, order Order) error {
+	quantities := make(map[ID]int32)
+	var products []ID
+	for _, item := range order.Items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			products = append(products, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	for _, productID := range products {
+		stock, err := takeStock(ctx, tx, productID, quantities[productID], order.ID)
+		if err != nil {
+			return err
+		}
+		if stock.ProductID == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO stock_reservations (order_id, product_id, quantity) VALUES (?, ?, ?)",
+			order.ID, productID, quantities[productID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseStock gives back the stock an order reserved. Releasing twice gives
+// nothing back the second time.
+func releaseStock(ctx context.Context, tx *sql.Tx, orderID ID) error {
+	rows, err := tx.QueryContext(ctx, "SELECT product_id, quantity FROM stock_reservations WHERE order_id = ?", orderID)
+	if err != nil {
+		return err
+	}
+	reserved := make(map[ID]int32)
+	for rows.Next() {
+		var productID ID
+		var quantity int32
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		reserved[productID] = quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for productID, quantity := range reserved {
+		if _, err := addStock(ctx, tx, productID, quantity); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM stock_reservations WHERE order_id = ?", orderID)
+	return err
+}
@@ -0,0 +1,142 @@
+package db_test
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupStockDB opens a database file, rather than the shared in-memory one, so
+// that concurrent writers wait for each other instead of failing.
+func setupStockDB(t *testing.T) *sql.DB {
+	t.Helper()
+	d, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "stock.sqlite3")+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	if err := db.Migrate(context.Background(), d); err != nil {
+		t.Fatalf("migrating schema failed: %v", err)
+	}
+	if _, err := d.Exec(`INSERT INTO products (id, name, price, category) VALUES
+		('1', 'Waffle with Berries', 6.5, 'Waffle'), ('2', 'Vanilla Bean Crème Brûlée', 7, 'Crème Brûlée')`); err != nil {
+		t.Fatalf("inserting products failed: %v", err)
+	}
+	return d
+}
+
+func stockEvents(t *testing.T, d *sql.DB) int {
+	t.Helper()
+	var n int
+	if err := d.QueryRow("SELECT COUNT(*) FROM outbox WHERE type = ?", db.EventProductLowStock).Scan(&n); err != nil {
+		t.Fatalf("counting events failed: %v", err)
+	}
+	return n
+}
+
+func TestStockDao_ReserveAndRelease(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	stockDao := db.NewStockDao(d)
+	orderDao := db.NewOrderDao(d)
+
+	_, err := stockDao.SetStock(ctx, db.Stock{ProductID: "1", OnHand: 5, LowThreshold: 2})
+	assert.NoError(t, err)
+	_, err = stockDao.SetStock(ctx, db.Stock{ProductID: "99", OnHand: 5})
+	assert.Error(t, err)
+
+	// Lines of the same product are taken together; untracked products aren't limited.
+	order := db.Order{ID: "stock-1", Items: []db.Item{
+		{ProductID: "1", Quantity: 2, Name: "Waffle with Berries", UnitPrice: 6.5},
+		{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", UnitPrice: 6.5, Note: "no cream"},
+		{ProductID: "2", Quantity: 50, Name: "Vanilla Bean Crème Brûlée", UnitPrice: 7},
+	}}
+	assert.NoError(t, orderDao.CreateOrder(ctx, order))
+	stock, err := stockDao.GetStock(ctx, []db.ID{"1", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[db.ID]int32{"1": 2}, onHand(stock))
+	assert.Equal(t, 1, stockEvents(t, d))
+
+	err = orderDao.CreateOrder(ctx, db.Order{ID: "stock-2", Items: []db.Item{{ProductID: "1", Quantity: 3, Name: "Waffle with Berries", UnitPrice: 6.5}}})
+	assert.Equal(t, &db.OutOfStockError{ProductID: "1", OnHand: 2}, err)
+	_, err = orderDao.GetOrder(ctx, "stock-2")
+	assert.Error(t, err, "an order without stock isn't stored")
+
+	assert.NoError(t, orderDao.CancelOrder(ctx, order.ID, "changed my mind", []string{db.OrderAccepted}, nil))
+	stock, err = stockDao.GetStock(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[db.ID]int32{"1": 5}, onHand(stock))
+
+	// Rejected asynchronous orders give their stock back too.
+	assert.NoError(t, orderDao.CreateOrder(ctx, db.Order{ID: "stock-3", Status: db.OrderPending, Items: []db.Item{{ProductID: "1", Quantity: 4, Name: "Waffle with Berries", UnitPrice: 6.5}}}))
+	assert.NoError(t, orderDao.UpdateOrderStatus(ctx, "stock-3", db.OrderRejected, "invalid coupon code"))
+	stock, err = stockDao.GetStock(ctx, []db.ID{"1"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[db.ID]int32{"1": 5}, onHand(stock))
+	assert.Equal(t, 2, stockEvents(t, d))
+}
+
+func TestStockDao_AdjustStock(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	stockDao := db.NewStockDao(d)
+
+	_, err := stockDao.AdjustStock(ctx, "1", 5)
+	assert.Error(t, err, "untracked products can't be adjusted")
+	_, err = stockDao.SetStock(ctx, db.Stock{ProductID: "1", OnHand: 5, LowThreshold: 3})
+	assert.NoError(t, err)
+
+	stock, err := stockDao.AdjustStock(ctx, "1", -2)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), stock.OnHand)
+	assert.True(t, stock.Low())
+	_, err = stockDao.AdjustStock(ctx, "1", -4)
+	assert.Equal(t, &db.OutOfStockError{ProductID: "1", OnHand: 3}, err)
+	stock, err = stockDao.AdjustStock(ctx, "1", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(13), stock.OnHand)
+	assert.Equal(t, 1, stockEvents(t, d))
+}
+
+func TestStockDao_NoOversell(t *testing.T) {
+	d := setupStockDB(t)
+	ctx := context.Background()
+	orderDao := db.NewOrderDao(d)
+	_, err := db.NewStockDao(d).SetStock(ctx, db.Stock{ProductID: "1", OnHand: 5})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = orderDao.CreateOrder(ctx, db.Order{ID: fmt.Sprintf("race-%d", i), Items: []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", UnitPrice: 6.5}}})
+		}()
+	}
+	wg.Wait()
+	placed := 0
+	for _, err := range errs {
+		var outOfStock *db.OutOfStockError
+		if err == nil {
+			placed++
+		} else if !assert.ErrorAs(t, err, &outOfStock) {
+			t.Logf("unexpected error: %v", err)
+		}
+	}
+	assert.Equal(t, 5, placed)
+}
+
+func onHand(stock map[db.ID]db.Stock) map[db.ID]int32 {
+	levels := make(map[db.ID]int32, len(stock))
+	for id, level := range stock {
+		levels[id] = level.OnHand
+	}
+	return levels
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type WebhookDaoImpl struct {
+	db *sql.DB
+}
+
+var _ WebhookDao = &WebhookDaoImpl{}
+
+func NewWebhookDao(db *sql.DB) WebhookDao {
+	return &WebhookDaoImpl{db: db}
+}
+
+// CreateWebhook implements WebhookDao.
+func (w *WebhookDaoImpl) CreateWebhook(ctx context.Context, webhook Webhook) error {
+	validate := validator.New()
+	if err := validate.Struct(webhook); err != nil {
+		return err
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return err
+	}
+	_, err = w.db.ExecContext(ctx, "INSERT INTO webhooks (id, url, secret, events, created_at) VALUES (?, ?, ?, ?, ?)",
+		webhook.ID, webhook.URL, webhook.Secret, string(events), webhook.CreatedAt.UTC())
+	return err
+}
+
+// GetWebhooks implements WebhookDao.
+func (w *WebhookDaoImpl) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := w.db.QueryContext(ctx, "SELECT id, url, secret, events, created_at FROM webhooks ORDER BY created_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var webhooks []Webhook
+	for rows.Next() {
+		var webhook Webhook
+		var events []byte
+		if err := rows.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(events, &webhook.Events); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook implements WebhookDao.
+func (w *WebhookDaoImpl) DeleteWebhook(ctx context.Context, id ID) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE webhook_id = ?", id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound(sql.ErrNoRows)
+	}
+	return tx.Commit()
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.updated_at,
+	o.id, o.type, o.order_id, o.created_at, o.payload, w.url, w.secret
+	FROM webhook_deliveries d
+	JOIN outbox o ON o.id = d.event_id
+	JOIN webhooks w ON w.id = d.webhook_id`
+
+// GetDueDeliveries implements WebhookDao. Deliveries are returned oldest event
+// first so each webhook sees events roughly in the order they happened.
+func (w *WebhookDaoImpl) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	return w.queryDeliveries(ctx, "SELECT "+deliveryColumns+` WHERE d.status = ? AND d.next_attempt_at <= ?
+		ORDER BY o.created_at, d.rowid LIMIT ?`, DeliveryPending, now.UTC(), limit)
+}
+
+// GetDeliveries implements WebhookDao.
+func (w *WebhookDaoImpl) GetDeliveries(ctx context.Context, webhookID ID, status string) ([]Delivery, error) {
+	return w.queryDeliveries(ctx, "SELECT "+deliveryColumns+` WHERE d.webhook_id = ? AND (? = '' OR d.status = ?)
+		ORDER BY o.created_at DESC, d.rowid DESC`, webhookID, status, status)
+}
+
+// GetDelivery implements WebhookDao.
+func (w *WebhookDaoImpl) GetDelivery(ctx context.Context, id ID) (Delivery, error) {
+	deliveries, err := w.queryDeliveries(ctx, "SELECT "+deliveryColumns+" WHERE d.id = ?", id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if len(deliveries) == 0 {
+		return Delivery{}, ErrNotFound(sql.ErrNoRows)
+	}
+	return deliveries[0], nil
+}
+
+func (w *WebhookDaoImpl) queryDeliveries(ctx context.Context, query string, args ...any) ([]Delivery, error) {
+	rows, err := w.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deliveries []Delivery
+	for rows.Next() {
+		var d Delivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastError, &d.UpdatedAt,
+			&d.Event.ID, &d.Event.Type, &d.Event.OrderID, &d.Event.CreatedAt, &payload, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		d.Event.Data = payload
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery implements WebhookDao.
+func (w *WebhookDaoImpl) UpdateDelivery(ctx context.Context, d Delivery) error {
+	return w.updateDelivery(ctx, `UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?`, d.Status, d.Attempts, d.NextAttemptAt.UTC(), d.LastError, time.Now().UTC(), d.ID)
+}
+
+// ReplayDelivery implements WebhookDao.
+func (w *WebhookDaoImpl) ReplayDelivery(ctx context.Context, id ID) error {
+	now := time.Now().UTC()
+	return w.updateDelivery(ctx, `UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?, last_error = '', updated_at = ?
+		WHERE id = ?`, DeliveryPending, now, now, id)
+}
+
+func (w *WebhookDaoImpl) updateDelivery(ctx context.Context, query string, args ...any) error {
+	res, err := w.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound(sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package db_test
+
+import (
+	"backend-challenge/internal/db"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDao_OutboxDeliveries(t *testing.T) {
+	sqlDB := setupTestDB(t)
+	orderDao := db.NewOrderDao(sqlDB)
+	webhookDao := db.NewWebhookDao(sqlDB)
+	ctx := context.Background()
+
+	all := db.Webhook{ID: "hook-all", URL: "http://localhost/all", Secret: "s3cret", CreatedAt: time.Now()}
+	cancelled := db.Webhook{ID: "hook-cancelled", URL: "http://localhost/cancelled", Secret: "s3cret", Events: []string{db.EventOrderCancelled}, CreatedAt: time.Now()}
+	assert.NoError(t, webhookDao.CreateWebhook(ctx, all))
+	assert.NoError(t, webhookDao.CreateWebhook(ctx, cancelled))
+	defer webhookDao.DeleteWebhook(ctx, all.ID)
+	defer webhookDao.DeleteWebhook(ctx, cancelled.ID)
+	assert.Error(t, webhookDao.CreateWebhook(ctx, db.Webhook{ID: "hook-bad", URL: "http://localhost", Secret: "s", Events: []string{"order.eaten"}}))
+
+	order := db.Order{ID: "order-events", Items: []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5}}}
+	assert.NoError(t, orderDao.CreateOrder(ctx, order))
+	assert.NoError(t, orderDao.CancelOrder(ctx, order.ID, "changed my mind", []string{db.OrderAccepted}, nil))
+
+	deliveries, err := webhookDao.GetDeliveries(ctx, all.ID, "")
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, db.EventOrderCancelled, deliveries[0].Event.Type)
+		assert.Equal(t, db.EventOrderCreated, deliveries[1].Event.Type)
+	}
+	deliveries, err = webhookDao.GetDeliveries(ctx, cancelled.ID, db.DeliveryPending)
+	assert.NoError(t, err)
+	if !assert.Len(t, deliveries, 1) {
+		return
+	}
+	delivery := deliveries[0]
+	assert.Equal(t, order.ID, delivery.Event.OrderID)
+	assert.Equal(t, cancelled.URL, delivery.URL)
+	var data struct {
+		Status string  `json:"status"`
+		Total  float32 `json:"total"`
+	}
+	assert.NoError(t, json.Unmarshal(delivery.Event.Data, &data))
+	assert.Equal(t, db.OrderCancelled, data.Status)
+	assert.Equal(t, float32(6.5), data.Total)
+
+	// A failed order change publishes nothing.
+	assert.Error(t, orderDao.CancelOrder(ctx, order.ID, "again", []string{db.OrderAccepted}, nil))
+	deliveries, err = webhookDao.GetDeliveries(ctx, cancelled.ID, "")
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+
+	delivery.Status = db.DeliveryDead
+	delivery.Attempts = 8
+	delivery.LastError = "503 Service Unavailable"
+	assert.NoError(t, webhookDao.UpdateDelivery(ctx, delivery))
+	due, err := webhookDao.GetDueDeliveries(ctx, time.Now(), 100)
+	assert.NoError(t, err)
+	for _, d := range due {
+		assert.NotEqual(t, delivery.ID, d.ID)
+	}
+
+	assert.NoError(t, webhookDao.ReplayDelivery(ctx, delivery.ID))
+	replayed, err := webhookDao.GetDelivery(ctx, delivery.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, db.DeliveryPending, replayed.Status)
+	assert.Equal(t, 0, replayed.Attempts)
+	due, err = webhookDao.GetDueDeliveries(ctx, time.Now().Add(time.Second), 100)
+	assert.NoError(t, err)
+	assert.Contains(t, deliveryIDs(due), delivery.ID)
+
+	assert.NoError(t, webhookDao.DeleteWebhook(ctx, cancelled.ID))
+	assert.ErrorIs(t, webhookDao.DeleteWebhook(ctx, cancelled.ID), sql.ErrNoRows)
+	_, err = webhookDao.GetDelivery(ctx, delivery.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func deliveryIDs(deliveries []db.Delivery) []db.ID {
+	ids := make([]db.ID, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
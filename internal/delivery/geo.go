@@ -0,0 +1,161 @@
+// Package delivery decides whether an address is delivered to and what the
+// delivery costs. Geometry is done in plain Go so zones can be tested
+// without a geocoder or a spatial database.
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// earthRadiusKm is the mean radius of the earth used by Distance.
+const earthRadiusKm = 6371.0
+
+// Point is a position in degrees.
+type Point struct {
+	Lat float64 `yaml:"lat" json:"lat"`
+	Lng float64 `yaml:"lng" json:"lng"`
+}
+
+// Distance returns the great-circle distance between a and b in kilometres,
+// using the haversine formula.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// Polygon is a list of linear rings. The first ring is the outline and any
+// others are holes in it.
+type Polygon [][]Point
+
+// Contains reports whether p lies inside the outline and outside every hole.
+func (poly Polygon) Contains(p Point) bool {
+	if len(poly) == 0 || !ringContains(poly[0], p) {
+		return false
+	}
+	for _, hole := range poly[1:] {
+		if ringContains(hole, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains casts a ray from p and counts the edges it crosses. Longitude
+// is treated as x and latitude as y, which is fine for delivery-sized areas
+// that don't cross the antimeridian.
+func ringContains(ring []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Area is one or more polygons, as in a GeoJSON MultiPolygon.
+type Area []Polygon
+
+// Contains reports whether any polygon of the area contains p.
+func (area Area) Contains(p Point) bool {
+	for _, poly := range area {
+		if poly.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// geoJSON covers the GeoJSON objects ParseGeoJSON accepts.
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Features    []geoJSON       `json:"features"`
+}
+
+// ParseGeoJSON reads a Polygon or MultiPolygon, either as a bare geometry,
+// a Feature or a FeatureCollection, into an Area. GeoJSON positions are
+// [longitude, latitude].
+func ParseGeoJSON(data []byte) (Area, error) {
+	var obj geoJSON
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj.area()
+}
+
+func (obj geoJSON) area() (Area, error) {
+	switch obj.Type {
+	case "Polygon":
+		var rings [][][2]float64
+		if err := json.Unmarshal(obj.Coordinates, &rings); err != nil {
+			return nil, err
+		}
+		poly, err := polygon(rings)
+		if err != nil {
+			return nil, err
+		}
+		return Area{poly}, nil
+	case "MultiPolygon":
+		var polys [][][][2]float64
+		if err := json.Unmarshal(obj.Coordinates, &polys); err != nil {
+			return nil, err
+		}
+		area := make(Area, 0, len(polys))
+		for _, rings := range polys {
+			poly, err := polygon(rings)
+			if err != nil {
+				return nil, err
+			}
+			area = append(area, poly)
+		}
+		return area, nil
+	case "Feature":
+		if obj.Geometry == nil {
+			return nil, errors.New("geojson: feature has no geometry")
+		}
+		return obj.Geometry.area()
+	case "FeatureCollection":
+		var area Area
+		for _, feature := range obj.Features {
+			a, err := feature.area()
+			if err != nil {
+				return nil, err
+			}
+			area = append(area, a...)
+		}
+		return area, nil
+	}
+	return nil, fmt.Errorf("geojson: unsupported type %q", obj.Type)
+}
+
+func polygon(rings [][][2]float64) (Polygon, error) {
+	if len(rings) == 0 {
+		return nil, errors.New("geojson: polygon has no rings")
+	}
+	poly := make(Polygon, 0, len(rings))
+	for _, ring := range rings {
+		if len(ring) < 4 {
+			return nil, errors.New("geojson: a ring needs at least four positions")
+		}
+		points := make([]Point, 0, len(ring))
+		for _, position := range ring {
+			points = append(points, Point{Lat: position[1], Lng: position[0]})
+		}
+		poly = append(poly, points)
+	}
+	return poly, nil
+}
@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	melbourne := Point{Lat: -37.8136, Lng: 144.9631}
+	sydney := Point{Lat: -33.8688, Lng: 151.2093}
+	assert.InDelta(t, 713.4, Distance(melbourne, sydney), 1)
+	assert.InDelta(t, Distance(melbourne, sydney), Distance(sydney, melbourne), 1e-9)
+	assert.Zero(t, Distance(melbourne, melbourne))
+	// A degree of latitude is about 111 km anywhere.
+	assert.InDelta(t, 111.2, Distance(Point{Lat: 0, Lng: 0}, Point{Lat: 1, Lng: 0}), 0.1)
+}
+
+// A square around Melbourne's CBD with a square hole in the middle.
+const cbdGeoJSON = `{
+	"type": "Feature",
+	"properties": {"name": "CBD"},
+	"geometry": {
+		"type": "Polygon",
+		"coordinates": [
+			[[144.95, -37.82], [144.98, -37.82], [144.98, -37.80], [144.95, -37.80], [144.95, -37.82]],
+			[[144.96, -37.812], [144.97, -37.812], [144.97, -37.808], [144.96, -37.808], [144.96, -37.812]]
+		]
+	}
+}`
+
+func TestParseGeoJSON(t *testing.T) {
+	area, err := ParseGeoJSON([]byte(cbdGeoJSON))
+	if !assert.NoError(t, err) {
+		return
+	}
+	tests := []struct {
+		name  string
+		point Point
+		want  bool
+	}{
+		{name: "inside", point: Point{Lat: -37.815, Lng: 144.955}, want: true},
+		{name: "in the hole", point: Point{Lat: -37.81, Lng: 144.965}, want: false},
+		{name: "outside", point: Point{Lat: -37.83, Lng: 144.955}, want: false},
+		{name: "latitude and longitude swapped", point: Point{Lat: 144.955, Lng: -37.815}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, area.Contains(tt.point))
+		})
+	}
+
+	multi, err := ParseGeoJSON([]byte(`{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "MultiPolygon", "coordinates": [
+			[[[0, 0], [1, 0], [1, 1], [0, 1], [0, 0]]],
+			[[[5, 5], [6, 5], [6, 6], [5, 6], [5, 5]]]
+		]}}
+	]}`))
+	assert.NoError(t, err)
+	assert.True(t, multi.Contains(Point{Lat: 5.5, Lng: 5.5}))
+	assert.False(t, multi.Contains(Point{Lat: 3, Lng: 3}))
+
+	for _, bad := range []string{
+		`{"type": "Point", "coordinates": [0, 0]}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 1], [0, 0]]]}`,
+		`{"type": "Feature"}`,
+		`not json`,
+	} {
+		_, err := ParseGeoJSON([]byte(bad))
+		assert.Error(t, err, bad)
+	}
+}
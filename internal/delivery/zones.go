@@ -0,0 +1,168 @@
+package delivery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FeeTier is one step of a zone's delivery fee. A tier applies when the
+// delivery is no further than MaxDistance kilometres and the order is worth
+// at least MinOrder; a zero leaves that condition out.
+type FeeTier struct {
+	MaxDistance float64 `yaml:"maxDistance" json:"maxDistance,omitempty"`
+	MinOrder    float32 `yaml:"minOrder" json:"minOrder,omitempty"`
+	Fee         float32 `yaml:"fee" json:"fee"`
+}
+
+// ZoneConfig is a zone as written in config.yaml or stored in the
+// delivery_zones table.
+type ZoneConfig struct {
+	Name      string   `yaml:"name" json:"name"`
+	Postcodes []string `yaml:"postcodes" json:"postcodes,omitempty"`
+	// GeoJSON is a Polygon or MultiPolygon, bare or as a Feature or
+	// FeatureCollection.
+	GeoJSON string `yaml:"geojson" json:"geojson,omitempty"`
+	// Radius in kilometres around the origin; addresses with coordinates
+	// inside it belong to the zone even if the polygon misses them.
+	Radius float64 `yaml:"radius" json:"radius,omitempty"`
+	// MinOrder is the smallest order value delivered to the zone.
+	MinOrder float32 `yaml:"minOrder" json:"minOrder,omitempty"`
+	// FreeOver waives the fee for orders worth at least this much.
+	FreeOver float32 `yaml:"freeOver" json:"freeOver,omitempty"`
+	// Fees are tried in order and the first tier that applies is charged.
+	Fees []FeeTier `yaml:"fees" json:"fees,omitempty"`
+}
+
+// Config lists the delivery zones, most specific first. Origin is where
+// deliveries start from; distances and radii are measured from it.
+type Config struct {
+	Origin Point        `yaml:"origin"`
+	Zones  []ZoneConfig `yaml:"zones"`
+}
+
+// Zone is a parsed ZoneConfig.
+type Zone struct {
+	ZoneConfig
+	area      Area
+	postcodes map[string]bool
+}
+
+// Zones decides which zone an address falls in and what delivering there costs.
+type Zones struct {
+	origin Point
+	zones  []Zone
+}
+
+// New parses the zones of cfg.
+func New(cfg Config) (*Zones, error) {
+	zones := &Zones{origin: cfg.Origin}
+	for _, zc := range cfg.Zones {
+		zone := Zone{ZoneConfig: zc, postcodes: make(map[string]bool, len(zc.Postcodes))}
+		for _, postcode := range zc.Postcodes {
+			zone.postcodes[normalizePostcode(postcode)] = true
+		}
+		if zc.GeoJSON != "" {
+			area, err := ParseGeoJSON([]byte(zc.GeoJSON))
+			if err != nil {
+				return nil, fmt.Errorf("delivery zone %s: %w", zc.Name, err)
+			}
+			zone.area = area
+		}
+		if len(zone.postcodes) == 0 && zone.area == nil && zc.Radius <= 0 {
+			return nil, fmt.Errorf("delivery zone %s: needs postcodes, geojson or a radius", zc.Name)
+		}
+		zones.zones = append(zones.zones, zone)
+	}
+	return zones, nil
+}
+
+func normalizePostcode(postcode string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(postcode), " ", ""))
+}
+
+// Destination is where an order is delivered. Point is nil when the address
+// has no coordinates, in which case only postcodes can match it.
+type Destination struct {
+	Postcode string
+	Point    *Point
+}
+
+// Quote is the zone a destination falls in and the fee for delivering there.
+// Distance is 0 when the destination has no coordinates.
+type Quote struct {
+	Zone     string
+	Distance float64
+	Fee      float32
+}
+
+// OutOfZoneError is returned for destinations no zone covers.
+type OutOfZoneError struct {
+	Postcode string
+	// Distance from the origin in kilometres, if known.
+	Distance float64
+}
+
+func (e *OutOfZoneError) Error() string {
+	if e.Distance > 0 {
+		return fmt.Sprintf("we don't deliver to postcode %s, %.1f km away", e.Postcode, e.Distance)
+	}
+	return fmt.Sprintf("we don't deliver to postcode %s", e.Postcode)
+}
+
+// MinimumOrderError is returned when an order is worth less than its zone's minimum.
+type MinimumOrderError struct {
+	Zone    string
+	Minimum float32
+}
+
+func (e *MinimumOrderError) Error() string {
+	return fmt.Sprintf("delivery to %s needs an order of at least %.2f", e.Zone, e.Minimum)
+}
+
+// Quote finds the first zone that covers dest and prices delivering an order
+// worth orderValue there. A zone covers a destination whose postcode it
+// lists, or whose coordinates are inside its area or radius. Fee tiers with a
+// MaxDistance never apply to destinations without coordinates.
+func (z *Zones) Quote(dest Destination, orderValue float32) (Quote, error) {
+	var distance float64
+	if dest.Point != nil {
+		distance = Distance(z.origin, *dest.Point)
+	}
+	for _, zone := range z.zones {
+		if !zone.covers(dest, distance) {
+			continue
+		}
+		if orderValue < zone.MinOrder {
+			return Quote{}, &MinimumOrderError{Zone: zone.Name, Minimum: zone.MinOrder}
+		}
+		quote := Quote{Zone: zone.Name, Distance: distance}
+		if zone.FreeOver > 0 && orderValue >= zone.FreeOver {
+			return quote, nil
+		}
+		if len(zone.Fees) == 0 {
+			return quote, nil
+		}
+		for _, tier := range zone.Fees {
+			if tier.MaxDistance > 0 && (dest.Point == nil || distance > tier.MaxDistance) {
+				continue
+			}
+			if orderValue < tier.MinOrder {
+				continue
+			}
+			quote.Fee = tier.Fee
+			return quote, nil
+		}
+		// Beyond the zone's furthest tier; a later, wider zone may still take it.
+	}
+	return Quote{}, &OutOfZoneError{Postcode: dest.Postcode, Distance: distance}
+}
+
+func (zone Zone) covers(dest Destination, distance float64) bool {
+	if zone.postcodes[normalizePostcode(dest.Postcode)] {
+		return true
+	}
+	if dest.Point == nil {
+		return false
+	}
+	return zone.area.Contains(*dest.Point) || (zone.Radius > 0 && distance <= zone.Radius)
+}
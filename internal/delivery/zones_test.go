@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZones_Quote(t *testing.T) {
+	origin := Point{Lat: -37.8136, Lng: 144.9631}
+	zones, err := New(Config{
+		Origin: origin,
+		Zones: []ZoneConfig{
+			{Name: "CBD", GeoJSON: cbdGeoJSON, Postcodes: []string{"3000"}, FreeOver: 50, Fees: []FeeTier{{Fee: 3}}},
+			{Name: "Inner", Radius: 8, MinOrder: 20, Fees: []FeeTier{
+				{MaxDistance: 3, Fee: 4},
+				{MaxDistance: 8, Fee: 6},
+			}},
+			{Name: "Suburbs", Postcodes: []string{"3121", "sw1a 1aa"}, MinOrder: 30, Fees: []FeeTier{
+				{MinOrder: 60, Fee: 2},
+				{Fee: 8},
+			}},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	point := func(lat, lng float64) *Point { return &Point{Lat: lat, Lng: lng} }
+	tests := []struct {
+		name       string
+		dest       Destination
+		orderValue float32
+		want       Quote
+		wantErr    error
+	}{
+		{name: "postcode", dest: Destination{Postcode: "3000"}, orderValue: 10, want: Quote{Zone: "CBD", Fee: 3}},
+		{name: "free over threshold", dest: Destination{Postcode: "3000"}, orderValue: 50, want: Quote{Zone: "CBD"}},
+		{name: "inside polygon", dest: Destination{Postcode: "3002", Point: point(-37.815, 144.955)}, orderValue: 10, want: Quote{Zone: "CBD", Fee: 3}},
+		{name: "radius fallback, near", dest: Destination{Postcode: "3002", Point: point(-37.83, 144.97)}, orderValue: 25, want: Quote{Zone: "Inner", Fee: 4}},
+		{name: "radius fallback, far", dest: Destination{Postcode: "3101", Point: point(-37.8136, 145.04)}, orderValue: 25, want: Quote{Zone: "Inner", Fee: 6}},
+		{name: "under zone minimum", dest: Destination{Postcode: "3002", Point: point(-37.83, 144.97)}, orderValue: 15, wantErr: &MinimumOrderError{Zone: "Inner", Minimum: 20}},
+		{name: "order value tier", dest: Destination{Postcode: "3121"}, orderValue: 60, want: Quote{Zone: "Suburbs", Fee: 2}},
+		{name: "catch-all tier", dest: Destination{Postcode: "3121"}, orderValue: 40, want: Quote{Zone: "Suburbs", Fee: 8}},
+		{name: "postcode spacing and case", dest: Destination{Postcode: "SW1A1AA"}, orderValue: 40, want: Quote{Zone: "Suburbs", Fee: 8}},
+		{name: "unknown postcode", dest: Destination{Postcode: "3999"}, orderValue: 40, wantErr: &OutOfZoneError{Postcode: "3999"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := zones.Quote(tt.dest, tt.orderValue)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Zone, got.Zone)
+			assert.Equal(t, tt.want.Fee, got.Fee)
+			if tt.dest.Point != nil {
+				assert.InDelta(t, Distance(origin, *tt.dest.Point), got.Distance, 1e-9)
+			}
+		})
+	}
+
+	_, err = zones.Quote(Destination{Postcode: "3977", Point: point(-38.1, 145.3)}, 40)
+	var out *OutOfZoneError
+	if assert.ErrorAs(t, err, &out) {
+		assert.Greater(t, out.Distance, 8.0)
+		assert.Contains(t, err.Error(), "km away")
+	}
+}
+
+func TestNew_RejectsBadZones(t *testing.T) {
+	_, err := New(Config{Zones: []ZoneConfig{{Name: "nowhere"}}})
+	assert.EqualError(t, err, "delivery zone nowhere: needs postcodes, geojson or a radius")
+	_, err = New(Config{Zones: []ZoneConfig{{Name: "broken", GeoJSON: `{"type": "Point"}`}}})
+	assert.ErrorContains(t, err, "delivery zone broken")
+}
@@ -13,13 +13,64 @@ package openapi
 import (
 	"context"
 	"net/http"
+	"os"
+	"time"
 )
 
+// CartAPIRouter defines the required methods for binding the api requests to a responses for the CartAPI
+// The CartAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a CartAPIServicer to perform the required actions, then write the service results to the http response.
+type CartAPIRouter interface {
+	CreateCart(http.ResponseWriter, *http.Request)
+	GetCart(http.ResponseWriter, *http.Request)
+	SetCartItem(http.ResponseWriter, *http.Request)
+	RemoveCartItem(http.ResponseWriter, *http.Request)
+	ApplyCartCoupon(http.ResponseWriter, *http.Request)
+	RemoveCartCoupon(http.ResponseWriter, *http.Request)
+	CheckoutCart(http.ResponseWriter, *http.Request)
+}
+
+// CategoryAPIRouter defines the required methods for binding the api requests to a responses for the CategoryAPI
+// The CategoryAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a CategoryAPIServicer to perform the required actions, then write the service results to the http response.
+type CategoryAPIRouter interface {
+	ListCategories(http.ResponseWriter, *http.Request)
+	ListCategoryProducts(http.ResponseWriter, *http.Request)
+}
+
+// InventoryAPIRouter defines the required methods for binding the api requests to a responses for the InventoryAPI
+// The InventoryAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a InventoryAPIServicer to perform the required actions, then write the service results to the http response.
+type InventoryAPIRouter interface {
+	ListStock(http.ResponseWriter, *http.Request)
+	SetStock(http.ResponseWriter, *http.Request)
+	AdjustStock(http.ResponseWriter, *http.Request)
+	ListIngredients(http.ResponseWriter, *http.Request)
+	ForecastIngredients(http.ResponseWriter, *http.Request)
+	SetIngredient(http.ResponseWriter, *http.Request)
+	AdjustIngredient(http.ResponseWriter, *http.Request)
+	GetRecipe(http.ResponseWriter, *http.Request)
+	SetRecipe(http.ResponseWriter, *http.Request)
+}
+
+// KitchenAPIRouter defines the required methods for binding the api requests to a responses for the KitchenAPI
+// The KitchenAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a KitchenAPIServicer to perform the required actions, then write the service results to the http response.
+type KitchenAPIRouter interface {
+	GetKitchenQueue(http.ResponseWriter, *http.Request)
+	BumpKitchenLine(http.ResponseWriter, *http.Request)
+}
+
 // OrderAPIRouter defines the required methods for binding the api requests to a responses for the OrderAPI
 // The OrderAPIRouter implementation should parse necessary information from the http request,
 // pass the data to a OrderAPIServicer to perform the required actions, then write the service results to the http response.
 type OrderAPIRouter interface {
 	PlaceOrder(http.ResponseWriter, *http.Request)
+	QuoteOrder(http.ResponseWriter, *http.Request)
+	GetOrder(http.ResponseWriter, *http.Request)
+	CancelOrder(http.ResponseWriter, *http.Request)
+	RefundOrder(http.ResponseWriter, *http.Request)
+	ListRefunds(http.ResponseWriter, *http.Request)
 }
 
 // ProductAPIRouter defines the required methods for binding the api requests to a responses for the ProductAPI
@@ -27,7 +78,80 @@ type OrderAPIRouter interface {
 // pass the data to a ProductAPIServicer to perform the required actions, then write the service results to the http response.
 type ProductAPIRouter interface {
 	ListProducts(http.ResponseWriter, *http.Request)
+	SearchProducts(http.ResponseWriter, *http.Request)
+	CreateProduct(http.ResponseWriter, *http.Request)
 	GetProduct(http.ResponseWriter, *http.Request)
+	UpdateProduct(http.ResponseWriter, *http.Request)
+	PatchProduct(http.ResponseWriter, *http.Request)
+	ArchiveProduct(http.ResponseWriter, *http.Request)
+	RestoreProduct(http.ResponseWriter, *http.Request)
+	UploadProductImage(http.ResponseWriter, *http.Request)
+}
+
+// SlotAPIRouter defines the required methods for binding the api requests to a responses for the SlotAPI
+// The SlotAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a SlotAPIServicer to perform the required actions, then write the service results to the http response.
+type SlotAPIRouter interface {
+	ListSlots(http.ResponseWriter, *http.Request)
+}
+
+// WebhookAPIRouter defines the required methods for binding the api requests to a responses for the WebhookAPI
+// The WebhookAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a WebhookAPIServicer to perform the required actions, then write the service results to the http response.
+type WebhookAPIRouter interface {
+	CreateWebhook(http.ResponseWriter, *http.Request)
+	ListWebhooks(http.ResponseWriter, *http.Request)
+	DeleteWebhook(http.ResponseWriter, *http.Request)
+	ListWebhookDeliveries(http.ResponseWriter, *http.Request)
+	ReplayWebhookDelivery(http.ResponseWriter, *http.Request)
+}
+
+// CartAPIServicer defines the api actions for the CartAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type CartAPIServicer interface {
+	CreateCart(context.Context) (ImplResponse, error)
+	GetCart(context.Context, string) (ImplResponse, error)
+	SetCartItem(context.Context, string, string, CartItemReq) (ImplResponse, error)
+	RemoveCartItem(context.Context, string, string) (ImplResponse, error)
+	ApplyCartCoupon(context.Context, string, CartCouponReq) (ImplResponse, error)
+	RemoveCartCoupon(context.Context, string) (ImplResponse, error)
+	CheckoutCart(context.Context, string, Fulfilment) (ImplResponse, error)
+}
+
+// CategoryAPIServicer defines the api actions for the CategoryAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type CategoryAPIServicer interface {
+	ListCategories(context.Context) (ImplResponse, error)
+	ListCategoryProducts(context.Context, string, bool, string, int32, string) (ImplResponse, error)
+}
+
+// InventoryAPIServicer defines the api actions for the InventoryAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type InventoryAPIServicer interface {
+	ListStock(context.Context) (ImplResponse, error)
+	SetStock(context.Context, string, StockReq) (ImplResponse, error)
+	AdjustStock(context.Context, string, StockAdjustReq) (ImplResponse, error)
+	ListIngredients(context.Context) (ImplResponse, error)
+	ForecastIngredients(context.Context, int32) (ImplResponse, error)
+	SetIngredient(context.Context, string, IngredientReq) (ImplResponse, error)
+	AdjustIngredient(context.Context, string, IngredientAdjustReq) (ImplResponse, error)
+	GetRecipe(context.Context, string) (ImplResponse, error)
+	SetRecipe(context.Context, string, Recipe) (ImplResponse, error)
+}
+
+// KitchenAPIServicer defines the api actions for the KitchenAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type KitchenAPIServicer interface {
+	GetKitchenQueue(context.Context, string) (ImplResponse, error)
+	BumpKitchenLine(context.Context, string, int32) (ImplResponse, error)
 }
 
 // OrderAPIServicer defines the api actions for the OrderAPI service
@@ -35,7 +159,12 @@ type ProductAPIRouter interface {
 // while the service implementation can be ignored with the .openapi-generator-ignore file
 // and updated with the logic required for the API.
 type OrderAPIServicer interface {
-	PlaceOrder(context.Context, OrderReq) (ImplResponse, error)
+	PlaceOrder(context.Context, string, OrderReq) (ImplResponse, error)
+	QuoteOrder(context.Context, OrderReq) (ImplResponse, error)
+	GetOrder(context.Context, string) (ImplResponse, error)
+	CancelOrder(context.Context, string, CancelOrderReq) (ImplResponse, error)
+	RefundOrder(context.Context, string, RefundReq) (ImplResponse, error)
+	ListRefunds(context.Context, time.Time, time.Time) (ImplResponse, error)
 }
 
 // ProductAPIServicer defines the api actions for the ProductAPI service
@@ -43,6 +172,33 @@ type OrderAPIServicer interface {
 // while the service implementation can be ignored with the .openapi-generator-ignore file
 // and updated with the logic required for the API.
 type ProductAPIServicer interface {
-	ListProducts(context.Context) (ImplResponse, error)
-	GetProduct(context.Context, int64) (ImplResponse, error)
+	ListProducts(context.Context, string, float32, float32, string, bool, string, int32, string, string, string) (ImplResponse, error)
+	SearchProducts(context.Context, string, int32) (ImplResponse, error)
+	CreateProduct(context.Context, ProductReq) (ImplResponse, error)
+	GetProduct(context.Context, string, string, string) (ImplResponse, error)
+	UpdateProduct(context.Context, string, string, ProductReq) (ImplResponse, error)
+	PatchProduct(context.Context, string, string, ProductPatch) (ImplResponse, error)
+	ArchiveProduct(context.Context, string, string) (ImplResponse, error)
+	RestoreProduct(context.Context, string, string) (ImplResponse, error)
+	UploadProductImage(context.Context, string, *os.File) (ImplResponse, error)
+}
+
+// SlotAPIServicer defines the api actions for the SlotAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type SlotAPIServicer interface {
+	ListSlots(context.Context, string) (ImplResponse, error)
+}
+
+// WebhookAPIServicer defines the api actions for the WebhookAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type WebhookAPIServicer interface {
+	CreateWebhook(context.Context, WebhookReq) (ImplResponse, error)
+	ListWebhooks(context.Context) (ImplResponse, error)
+	DeleteWebhook(context.Context, string) (ImplResponse, error)
+	ListWebhookDeliveries(context.Context, string, string) (ImplResponse, error)
+	ReplayWebhookDelivery(context.Context, string, string) (ImplResponse, error)
 }
@@ -16,6 +16,9 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
 // OrderAPIController binds http requests to an api service and writes the service results to the http response
@@ -57,6 +60,36 @@ func (c *OrderAPIController) Routes() Routes {
 			"/api/order",
 			c.PlaceOrder,
 		},
+		"QuoteOrder": Route{
+			"QuoteOrder",
+			strings.ToUpper("Post"),
+			"/api/order/quote",
+			c.QuoteOrder,
+		},
+		"GetOrder": Route{
+			"GetOrder",
+			strings.ToUpper("Get"),
+			"/api/order/{orderId}",
+			c.GetOrder,
+		},
+		"CancelOrder": Route{
+			"CancelOrder",
+			strings.ToUpper("Post"),
+			"/api/order/{orderId}/cancel",
+			c.CancelOrder,
+		},
+		"RefundOrder": Route{
+			"RefundOrder",
+			strings.ToUpper("Post"),
+			"/api/order/{orderId}/refund",
+			c.RefundOrder,
+		},
+		"ListRefunds": Route{
+			"ListRefunds",
+			strings.ToUpper("Get"),
+			"/api/refund",
+			c.ListRefunds,
+		},
 	}
 }
 
@@ -69,11 +102,42 @@ func (c *OrderAPIController) OrderedRoutes() []Route {
 			"/api/order",
 			c.PlaceOrder,
 		},
+		Route{
+			"QuoteOrder",
+			strings.ToUpper("Post"),
+			"/api/order/quote",
+			c.QuoteOrder,
+		},
+		Route{
+			"GetOrder",
+			strings.ToUpper("Get"),
+			"/api/order/{orderId}",
+			c.GetOrder,
+		},
+		Route{
+			"CancelOrder",
+			strings.ToUpper("Post"),
+			"/api/order/{orderId}/cancel",
+			c.CancelOrder,
+		},
+		Route{
+			"RefundOrder",
+			strings.ToUpper("Post"),
+			"/api/order/{orderId}/refund",
+			c.RefundOrder,
+		},
+		Route{
+			"ListRefunds",
+			strings.ToUpper("Get"),
+			"/api/refund",
+			c.ListRefunds,
+		},
 	}
 }
 
 // PlaceOrder - Place an order
 func (c *OrderAPIController) PlaceOrder(w http.ResponseWriter, r *http.Request) {
+	preferParam := r.Header.Get("Prefer")
 	var orderReqParam OrderReq
 	d := json.NewDecoder(r.Body)
 	d.DisallowUnknownFields()
@@ -89,12 +153,162 @@ func (c *OrderAPIController) PlaceOrder(w http.ResponseWriter, r *http.Request)
 		c.errorHandler(w, r, err, nil)
 		return
 	}
-	result, err := c.service.PlaceOrder(r.Context(), orderReqParam)
+	result, err := c.service.PlaceOrder(r.Context(), preferParam, orderReqParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// QuoteOrder - Quote an order
+func (c *OrderAPIController) QuoteOrder(w http.ResponseWriter, r *http.Request) {
+	var orderReqParam OrderReq
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&orderReqParam); err != nil && !errors.Is(err, io.EOF) {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	if err := AssertOrderReqRequired(orderReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertOrderReqConstraints(orderReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.QuoteOrder(r.Context(), orderReqParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// GetOrder - Find order by ID
+func (c *OrderAPIController) GetOrder(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	orderIdParam := params["orderId"]
+	if orderIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"orderId"}, nil)
+		return
+	}
+	result, err := c.service.GetOrder(r.Context(), orderIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// CancelOrder - Cancel an order
+func (c *OrderAPIController) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	orderIdParam := params["orderId"]
+	if orderIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"orderId"}, nil)
+		return
+	}
+	var cancelOrderReqParam CancelOrderReq
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&cancelOrderReqParam); err != nil && !errors.Is(err, io.EOF) {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	if err := AssertCancelOrderReqRequired(cancelOrderReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertCancelOrderReqConstraints(cancelOrderReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CancelOrder(r.Context(), orderIdParam, cancelOrderReqParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// RefundOrder - Refund an order
+func (c *OrderAPIController) RefundOrder(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	orderIdParam := params["orderId"]
+	if orderIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"orderId"}, nil)
+		return
+	}
+	var refundReqParam RefundReq
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&refundReqParam); err != nil && !errors.Is(err, io.EOF) {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	if err := AssertRefundReqRequired(refundReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertRefundReqConstraints(refundReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.RefundOrder(r.Context(), orderIdParam, refundReqParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ListRefunds - Report refunds
+func (c *OrderAPIController) ListRefunds(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var fromParam time.Time
+	if query.Has("from") {
+		param, err := parseTime(query.Get("from"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "from", Err: err}, nil)
+			return
+		}
+
+		fromParam = param
+	} else {
+	}
+	var toParam time.Time
+	if query.Has("to") {
+		param, err := parseTime(query.Get("to"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "to", Err: err}, nil)
+			return
+		}
+
+		toParam = param
+	} else {
+	}
+	result, err := c.service.ListRefunds(r.Context(), fromParam, toParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
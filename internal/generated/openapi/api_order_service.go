@@ -14,6 +14,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 )
 
 // OrderAPIService is a service that implements the logic for the OrderAPIServicer
@@ -28,13 +29,16 @@ func NewOrderAPIService() *OrderAPIService {
 }
 
 // PlaceOrder - Place an order
-func (s *OrderAPIService) PlaceOrder(ctx context.Context, orderReq OrderReq) (ImplResponse, error) {
+func (s *OrderAPIService) PlaceOrder(ctx context.Context, prefer string, orderReq OrderReq) (ImplResponse, error) {
 	// TODO - update PlaceOrder with the required logic for this service method.
 	// Add api_order_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
 
 	// TODO: Uncomment the next line to return response Response(200, Order{}) or use other options such as http.Ok ...
 	// return Response(200, Order{}), nil
 
+	// TODO: Uncomment the next line to return response Response(202, Order{}) or use other options such as http.Ok ...
+	// return Response(202, Order{}), nil
+
 	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
 	// return Response(400, nil),nil
 
@@ -49,3 +53,88 @@ func (s *OrderAPIService) PlaceOrder(ctx context.Context, orderReq OrderReq) (Im
 
 	return Response(http.StatusNotImplemented, nil), errors.New("PlaceOrder method not implemented")
 }
+
+// QuoteOrder - Quote an order
+func (s *OrderAPIService) QuoteOrder(ctx context.Context, orderReq OrderReq) (ImplResponse, error) {
+	// TODO - update QuoteOrder with the required logic for this service method.
+	// Add api_order_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Quote{}) or use other options such as http.Ok ...
+	// return Response(200, Quote{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(422, {}) or use other options such as http.Ok ...
+	// return Response(422, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("QuoteOrder method not implemented")
+}
+
+// GetOrder - Find order by ID
+func (s *OrderAPIService) GetOrder(ctx context.Context, orderId string) (ImplResponse, error) {
+	// TODO - update GetOrder with the required logic for this service method.
+	// Add api_order_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Order{}) or use other options such as http.Ok ...
+	// return Response(200, Order{}), nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetOrder method not implemented")
+}
+
+// CancelOrder - Cancel an order
+func (s *OrderAPIService) CancelOrder(ctx context.Context, orderId string, cancelOrderReq CancelOrderReq) (ImplResponse, error) {
+	// TODO - update CancelOrder with the required logic for this service method.
+	// Add api_order_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Order{}) or use other options such as http.Ok ...
+	// return Response(200, Order{}), nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(409, {}) or use other options such as http.Ok ...
+	// return Response(409, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("CancelOrder method not implemented")
+}
+
+// RefundOrder - Refund an order
+func (s *OrderAPIService) RefundOrder(ctx context.Context, orderId string, refundReq RefundReq) (ImplResponse, error) {
+	// TODO - update RefundOrder with the required logic for this service method.
+	// Add api_order_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Refund{}) or use other options such as http.Ok ...
+	// return Response(200, Refund{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(409, {}) or use other options such as http.Ok ...
+	// return Response(409, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(422, {}) or use other options such as http.Ok ...
+	// return Response(422, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("RefundOrder method not implemented")
+}
+
+// ListRefunds - Report refunds
+func (s *OrderAPIService) ListRefunds(ctx context.Context, from time.Time, to time.Time) (ImplResponse, error) {
+	// TODO - update ListRefunds with the required logic for this service method.
+	// Add api_order_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, RefundReport{}) or use other options such as http.Ok ...
+	// return Response(200, RefundReport{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("ListRefunds method not implemented")
+}
@@ -11,7 +11,11 @@
 package openapi
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -57,12 +61,54 @@ func (c *ProductAPIController) Routes() Routes {
 			"/api/product",
 			c.ListProducts,
 		},
+		"CreateProduct": Route{
+			"CreateProduct",
+			strings.ToUpper("Post"),
+			"/api/product",
+			c.CreateProduct,
+		},
+		"SearchProducts": Route{
+			"SearchProducts",
+			strings.ToUpper("Get"),
+			"/api/product/search",
+			c.SearchProducts,
+		},
 		"GetProduct": Route{
 			"GetProduct",
 			strings.ToUpper("Get"),
 			"/api/product/{productId}",
 			c.GetProduct,
 		},
+		"UpdateProduct": Route{
+			"UpdateProduct",
+			strings.ToUpper("Put"),
+			"/api/product/{productId}",
+			c.UpdateProduct,
+		},
+		"PatchProduct": Route{
+			"PatchProduct",
+			strings.ToUpper("Patch"),
+			"/api/product/{productId}",
+			c.PatchProduct,
+		},
+		"ArchiveProduct": Route{
+			"ArchiveProduct",
+			strings.ToUpper("Post"),
+			"/api/product/{productId}/archive",
+			c.ArchiveProduct,
+		},
+		"RestoreProduct": Route{
+			"RestoreProduct",
+			strings.ToUpper("Post"),
+			"/api/product/{productId}/restore",
+			c.RestoreProduct,
+		},
+		"UploadProductImage": Route{
+			"UploadProductImage",
+			strings.ToUpper("Post"),
+			"/api/product/{productId}/image",
+			c.UploadProductImage,
+		},
 	}
 }
 
@@ -75,44 +121,392 @@ func (c *ProductAPIController) OrderedRoutes() []Route {
 			"/api/product",
 			c.ListProducts,
 		},
+		Route{
+			"CreateProduct",
+			strings.ToUpper("Post"),
+			"/api/product",
+			c.CreateProduct,
+		},
+		Route{
+			"SearchProducts",
+			strings.ToUpper("Get"),
+			"/api/product/search",
+			c.SearchProducts,
+		},
 		Route{
 			"GetProduct",
 			strings.ToUpper("Get"),
 			"/api/product/{productId}",
 			c.GetProduct,
 		},
+		Route{
+			"UpdateProduct",
+			strings.ToUpper("Put"),
+			"/api/product/{productId}",
+			c.UpdateProduct,
+		},
+		Route{
+			"PatchProduct",
+			strings.ToUpper("Patch"),
+			"/api/product/{productId}",
+			c.PatchProduct,
+		},
+		Route{
+			"ArchiveProduct",
+			strings.ToUpper("Post"),
+			"/api/product/{productId}/archive",
+			c.ArchiveProduct,
+		},
+		Route{
+			"RestoreProduct",
+			strings.ToUpper("Post"),
+			"/api/product/{productId}/restore",
+			c.RestoreProduct,
+		},
+		Route{
+			"UploadProductImage",
+			strings.ToUpper("Post"),
+			"/api/product/{productId}/image",
+			c.UploadProductImage,
+		},
 	}
 }
 
 // ListProducts - List products
 func (c *ProductAPIController) ListProducts(w http.ResponseWriter, r *http.Request) {
-	result, err := c.service.ListProducts(r.Context())
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var categoryParam string
+	if query.Has("category") {
+		param := query.Get("category")
+
+		categoryParam = param
+	} else {
+	}
+	var minPriceParam float32
+	if query.Has("minPrice") {
+		param, err := parseNumericParameter[float32](
+			query.Get("minPrice"),
+			WithParse[float32](parseFloat32),
+			WithMinimum[float32](0),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "minPrice", Err: err}, nil)
+			return
+		}
+
+		minPriceParam = param
+	} else {
+	}
+	var maxPriceParam float32
+	if query.Has("maxPrice") {
+		param, err := parseNumericParameter[float32](
+			query.Get("maxPrice"),
+			WithParse[float32](parseFloat32),
+			WithMinimum[float32](0),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "maxPrice", Err: err}, nil)
+			return
+		}
+
+		maxPriceParam = param
+	} else {
+	}
+	var qParam string
+	if query.Has("q") {
+		param := query.Get("q")
+
+		qParam = param
+	} else {
+	}
+	var availableParam bool
+	if query.Has("available") {
+		param, err := parseBoolParameter(
+			query.Get("available"),
+			WithParse[bool](parseBool),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "available", Err: err}, nil)
+			return
+		}
+
+		availableParam = param
+	} else {
+	}
+	var sortParam string
+	if query.Has("sort") {
+		param := query.Get("sort")
+
+		sortParam = param
+	} else {
+		param := "menu"
+		sortParam = param
+	}
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := parseNumericParameter[int32](
+			query.Get("limit"),
+			WithParse[int32](parseInt32),
+			WithMinimum[int32](1),
+			WithMaximum[int32](100),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "limit", Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	} else {
+	}
+	var cursorParam string
+	if query.Has("cursor") {
+		param := query.Get("cursor")
+
+		cursorParam = param
+	} else {
+	}
+	ifNoneMatchParam := r.Header.Get("If-None-Match")
+	ifModifiedSinceParam := r.Header.Get("If-Modified-Since")
+	result, err := c.service.ListProducts(r.Context(), categoryParam, minPriceParam, maxPriceParam, qParam, availableParam, sortParam, limitParam, cursorParam, ifNoneMatchParam, ifModifiedSinceParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
-// GetProduct - Find product by ID
+// CreateProduct - Create a product
+func (c *ProductAPIController) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var productReqParam ProductReq
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&productReqParam); err != nil && !errors.Is(err, io.EOF) {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	if err := AssertProductReqRequired(productReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertProductReqConstraints(productReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CreateProduct(r.Context(), productReqParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// SearchProducts - Search products
+func (c *ProductAPIController) SearchProducts(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var qParam string
+	if query.Has("q") {
+		param := query.Get("q")
+
+		qParam = param
+	} else {
+		c.errorHandler(w, r, &RequiredError{Field: "q"}, nil)
+		return
+	}
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := parseNumericParameter[int32](
+			query.Get("limit"),
+			WithParse[int32](parseInt32),
+			WithMinimum[int32](1),
+			WithMaximum[int32](50),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "limit", Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	} else {
+		var param int32 = 20
+		limitParam = param
+	}
+	result, err := c.service.SearchProducts(r.Context(), qParam, limitParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// GetProduct - Find product by ID or slug
 func (c *ProductAPIController) GetProduct(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	productIdParam, err := parseNumericParameter[int64](
-		params["productId"],
-		WithRequire[int64](parseInt64),
-	)
+	productIdParam := params["productId"]
+	if productIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"productId"}, nil)
+		return
+	}
+	ifNoneMatchParam := r.Header.Get("If-None-Match")
+	ifModifiedSinceParam := r.Header.Get("If-Modified-Since")
+	result, err := c.service.GetProduct(r.Context(), productIdParam, ifNoneMatchParam, ifModifiedSinceParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// UpdateProduct - Replace a product
+func (c *ProductAPIController) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	productIdParam := params["productId"]
+	if productIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"productId"}, nil)
+		return
+	}
+	ifMatchParam := r.Header.Get("If-Match")
+	var productReqParam ProductReq
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&productReqParam); err != nil && !errors.Is(err, io.EOF) {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	if err := AssertProductReqRequired(productReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertProductReqConstraints(productReqParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.UpdateProduct(r.Context(), productIdParam, ifMatchParam, productReqParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// PatchProduct - Update a product
+func (c *ProductAPIController) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	productIdParam := params["productId"]
+	if productIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"productId"}, nil)
+		return
+	}
+	ifMatchParam := r.Header.Get("If-Match")
+	var productPatchParam ProductPatch
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&productPatchParam); err != nil && !errors.Is(err, io.EOF) {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	if err := AssertProductPatchRequired(productPatchParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertProductPatchConstraints(productPatchParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PatchProduct(r.Context(), productIdParam, ifMatchParam, productPatchParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ArchiveProduct - Archive a product
+func (c *ProductAPIController) ArchiveProduct(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	productIdParam := params["productId"]
+	if productIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"productId"}, nil)
+		return
+	}
+	ifMatchParam := r.Header.Get("If-Match")
+	result, err := c.service.ArchiveProduct(r.Context(), productIdParam, ifMatchParam)
+	// If an error occurred, encode the error with the status code
 	if err != nil {
-		c.errorHandler(w, r, &ParsingError{Param: "productId", Err: err}, nil)
+		c.errorHandler(w, r, err, &result)
 		return
 	}
-	result, err := c.service.GetProduct(r.Context(), productIdParam)
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// RestoreProduct - Restore a product
+func (c *ProductAPIController) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	productIdParam := params["productId"]
+	if productIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"productId"}, nil)
+		return
+	}
+	ifMatchParam := r.Header.Get("If-Match")
+	result, err := c.service.RestoreProduct(r.Context(), productIdParam, ifMatchParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// UploadProductImage - Upload a product image
+func (c *ProductAPIController) UploadProductImage(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	params := mux.Vars(r)
+	productIdParam := params["productId"]
+	if productIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"productId"}, nil)
+		return
+	}
+	var fileParam *os.File
+	{
+		param, err := ReadFormFileToTempFile(r, "file")
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "file", Err: err}, nil)
+			return
+		}
+
+		fileParam = param
+	}
+
+	result, err := c.service.UploadProductImage(r.Context(), productIdParam, fileParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
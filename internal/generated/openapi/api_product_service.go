@@ -14,6 +14,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"os"
 )
 
 // ProductAPIService is a service that implements the logic for the ProductAPIServicer
@@ -28,24 +29,67 @@ func NewProductAPIService() *ProductAPIService {
 }
 
 // ListProducts - List products
-func (s *ProductAPIService) ListProducts(ctx context.Context) (ImplResponse, error) {
+func (s *ProductAPIService) ListProducts(ctx context.Context, category string, minPrice float32, maxPrice float32, q string, available bool, sort string, limit int32, cursor string, ifNoneMatch string, ifModifiedSince string) (ImplResponse, error) {
 	// TODO - update ListProducts with the required logic for this service method.
 	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
 
 	// TODO: Uncomment the next line to return response Response(200, []Product{}) or use other options such as http.Ok ...
 	// return Response(200, []Product{}), nil
 
+	// TODO: Uncomment the next line to return response Response(304, {}) or use other options such as http.Ok ...
+	// return Response(304, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
 	return Response(http.StatusNotImplemented, nil), errors.New("ListProducts method not implemented")
 }
 
-// GetProduct - Find product by ID
-func (s *ProductAPIService) GetProduct(ctx context.Context, productId int64) (ImplResponse, error) {
+// CreateProduct - Create a product
+func (s *ProductAPIService) CreateProduct(ctx context.Context, productReq ProductReq) (ImplResponse, error) {
+	// TODO - update CreateProduct with the required logic for this service method.
+	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(201, Product{}) or use other options such as http.Ok ...
+	// return Response(201, Product{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(401, {}) or use other options such as http.Ok ...
+	// return Response(401, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("CreateProduct method not implemented")
+}
+
+// SearchProducts - Search products
+func (s *ProductAPIService) SearchProducts(ctx context.Context, q string, limit int32) (ImplResponse, error) {
+	// TODO - update SearchProducts with the required logic for this service method.
+	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, ProductSearch{}) or use other options such as http.Ok ...
+	// return Response(200, ProductSearch{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(501, {}) or use other options such as http.Ok ...
+	// return Response(501, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("SearchProducts method not implemented")
+}
+
+// GetProduct - Find product by ID or slug
+func (s *ProductAPIService) GetProduct(ctx context.Context, productId string, ifNoneMatch string, ifModifiedSince string) (ImplResponse, error) {
 	// TODO - update GetProduct with the required logic for this service method.
 	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
 
 	// TODO: Uncomment the next line to return response Response(200, Product{}) or use other options such as http.Ok ...
 	// return Response(200, Product{}), nil
 
+	// TODO: Uncomment the next line to return response Response(304, {}) or use other options such as http.Ok ...
+	// return Response(304, nil),nil
+
 	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
 	// return Response(400, nil),nil
 
@@ -54,3 +98,133 @@ func (s *ProductAPIService) GetProduct(ctx context.Context, productId int64) (Im
 
 	return Response(http.StatusNotImplemented, nil), errors.New("GetProduct method not implemented")
 }
+
+// UpdateProduct - Replace a product
+func (s *ProductAPIService) UpdateProduct(ctx context.Context, productId string, ifMatch string, productReq ProductReq) (ImplResponse, error) {
+	// TODO - update UpdateProduct with the required logic for this service method.
+	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Product{}) or use other options such as http.Ok ...
+	// return Response(200, Product{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(401, {}) or use other options such as http.Ok ...
+	// return Response(401, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(412, {}) or use other options such as http.Ok ...
+	// return Response(412, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(428, {}) or use other options such as http.Ok ...
+	// return Response(428, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("UpdateProduct method not implemented")
+}
+
+// PatchProduct - Update a product
+func (s *ProductAPIService) PatchProduct(ctx context.Context, productId string, ifMatch string, productPatch ProductPatch) (ImplResponse, error) {
+	// TODO - update PatchProduct with the required logic for this service method.
+	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Product{}) or use other options such as http.Ok ...
+	// return Response(200, Product{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(401, {}) or use other options such as http.Ok ...
+	// return Response(401, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(412, {}) or use other options such as http.Ok ...
+	// return Response(412, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(428, {}) or use other options such as http.Ok ...
+	// return Response(428, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PatchProduct method not implemented")
+}
+
+// ArchiveProduct - Archive a product
+func (s *ProductAPIService) ArchiveProduct(ctx context.Context, productId string, ifMatch string) (ImplResponse, error) {
+	// TODO - update ArchiveProduct with the required logic for this service method.
+	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Product{}) or use other options such as http.Ok ...
+	// return Response(200, Product{}), nil
+
+	// TODO: Uncomment the next line to return response Response(401, {}) or use other options such as http.Ok ...
+	// return Response(401, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(412, {}) or use other options such as http.Ok ...
+	// return Response(412, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(428, {}) or use other options such as http.Ok ...
+	// return Response(428, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("ArchiveProduct method not implemented")
+}
+
+// RestoreProduct - Restore a product
+func (s *ProductAPIService) RestoreProduct(ctx context.Context, productId string, ifMatch string) (ImplResponse, error) {
+	// TODO - update RestoreProduct with the required logic for this service method.
+	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Product{}) or use other options such as http.Ok ...
+	// return Response(200, Product{}), nil
+
+	// TODO: Uncomment the next line to return response Response(401, {}) or use other options such as http.Ok ...
+	// return Response(401, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(412, {}) or use other options such as http.Ok ...
+	// return Response(412, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(428, {}) or use other options such as http.Ok ...
+	// return Response(428, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("RestoreProduct method not implemented")
+}
+
+// UploadProductImage - Upload a product image
+func (s *ProductAPIService) UploadProductImage(ctx context.Context, productId string, file *os.File) (ImplResponse, error) {
+	// TODO - update UploadProductImage with the required logic for this service method.
+	// Add api_product_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Product{}) or use other options such as http.Ok ...
+	// return Response(200, Product{}), nil
+
+	// TODO: Uncomment the next line to return response Response(400, {}) or use other options such as http.Ok ...
+	// return Response(400, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(401, {}) or use other options such as http.Ok ...
+	// return Response(401, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
+	// return Response(404, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(413, {}) or use other options such as http.Ok ...
+	// return Response(413, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(415, {}) or use other options such as http.Ok ...
+	// return Response(415, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(422, {}) or use other options such as http.Ok ...
+	// return Response(422, nil),nil
+
+	// TODO: Uncomment the next line to return response Response(501, {}) or use other options such as http.Ok ...
+	// return Response(501, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("UploadProductImage method not implemented")
+}
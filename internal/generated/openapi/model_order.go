@@ -10,6 +10,10 @@
 
 package openapi
 
+import (
+	"time"
+)
+
 type Order struct {
 	Id string `json:"id,omitempty"`
 
@@ -17,6 +21,31 @@ type Order struct {
 
 	Discounts float32 `json:"discounts,omitempty"`
 
+	// Delivery fee included in the total
+	DeliveryFee float32 `json:"deliveryFee,omitempty"`
+
+	// Orders placed asynchronously stay pending until their coupon has been checked
+	Status string `json:"status,omitempty"`
+
+	// Why the order was rejected or cancelled
+	StatusReason string `json:"statusReason,omitempty"`
+
+	Fulfilment Fulfilment `json:"fulfilment,omitempty"`
+
+	// When a pre-order is to be picked up
+	ScheduledFor time.Time `json:"scheduledFor,omitempty"`
+
+	// When a pre-order was released to the kitchen. Left out until it is.
+	ReleasedAt time.Time `json:"releasedAt,omitempty"`
+
+	// When the order was placed
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+
+	// Total refunded so far
+	Refunded float32 `json:"refunded,omitempty"`
+
+	Refunds []Refund `json:"refunds,omitempty"`
+
 	Items []OrderItemsInner `json:"items,omitempty"`
 
 	Products []Product `json:"products,omitempty"`
@@ -24,6 +53,14 @@ type Order struct {
 
 // AssertOrderRequired checks if the required fields are not zero-ed
 func AssertOrderRequired(obj Order) error {
+	if err := AssertFulfilmentRequired(obj.Fulfilment); err != nil {
+		return err
+	}
+	for _, el := range obj.Refunds {
+		if err := AssertRefundRequired(el); err != nil {
+			return err
+		}
+	}
 	for _, el := range obj.Items {
 		if err := AssertOrderItemsInnerRequired(el); err != nil {
 			return err
@@ -39,6 +76,14 @@ func AssertOrderRequired(obj Order) error {
 
 // AssertOrderConstraints checks if the values respects the defined constraints
 func AssertOrderConstraints(obj Order) error {
+	if err := AssertFulfilmentConstraints(obj.Fulfilment); err != nil {
+		return err
+	}
+	for _, el := range obj.Refunds {
+		if err := AssertRefundConstraints(el); err != nil {
+			return err
+		}
+	}
 	for _, el := range obj.Items {
 		if err := AssertOrderItemsInnerConstraints(el); err != nil {
 			return err
@@ -10,6 +10,10 @@
 
 package openapi
 
+import (
+	"time"
+)
+
 type OrderItemsInner struct {
 
 	// ID of the product
@@ -17,14 +21,36 @@ type OrderItemsInner struct {
 
 	// Item count
 	Quantity int32 `json:"quantity,omitempty"`
+
+	// Modifiers chosen for the line, priced as when the order was placed
+	Modifiers []Modifier `json:"modifiers,omitempty"`
+
+	// Free-text instructions for the kitchen
+	Note string `json:"note,omitempty"`
+
+	// Price of the line including modifiers, before the order discount
+	LineTotal float32 `json:"lineTotal,omitempty"`
+
+	// When the kitchen finished the line. Left out until it does.
+	BumpedAt time.Time `json:"bumpedAt,omitempty"`
 }
 
 // AssertOrderItemsInnerRequired checks if the required fields are not zero-ed
 func AssertOrderItemsInnerRequired(obj OrderItemsInner) error {
+	for _, el := range obj.Modifiers {
+		if err := AssertModifierRequired(el); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // AssertOrderItemsInnerConstraints checks if the values respects the defined constraints
 func AssertOrderItemsInnerConstraints(obj OrderItemsInner) error {
+	for _, el := range obj.Modifiers {
+		if err := AssertModifierConstraints(el); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -10,12 +10,24 @@
 
 package openapi
 
+import (
+	"time"
+)
+
 // OrderReq - Place a new order
 type OrderReq struct {
 
 	// Optional promo code applied to the order
 	CouponCode string `json:"couponCode,omitempty"`
 
+	// Optional ID of an unexpired quote for the same items and coupon that no other order has used; the order is charged the quoted prices
+	QuoteId string `json:"quoteId,omitempty"`
+
+	Fulfilment Fulfilment `json:"fulfilment,omitempty"`
+
+	// Optional time to pick the order up, for ordering ahead. It takes a place in the pickup slot it falls in; see /slots.
+	ScheduledFor time.Time `json:"scheduledFor,omitempty"`
+
 	Items []OrderReqItemsInner `json:"items"`
 }
 
@@ -30,6 +42,9 @@ func AssertOrderReqRequired(obj OrderReq) error {
 		}
 	}
 
+	if err := AssertFulfilmentRequired(obj.Fulfilment); err != nil {
+		return err
+	}
 	for _, el := range obj.Items {
 		if err := AssertOrderReqItemsInnerRequired(el); err != nil {
 			return err
@@ -40,6 +55,9 @@ func AssertOrderReqRequired(obj OrderReq) error {
 
 // AssertOrderReqConstraints checks if the values respects the defined constraints
 func AssertOrderReqConstraints(obj OrderReq) error {
+	if err := AssertFulfilmentConstraints(obj.Fulfilment); err != nil {
+		return err
+	}
 	for _, el := range obj.Items {
 		if err := AssertOrderReqItemsInnerConstraints(el); err != nil {
 			return err
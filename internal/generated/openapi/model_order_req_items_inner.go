@@ -17,6 +17,12 @@ type OrderReqItemsInner struct {
 
 	// Item count (required)
 	Quantity int32 `json:"quantity"`
+
+	// IDs of the modifiers chosen from the product's modifier groups
+	Modifiers []string `json:"modifiers,omitempty"`
+
+	// Free-text instructions for the kitchen, up to 200 characters
+	Note string `json:"note,omitempty"`
 }
 
 // AssertOrderReqItemsInnerRequired checks if the required fields are not zero-ed
@@ -13,6 +13,9 @@ package openapi
 type Product struct {
 	Id string `json:"id,omitempty"`
 
+	// Made from the name when the product is created, and made again when it is renamed. Products can be looked up by it instead of their ID, and by the slugs they had before being renamed, which no other product is given. Products never have a slug that is a path of the API, such as search.
+	Slug string `json:"slug,omitempty"`
+
 	Name string `json:"name,omitempty"`
 
 	// Selling price
@@ -20,11 +23,34 @@ type Product struct {
 
 	Category string `json:"category,omitempty"`
 
+	// Slug of the product's category
+	CategorySlug string `json:"categorySlug,omitempty"`
+
+	Description string `json:"description,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+
+	// False when the product's stock has run out, or it is archived
+	Available bool `json:"available,omitempty"`
+
+	// Goes up by one with every change to the product
+	Version int64 `json:"version,omitempty"`
+
+	// True when the product has been taken off the menu
+	Archived bool `json:"archived,omitempty"`
+
+	ModifierGroups []ModifierGroup `json:"modifierGroups,omitempty"`
+
 	Image ProductImage `json:"image,omitempty"`
 }
 
 // AssertProductRequired checks if the required fields are not zero-ed
 func AssertProductRequired(obj Product) error {
+	for _, el := range obj.ModifierGroups {
+		if err := AssertModifierGroupRequired(el); err != nil {
+			return err
+		}
+	}
 	if err := AssertProductImageRequired(obj.Image); err != nil {
 		return err
 	}
@@ -33,6 +59,11 @@ func AssertProductRequired(obj Product) error {
 
 // AssertProductConstraints checks if the values respects the defined constraints
 func AssertProductConstraints(obj Product) error {
+	for _, el := range obj.ModifierGroups {
+		if err := AssertModifierGroupConstraints(el); err != nil {
+			return err
+		}
+	}
 	if err := AssertProductImageConstraints(obj.Image); err != nil {
 		return err
 	}
@@ -0,0 +1,193 @@
+// Package images turns uploaded product pictures into the sizes the menu shows
+// them at, and keeps the results on the local filesystem.
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Variants are the sizes a product picture can be shown at.
+var Variants = []string{"thumbnail", "mobile", "tablet", "desktop"}
+
+const (
+	defaultMaxBytes  = 10 << 20
+	defaultMaxPixels = 40_000_000
+	defaultQuality   = 85
+)
+
+var (
+	// ErrTooLarge is returned for uploads of more than the configured bytes.
+	ErrTooLarge = errors.New("image is too large")
+	// ErrUnsupportedType is returned for uploads that are not a JPEG or PNG.
+	ErrUnsupportedType = errors.New("image must be a JPEG or PNG")
+	// ErrInvalid is returned for JPEGs and PNGs that can't be decoded, or
+	// that have more than the configured pixels.
+	ErrInvalid = errors.New("image can't be read")
+)
+
+// Size is the box a variant is cut to. With one side zero, the variant keeps
+// the original's proportions.
+type Size struct {
+	Width  int `yaml:"width"`
+	Height int `yaml:"height"`
+}
+
+// Config says where renditions are written and served from, what uploads
+// are accepted, and the size of each variant.
+type Config struct {
+	// Dir is the directory renditions are written to.
+	Dir string `yaml:"dir"`
+	// URL is the absolute URL Dir is served at, e.g. "http://localhost:8080/images/".
+	URL string `yaml:"url"`
+	// MaxBytes and MaxPixels limit the originals that are accepted.
+	MaxBytes  int64 `yaml:"maxBytes"`
+	MaxPixels int   `yaml:"maxPixels"`
+	// Quality is the JPEG quality renditions are encoded at, from 1 to 100.
+	Quality  int             `yaml:"quality"`
+	Variants map[string]Size `yaml:"variants"`
+}
+
+// Store makes and keeps renditions.
+type Store struct {
+	dir       string
+	url       string
+	maxBytes  int64
+	maxPixels int
+	quality   int
+	variants  map[string]Size
+}
+
+// New checks cfg and creates its directory.
+func New(cfg Config) (*Store, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("images: no directory")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || !u.IsAbs() {
+		return nil, fmt.Errorf("images: URL %q is not absolute", cfg.URL)
+	}
+	if len(cfg.Variants) == 0 {
+		return nil, errors.New("images: no variants")
+	}
+	for name, size := range cfg.Variants {
+		known := false
+		for _, variant := range Variants {
+			known = known || name == variant
+		}
+		if !known {
+			return nil, fmt.Errorf("images: unknown variant %q", name)
+		}
+		if size.Width < 0 || size.Height < 0 || size.Width == 0 && size.Height == 0 {
+			return nil, fmt.Errorf("images: variant %q has no size", name)
+		}
+	}
+	if cfg.Quality < 0 || cfg.Quality > 100 {
+		return nil, fmt.Errorf("images: quality %d is not between 1 and 100", cfg.Quality)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("images: %w", err)
+	}
+	s := &Store{
+		dir:       cfg.Dir,
+		url:       strings.TrimSuffix(cfg.URL, "/") + "/",
+		maxBytes:  cfg.MaxBytes,
+		maxPixels: cfg.MaxPixels,
+		quality:   cfg.Quality,
+		variants:  cfg.Variants,
+	}
+	if s.maxBytes <= 0 {
+		s.maxBytes = defaultMaxBytes
+	}
+	if s.maxPixels <= 0 {
+		s.maxPixels = defaultMaxPixels
+	}
+	if s.quality == 0 {
+		s.quality = defaultQuality
+	}
+	return s, nil
+}
+
+// Dir is the directory renditions are written to.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Save checks the original read from r, and writes a JPEG rendition of it
+// for each variant. It returns the URL of each rendition by variant.
+// Renditions are named after the original's content, so they never change
+// once written.
+func (s *Store) Save(r io.Reader) (map[string]string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, ErrTooLarge
+	}
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png":
+	default:
+		return nil, ErrUnsupportedType
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width*cfg.Height > s.maxPixels {
+		return nil, ErrInvalid
+	}
+	original, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:8])
+	names := make([]string, 0, len(s.variants))
+	for variant := range s.variants {
+		names = append(names, variant)
+	}
+	sort.Strings(names)
+	urls := make(map[string]string, len(names))
+	for _, variant := range names {
+		file := name + "-" + variant + ".jpg"
+		if err := s.write(file, cover(original, s.variants[variant])); err != nil {
+			return nil, err
+		}
+		urls[variant] = s.url + file
+	}
+	return urls, nil
+}
+
+// write encodes img to the file name in the store's directory, replacing it
+// only once it is complete.
+func (s *Store) write(name string, img image.Image) error {
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := jpeg.Encode(tmp, img, &jpeg.Options{Quality: s.quality}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
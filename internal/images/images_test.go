@@ -0,0 +1,127 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testStore(t *testing.T, maxBytes int64) *Store {
+	t.Helper()
+	s, err := New(Config{
+		Dir:      t.TempDir(),
+		URL:      "http://localhost:8080/images",
+		MaxBytes: maxBytes,
+		Variants: map[string]Size{
+			"thumbnail": {Width: 100, Height: 100},
+			"mobile":    {Width: 300, Height: 200},
+			"desktop":   {Width: 1200},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSave(t *testing.T) {
+	s := testStore(t, 0)
+	original := image.NewNRGBA(image.Rect(0, 0, 800, 400))
+	// Transparent on the left, red on the right.
+	for y := 0; y < 400; y++ {
+		for x := 400; x < 800; x++ {
+			original.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	urls, err := s.Save(bytes.NewReader(encodePNG(t, original)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	renditions := map[string]image.Image{}
+	sizes := map[string]image.Point{}
+	for variant, url := range urls {
+		assert.Contains(t, url, "http://localhost:8080/images/")
+		renditions[variant] = readJPEG(t, filepath.Join(s.Dir(), path.Base(url)))
+		sizes[variant] = renditions[variant].Bounds().Size()
+	}
+	// Thumbnails and mobile pictures are cut to shape; the desktop picture
+	// keeps its proportions and isn't scaled up.
+	assert.Equal(t, map[string]image.Point{
+		"thumbnail": {X: 100, Y: 100},
+		"mobile":    {X: 300, Y: 200},
+		"desktop":   {X: 800, Y: 400},
+	}, sizes)
+
+	img := renditions["desktop"]
+	r, g, b, _ := img.At(100, 200).RGBA()
+	assert.True(t, r > 0xf000 && g > 0xf000 && b > 0xf000, "transparent parts are white")
+	r, g, _, _ = img.At(700, 200).RGBA()
+	assert.True(t, r > 0xf000 && g < 0x1000, "opaque parts keep their colour")
+}
+
+func TestSaveRejects(t *testing.T) {
+	s := testStore(t, 1024)
+	small := encodePNG(t, image.NewGray(image.Rect(0, 0, 10, 10)))
+	large := encodePNG(t, noise(100, 100))
+
+	_, err := s.Save(bytes.NewReader([]byte("GIF89a not really")))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	_, err = s.Save(bytes.NewReader(large))
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, err = s.Save(bytes.NewReader(small[:len(small)/2]))
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = s.Save(bytes.NewReader(small))
+	assert.NoError(t, err)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Dir: t.TempDir(), URL: "/images/", Variants: map[string]Size{"thumbnail": {Width: 100}}})
+	assert.Error(t, err, "relative URL")
+	_, err = New(Config{Dir: t.TempDir(), URL: "http://localhost/images/", Variants: map[string]Size{"banner": {Width: 100}}})
+	assert.Error(t, err, "unknown variant")
+	_, err = New(Config{Dir: t.TempDir(), URL: "http://localhost/images/", Variants: map[string]Size{"thumbnail": {}}})
+	assert.Error(t, err, "no size")
+}
+
+func noise(w, h int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	seed := uint32(1)
+	for i := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i] = uint8(seed >> 24)
+	}
+	return img
+}
+
+func readJPEG(t *testing.T, name string) image.Image {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
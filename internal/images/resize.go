@@ -0,0 +1,66 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// cover cuts img to the proportions of size, keeping its centre, and scales
+// the cut down to size. Pictures smaller than size are cut but not scaled up.
+// Transparent parts are made white, as JPEGs have no transparency.
+func cover(img image.Image, size Size) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	width, height := size.Width, size.Height
+	switch {
+	case width == 0:
+		width = max(1, (height*w+h/2)/h)
+	case height == 0:
+		height = max(1, (width*h+w/2)/w)
+	}
+
+	cutW, cutH := w, h
+	if w*height > h*width {
+		cutW = max(1, (h*width+height/2)/height)
+	} else {
+		cutH = max(1, (w*height+width/2)/width)
+	}
+	cut := image.Rect(0, 0, cutW, cutH).Add(bounds.Min).Add(image.Pt((w-cutW)/2, (h-cutH)/2))
+	if cutW < width {
+		width, height = cutW, cutH
+	}
+
+	flat := image.NewRGBA(image.Rect(0, 0, cutW, cutH))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, cut.Min, draw.Over)
+	return shrink(flat, width, height)
+}
+
+// shrink scales src down to width by height, making each pixel the average
+// of the block of src pixels it covers.
+func shrink(src *image.RGBA, width, height int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w == width && h == height {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*h/height, max((y+1)*h/height, y*h/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*w/width, max((x+1)*w/width, x*w/width+1)
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r, g, b, a = r+int(p[0]), g+int(p[1]), b+int(p[2]), a+int(p[3])
+					n++
+				}
+			}
+			p := dst.Pix[y*dst.Stride+x*4:]
+			p[0], p[1], p[2], p[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,55 @@
+// Package kitchen routes order lines to the stations that prepare them, such
+// as the grill, drinks or desserts.
+package kitchen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config maps products, by ID, and product categories to stations. A
+// product's own entry wins over its category's. Lines of anything that isn't
+// mapped are prepared at a station named after their category.
+type Config struct {
+	Products   map[string]string `yaml:"products"`
+	Categories map[string]string `yaml:"categories"`
+}
+
+// Stations is a parsed Config. A nil *Stations maps nothing.
+type Stations struct {
+	products   map[string]string
+	categories map[string]string
+}
+
+// New checks cfg and builds its stations. Categories match whatever their
+// case.
+func New(cfg Config) (*Stations, error) {
+	s := &Stations{products: make(map[string]string, len(cfg.Products)), categories: make(map[string]string, len(cfg.Categories))}
+	for id, station := range cfg.Products {
+		if strings.TrimSpace(station) == "" {
+			return nil, fmt.Errorf("kitchen: product %q has no station", id)
+		}
+		s.products[id] = station
+	}
+	for category, station := range cfg.Categories {
+		if strings.TrimSpace(station) == "" {
+			return nil, fmt.Errorf("kitchen: category %q has no station", category)
+		}
+		s.categories[strings.ToLower(category)] = station
+	}
+	return s, nil
+}
+
+// Station returns the station that prepares productID, which is in category.
+func (s *Stations) Station(productID, category string) string {
+	if s == nil {
+		return category
+	}
+	if station, ok := s.products[productID]; ok {
+		return station
+	}
+	if station, ok := s.categories[strings.ToLower(category)]; ok {
+		return station
+	}
+	return category
+}
@@ -0,0 +1,29 @@
+package kitchen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStations_Station(t *testing.T) {
+	s, err := New(Config{
+		Products:   map[string]string{"7": "desserts"},
+		Categories: map[string]string{"Waffle": "grill", "Cake": "pastry"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "grill", s.Station("1", "waffle"))
+	assert.Equal(t, "desserts", s.Station("7", "Cake"), "the product's own station wins")
+	assert.Equal(t, "pastry", s.Station("8", "Cake"))
+	assert.Equal(t, "Tiramisu", s.Station("4", "Tiramisu"), "unmapped categories are their own station")
+
+	var none *Stations
+	assert.Equal(t, "Waffle", none.Station("1", "Waffle"))
+}
+
+func TestNew_RejectsEmptyStations(t *testing.T) {
+	_, err := New(Config{Categories: map[string]string{"Waffle": " "}})
+	assert.EqualError(t, err, `kitchen: category "Waffle" has no station`)
+}
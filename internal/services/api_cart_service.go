@@ -0,0 +1,423 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultCartTTL = 30 * time.Minute
+	// couponCheckTimeout bounds a background coupon search, matching the
+	// timeout PlaceOrder gives its own search.
+	couponCheckTimeout = 60 * time.Second
+)
+
+// Coupon validation progress reported on a cart.
+const (
+	couponPending = "pending"
+	couponValid   = "valid"
+	couponInvalid = "invalid"
+	// couponUnknown is reported for a coupon no check is running for, as
+	// after a restart; checkout checks it.
+	couponUnknown = "unknown"
+)
+
+// CartAPIService implements business logic for the CartAPI defined by the generated OpenAPI.
+// Carts are stored through `db.CartDao` and checked out through the OrderAPIService pipeline.
+// Coupon validation starts as soon as a coupon is applied, so checkout rarely waits for it.
+type CartAPIService struct {
+	cartDao db.CartDao
+	orders  *OrderAPIService
+	// ttl is how long a cart survives without being modified.
+	ttl time.Duration
+
+	mu sync.Mutex
+	// checks holds the coupon search started for each cart's current coupon.
+	checks map[db.ID]*couponCheck
+}
+
+// couponCheck is a coupon search running in the background for a cart. It
+// satisfies db.SearchResult so checkout can hand it to the order pipeline, and
+// unlike the underlying search it can be waited on more than once.
+type couponCheck struct {
+	code    string
+	started time.Time
+	// cancel stops the search once the cart no longer needs it.
+	cancel context.CancelFunc
+	done   chan struct{}
+	valid  bool
+	err    error
+}
+
+// Validate implements db.SearchResult.
+func (c *couponCheck) Validate() (bool, error) {
+	<-c.done
+	return c.valid, c.err
+}
+
+func (c *couponCheck) status() string {
+	select {
+	case <-c.done:
+		if c.err == nil && c.valid {
+			return couponValid
+		}
+		return couponInvalid
+	default:
+		return couponPending
+	}
+}
+
+// failed reports whether the search ended in an error, such as a timeout,
+// rather than an answer.
+func (c *couponCheck) failed() bool {
+	select {
+	case <-c.done:
+		return c.err != nil
+	default:
+		return false
+	}
+}
+
+var _ db.SearchResult = &couponCheck{}
+
+// NewCartAPIService creates a cart service that checks out through orders.
+// Carts idle for longer than ttl expire; zero selects the default.
+func NewCartAPIService(cartDao db.CartDao, orders *OrderAPIService, ttl time.Duration) *CartAPIService {
+	if ttl <= 0 {
+		ttl = defaultCartTTL
+	}
+	return &CartAPIService{
+		cartDao: cartDao,
+		orders:  orders,
+		ttl:     ttl,
+		checks:  make(map[db.ID]*couponCheck),
+	}
+}
+
+// CreateCart - Create a cart
+func (s *CartAPIService) CreateCart(ctx context.Context) (openapi.ImplResponse, error) {
+	cart := db.Cart{ID: uuid.New().String(), UpdatedAt: time.Now()}
+	if err := s.cartDao.CreateCart(ctx, cart); err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	return s.cartResponse(ctx, http.StatusCreated, cart)
+}
+
+// GetCart - Find cart by ID
+func (s *CartAPIService) GetCart(ctx context.Context, cartId string) (openapi.ImplResponse, error) {
+	cart, err := s.activeCart(ctx, db.ID(cartId))
+	if err != nil {
+		return cartFailure(err)
+	}
+	return s.cartResponse(ctx, http.StatusOK, cart)
+}
+
+// SetCartItem - Add or update a cart line
+func (s *CartAPIService) SetCartItem(ctx context.Context, cartId string, productId string, cartItemReq openapi.CartItemReq) (openapi.ImplResponse, error) {
+	lines, err := mergeLines([]openapi.OrderReqItemsInner{{
+		ProductId: productId,
+		Quantity:  cartItemReq.Quantity,
+		Modifiers: cartItemReq.Modifiers,
+		Note:      cartItemReq.Note,
+	}})
+	if err != nil {
+		return pricingFailure(err)
+	}
+	if _, err := s.activeCart(ctx, db.ID(cartId)); err != nil {
+		return cartFailure(err)
+	}
+	products, err := s.orders.productDao.GetProductsByIDs(ctx, []db.ID{db.ID(productId)})
+	var unknown *db.UnknownProductsError
+	if errors.As(err, &unknown) {
+		return openapi.Response(http.StatusBadRequest, "invalid product specified"), nil
+	} else if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	product := products[db.ID(productId)]
+	if !product.ArchivedAt.IsZero() {
+		return openapi.Response(http.StatusBadRequest, "invalid product specified"), nil
+	}
+	// The modifiers are checked now so that checkout doesn't turn them down.
+	if _, err := chooseModifiers(product, lines[0].Modifiers); err != nil {
+		return pricingFailure(err)
+	}
+	if err := s.cartDao.SetCartItem(ctx, db.ID(cartId), lines[0]); err != nil {
+		return cartFailure(err)
+	}
+	return s.currentCart(ctx, db.ID(cartId))
+}
+
+// RemoveCartItem - Remove a cart line
+func (s *CartAPIService) RemoveCartItem(ctx context.Context, cartId string, productId string) (openapi.ImplResponse, error) {
+	if _, err := s.activeCart(ctx, db.ID(cartId)); err != nil {
+		return cartFailure(err)
+	}
+	if err := s.cartDao.RemoveCartItem(ctx, db.ID(cartId), db.ID(productId)); err != nil {
+		return cartFailure(err)
+	}
+	return s.currentCart(ctx, db.ID(cartId))
+}
+
+// ApplyCartCoupon - Apply a coupon
+func (s *CartAPIService) ApplyCartCoupon(ctx context.Context, cartId string, cartCouponReq openapi.CartCouponReq) (openapi.ImplResponse, error) {
+	if !validCouponFormat(cartCouponReq.CouponCode) {
+		return openapi.Response(http.StatusUnprocessableEntity, "invalid coupon code"), nil
+	}
+	if _, err := s.activeCart(ctx, db.ID(cartId)); err != nil {
+		return cartFailure(err)
+	}
+	if err := s.cartDao.SetCartCoupon(ctx, db.ID(cartId), cartCouponReq.CouponCode); err != nil {
+		return cartFailure(err)
+	}
+	s.couponCheckFor(db.ID(cartId), cartCouponReq.CouponCode)
+	return s.currentCart(ctx, db.ID(cartId))
+}
+
+// RemoveCartCoupon - Remove the coupon
+func (s *CartAPIService) RemoveCartCoupon(ctx context.Context, cartId string) (openapi.ImplResponse, error) {
+	if _, err := s.activeCart(ctx, db.ID(cartId)); err != nil {
+		return cartFailure(err)
+	}
+	if err := s.cartDao.SetCartCoupon(ctx, db.ID(cartId), ""); err != nil {
+		return cartFailure(err)
+	}
+	s.forgetCouponCheck(db.ID(cartId))
+	return s.currentCart(ctx, db.ID(cartId))
+}
+
+// CheckoutCart - Check out a cart
+func (s *CartAPIService) CheckoutCart(ctx context.Context, cartId string, fulfilment openapi.Fulfilment) (openapi.ImplResponse, error) {
+	cart, err := s.activeCart(ctx, db.ID(cartId))
+	if err != nil {
+		return cartFailure(err)
+	}
+	if len(cart.Items) == 0 {
+		return openapi.Response(http.StatusUnprocessableEntity, "cart is empty"), nil
+	}
+	orderReq := openapi.OrderReq{CouponCode: cart.CouponCode, Fulfilment: fulfilment}
+	for _, item := range cart.Items {
+		orderReq.Items = append(orderReq.Items, openapi.OrderReqItemsInner{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+			Modifiers: modifierIDs(item.Modifiers),
+			Note:      item.Note,
+		})
+	}
+	// A cart without a usable coupon gets no check; the order pipeline then
+	// rejects it the same way PlaceOrder would.
+	var coupon db.SearchResult
+	if validCouponFormat(cart.CouponCode) {
+		coupon = s.couponCheckFor(cart.ID, cart.CouponCode)
+	}
+	res, err := s.orders.CheckoutOrder(ctx, orderReq, coupon)
+	if err != nil || res.Code != http.StatusOK {
+		return res, err
+	}
+	if err := s.cartDao.DeleteCart(ctx, cart.ID); err != nil {
+		// The order is placed; a leftover cart will expire on its own.
+		log.Printf("deleting checked out cart %s: %v", cart.ID, err)
+	}
+	s.forgetCouponCheck(cart.ID)
+	return res, nil
+}
+
+// ExpireCarts removes carts that have been idle for longer than the TTL along
+// with coupon checks nobody is waiting on anymore.
+func (s *CartAPIService) ExpireCarts(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-s.ttl)
+	s.mu.Lock()
+	for id, check := range s.checks {
+		if check.started.Before(cutoff) && check.status() != couponPending {
+			delete(s.checks, id)
+		}
+	}
+	s.mu.Unlock()
+	return s.cartDao.DeleteCartsIdleSince(ctx, cutoff)
+}
+
+// ExpireCartsEvery runs ExpireCarts on every tick of interval until ctx is done.
+func (s *CartAPIService) ExpireCartsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := s.ExpireCarts(ctx); err != nil {
+				log.Printf("expiring carts: %v", err)
+			} else if n > 0 {
+				log.Printf("expired %d idle carts", n)
+			}
+		}
+	}
+}
+
+// activeCart loads a cart, treating one idle past its TTL as missing even if
+// the sweeper hasn't removed it yet.
+func (s *CartAPIService) activeCart(ctx context.Context, id db.ID) (db.Cart, error) {
+	cart, err := s.cartDao.GetCart(ctx, id)
+	if err != nil {
+		return db.Cart{}, err
+	}
+	if time.Since(cart.UpdatedAt) > s.ttl {
+		if err := s.cartDao.DeleteCart(ctx, id); err != nil {
+			return db.Cart{}, err
+		}
+		s.forgetCouponCheck(id)
+		return db.Cart{}, db.ErrNotFound(sql.ErrNoRows)
+	}
+	return cart, nil
+}
+
+// currentCart reloads a cart after a write and returns its priced view.
+func (s *CartAPIService) currentCart(ctx context.Context, id db.ID) (openapi.ImplResponse, error) {
+	cart, err := s.cartDao.GetCart(ctx, id)
+	if err != nil {
+		return cartFailure(err)
+	}
+	return s.cartResponse(ctx, http.StatusOK, cart)
+}
+
+func cartFailure(err error) (openapi.ImplResponse, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.Response(http.StatusNotFound, nil), nil
+	}
+	return openapi.Response(http.StatusInternalServerError, nil), err
+}
+
+// cartResponse prices the cart against the current menu. Products removed from
+// the menu, or archived, since they were added are listed without a name or
+// price. The coupon is reported as its check stands; reading a cart never
+// starts a search.
+func (s *CartAPIService) cartResponse(ctx context.Context, code int, cart db.Cart) (openapi.ImplResponse, error) {
+	ids := make([]db.ID, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.orders.productDao.GetProductsByIDs(ctx, ids)
+	var unknown *db.UnknownProductsError
+	if err != nil && !errors.As(err, &unknown) {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+
+	res := openapi.Cart{
+		Id:         cart.ID,
+		Items:      make([]openapi.CartItem, 0, len(cart.Items)),
+		Products:   make([]openapi.Product, 0, len(cart.Items)),
+		CouponCode: cart.CouponCode,
+		ExpiresAt:  cart.UpdatedAt.Add(s.ttl).UTC(),
+	}
+	var subtotal float32
+	for _, item := range cart.Items {
+		product, ok := products[item.ProductID]
+		if !ok || !product.ArchivedAt.IsZero() {
+			product = db.Product{Id: item.ProductID}
+		}
+		res.Items = append(res.Items, openapi.CartItem{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+			Modifiers: modifierIDs(item.Modifiers),
+			Note:      item.Note,
+		})
+		res.Products = append(res.Products, openapi.Product{
+			Id:       product.Id,
+			Name:     product.Name,
+			Price:    product.Price,
+			Category: product.Category,
+			Image:    productImage(s.orders.imageBase, product.Images),
+		})
+		subtotal += cartUnitPrice(product, item.Modifiers) * float32(item.Quantity)
+	}
+	if cart.CouponCode != "" {
+		res.CouponStatus = s.couponStatus(cart.ID, cart.CouponCode)
+		if res.CouponStatus == couponValid {
+			res.Discounts = s.orders.discountFor(subtotal)
+		}
+	}
+	res.Total = roundCents(subtotal - res.Discounts)
+	return openapi.Response(code, res), nil
+}
+
+// cartUnitPrice is the price of one unit of a cart line: the product as it is
+// now, with the modifiers chosen that it still offers.
+func cartUnitPrice(product db.Product, modifiers []db.Modifier) float32 {
+	price := product.Price
+	for _, group := range product.ModifierGroups {
+		for _, option := range group.Options {
+			if slices.ContainsFunc(modifiers, func(m db.Modifier) bool { return m.ID == option.ID }) {
+				price += option.PriceDelta
+			}
+		}
+	}
+	return price
+}
+
+func modifierIDs(modifiers []db.Modifier) []string {
+	var ids []string
+	for _, modifier := range modifiers {
+		ids = append(ids, modifier.ID)
+	}
+	return ids
+}
+
+// couponStatus reports how the check of the cart's coupon stands, or
+// couponUnknown if none is running for it.
+func (s *CartAPIService) couponStatus(cartID db.ID, code string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if check, ok := s.checks[cartID]; ok && check.code == code {
+		return check.status()
+	}
+	return couponUnknown
+}
+
+// couponCheckFor returns the running check for the cart's coupon, starting a
+// new search if the cart has none, the coupon changed or the last search
+// failed, and cancelling the one it replaces. Checks live in memory, so after a restart they are started
+// again when the coupon is applied or the cart checked out.
+func (s *CartAPIService) couponCheckFor(cartID db.ID, code string) *couponCheck {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.checks[cartID]
+	if ok && prev.code == code && !prev.failed() {
+		return prev
+	} else if ok {
+		prev.cancel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), couponCheckTimeout)
+	check := &couponCheck{code: code, started: time.Now(), cancel: cancel, done: make(chan struct{})}
+	s.checks[cartID] = check
+	go func() {
+		defer close(check.done)
+		defer cancel()
+		result, err := s.orders.couponDao.SearchForCouponInGivenFiles(ctx, openapi.OrderReq{CouponCode: code})
+		if err != nil {
+			check.err = err
+			return
+		}
+		check.valid, check.err = result.Validate()
+	}()
+	return check
+}
+
+// forgetCouponCheck cancels and drops the cart's coupon check, once the coupon
+// is removed or the cart is gone.
+func (s *CartAPIService) forgetCouponCheck(cartID db.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if check, ok := s.checks[cartID]; ok {
+		check.cancel()
+		delete(s.checks, cartID)
+	}
+}
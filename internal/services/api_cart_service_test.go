@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+type cartMocks struct {
+	carts    *dbmocks.MockCartDao
+	orders   *dbmocks.MockOrderDao
+	products *dbmocks.MockProductDao
+	coupons  *dbmocks.MockCouponDao
+}
+
+func newTestCartService(ctrl *gomock.Controller) (*CartAPIService, cartMocks) {
+	m := cartMocks{
+		carts:    dbmocks.NewMockCartDao(ctrl),
+		orders:   dbmocks.NewMockOrderDao(ctrl),
+		products: dbmocks.NewMockProductDao(ctrl),
+		coupons:  dbmocks.NewMockCouponDao(ctrl),
+	}
+	orders := NewOrderAPIServiceWithCouponDao(m.orders, m.products, m.coupons, dbmocks.NewMockQuoteDao(ctrl), WithCouponDiscount(10))
+	return NewCartAPIService(m.carts, orders, time.Hour), m
+}
+
+func TestCartAPIService_GetCart(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(m cartMocks)
+		wantCode   int
+		wantTotal  float32
+		wantCoupon string
+	}{
+		{
+			name: "cart not found",
+			setupMocks: func(m cartMocks) {
+				m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(db.Cart{}, db.ErrNotFound(sql.ErrNoRows))
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "idle cart has expired",
+			setupMocks: func(m cartMocks) {
+				m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(db.Cart{ID: "cart-1", UpdatedAt: time.Now().Add(-2 * time.Hour)}, nil)
+				m.carts.EXPECT().DeleteCart(gomock.Any(), db.ID("cart-1")).Return(nil)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "cart is priced from the menu",
+			setupMocks: func(m cartMocks) {
+				m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(db.Cart{
+					ID:        "cart-1",
+					Items:     []db.Item{{ProductID: "1", Quantity: 2}},
+					UpdatedAt: time.Now(),
+				}, nil)
+				m.products.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{
+					"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"},
+				}, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantTotal: 13,
+		},
+		{
+			name: "coupon nobody is checking isn't searched for",
+			setupMocks: func(m cartMocks) {
+				m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(db.Cart{
+					ID:         "cart-1",
+					Items:      []db.Item{{ProductID: "1", Quantity: 2}},
+					CouponCode: "HAPPYHRS",
+					UpdatedAt:  time.Now(),
+				}, nil)
+				m.products.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{
+					"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"},
+				}, nil)
+			},
+			wantCode:   http.StatusOK,
+			wantTotal:  13,
+			wantCoupon: couponUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newTestCartService(ctrl)
+			tt.setupMocks(m)
+			res, err := svc.GetCart(context.Background(), "cart-1")
+			if err != nil {
+				t.Fatalf("Service error: %v", err)
+			}
+			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantTotal, res.Body.(openapi.Cart).Total)
+				assert.Equal(t, tt.wantCoupon, res.Body.(openapi.Cart).CouponStatus)
+			}
+		})
+	}
+}
+
+func TestCartAPIService_SetCartItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newTestCartService(ctrl)
+	cart := db.Cart{ID: "cart-1", UpdatedAt: time.Now()}
+
+	res, err := svc.SetCartItem(context.Background(), "cart-1", "1", openapi.CartItemReq{Quantity: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(cart, nil)
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"bad"}).Return(map[db.ID]db.Product{}, &db.UnknownProductsError{IDs: []db.ID{"bad"}})
+	res, err = svc.SetCartItem(context.Background(), "cart-1", "bad", openapi.CartItemReq{Quantity: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	waffle := map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(cart, nil)
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(waffle, nil).Times(2)
+	m.carts.EXPECT().SetCartItem(gomock.Any(), db.ID("cart-1"), db.Item{ProductID: "1", Quantity: 3}).Return(nil)
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(db.Cart{ID: "cart-1", Items: []db.Item{{ProductID: "1", Quantity: 3}}, UpdatedAt: time.Now()}, nil)
+	res, err = svc.SetCartItem(context.Background(), "cart-1", "1", openapi.CartItemReq{Quantity: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, []openapi.CartItem{{ProductId: "1", Quantity: 3}}, res.Body.(openapi.Cart).Items)
+}
+
+func TestCartAPIService_ModifiersAndNotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newTestCartService(ctrl)
+	ctx := context.Background()
+	size := db.ModifierGroup{ID: "size", Name: "Size", Required: true, MaxSelect: 1, Options: []db.Modifier{
+		{ID: "small", GroupID: "size", Name: "Small"},
+		{ID: "large", GroupID: "size", Name: "Large", PriceDelta: 1.5},
+	}}
+	waffle := map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle", ModifierGroups: []db.ModifierGroup{size}}}
+	line := db.Item{ProductID: "1", Quantity: 2, Modifiers: []db.Modifier{{ID: "large"}}, Note: "no nuts"}
+	cart := db.Cart{ID: "cart-1", Items: []db.Item{line}, CouponCode: "HAPPYHRS", UpdatedAt: time.Now()}
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(cart, nil).AnyTimes()
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(waffle, nil).AnyTimes()
+
+	// A required choice left out is turned down when the line is set, not at checkout.
+	res, err := svc.SetCartItem(ctx, "cart-1", "1", openapi.CartItemReq{Quantity: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	m.carts.EXPECT().SetCartItem(gomock.Any(), db.ID("cart-1"), line).Return(nil)
+	res, err = svc.SetCartItem(ctx, "cart-1", "1", openapi.CartItemReq{Quantity: 2, Modifiers: []string{"large"}, Note: " no nuts "})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	body := res.Body.(openapi.Cart)
+	assert.Equal(t, []openapi.CartItem{{ProductId: "1", Quantity: 2, Modifiers: []string{"large"}, Note: "no nuts"}}, body.Items)
+	assert.Equal(t, float32(16), body.Total, "modifiers are priced")
+
+	searchResult := dbmocks.NewMockSearchResult(ctrl)
+	searchResult.EXPECT().Validate().Return(true, nil)
+	m.coupons.EXPECT().SearchForCouponInGivenFiles(gomock.Any(), openapi.OrderReq{CouponCode: "HAPPYHRS"}).Return(searchResult, nil)
+	m.orders.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		if assert.Len(t, order.Items, 1) {
+			assert.Equal(t, []db.Modifier{size.Options[1]}, order.Items[0].Modifiers)
+			assert.Equal(t, "no nuts", order.Items[0].Note)
+		}
+		return nil
+	})
+	m.carts.EXPECT().DeleteCart(gomock.Any(), db.ID("cart-1")).Return(nil)
+	res, err = svc.CheckoutCart(ctx, "cart-1", openapi.Fulfilment{Type: db.FulfilmentDineIn, TableNumber: "12"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, float32(14.4), res.Body.(openapi.Order).Total)
+}
+
+func TestCartAPIService_ApplyCouponAndCheckout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newTestCartService(ctrl)
+	ctx := context.Background()
+	cart := db.Cart{ID: "cart-1", Items: []db.Item{{ProductID: "1", Quantity: 2}}, UpdatedAt: time.Now()}
+	withCoupon := cart
+	withCoupon.CouponCode = "HAPPYHRS"
+	waffle := map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}
+
+	res, err := svc.ApplyCartCoupon(ctx, "cart-1", openapi.CartCouponReq{CouponCode: "SHORT"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	// The coupon files are searched once, when the coupon is applied.
+	searchResult := dbmocks.NewMockSearchResult(ctrl)
+	searchResult.EXPECT().Validate().Return(true, nil)
+	m.coupons.EXPECT().SearchForCouponInGivenFiles(gomock.Any(), openapi.OrderReq{CouponCode: "HAPPYHRS"}).Return(searchResult, nil).Times(1)
+
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(cart, nil)
+	m.carts.EXPECT().SetCartCoupon(gomock.Any(), db.ID("cart-1"), "HAPPYHRS").Return(nil)
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(withCoupon, nil)
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(waffle, nil)
+	res, err = svc.ApplyCartCoupon(ctx, "cart-1", openapi.CartCouponReq{CouponCode: "HAPPYHRS"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, []string{couponPending, couponValid}, res.Body.(openapi.Cart).CouponStatus)
+
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(withCoupon, nil)
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(waffle, nil)
+	m.orders.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		assert.Equal(t, "HAPPYHRS", order.CouponCode)
+		assert.Equal(t, float32(1.3), order.Discounts)
+		assert.Equal(t, &db.Fulfilment{Type: db.FulfilmentDineIn, TableNumber: "12"}, order.Fulfilment)
+		return nil
+	})
+	m.carts.EXPECT().DeleteCart(gomock.Any(), db.ID("cart-1")).Return(nil)
+	res, err = svc.CheckoutCart(ctx, "cart-1", openapi.Fulfilment{Type: db.FulfilmentDineIn, TableNumber: "12"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, float32(11.7), res.Body.(openapi.Order).Total)
+}
+
+func TestCartAPIService_CouponChecksAreCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newTestCartService(ctrl)
+	ctx := context.Background()
+	cart := db.Cart{ID: "cart-1", UpdatedAt: time.Now()}
+
+	// Each search runs until it is cancelled.
+	cancelled := make(chan string, 2)
+	m.coupons.EXPECT().SearchForCouponInGivenFiles(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req openapi.OrderReq) (db.SearchResult, error) {
+			<-ctx.Done()
+			cancelled <- req.CouponCode
+			return nil, ctx.Err()
+		}).Times(2)
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(cart, nil).AnyTimes()
+	m.carts.EXPECT().SetCartCoupon(gomock.Any(), db.ID("cart-1"), gomock.Any()).Return(nil).Times(3)
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), gomock.Any()).Return(map[db.ID]db.Product{}, nil).AnyTimes()
+
+	waitCancelled := func(code string) {
+		t.Helper()
+		select {
+		case got := <-cancelled:
+			assert.Equal(t, code, got)
+		case <-time.After(time.Second):
+			t.Fatalf("search for %s wasn't cancelled", code)
+		}
+	}
+	_, err := svc.ApplyCartCoupon(ctx, "cart-1", openapi.CartCouponReq{CouponCode: "HAPPYHRS"})
+	assert.NoError(t, err)
+	_, err = svc.ApplyCartCoupon(ctx, "cart-1", openapi.CartCouponReq{CouponCode: "FIFTYOFF"})
+	assert.NoError(t, err)
+	waitCancelled("HAPPYHRS")
+	_, err = svc.RemoveCartCoupon(ctx, "cart-1")
+	assert.NoError(t, err)
+	waitCancelled("FIFTYOFF")
+}
+
+func TestCartAPIService_FailedCouponChecksAreRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newTestCartService(ctrl)
+	ctx := context.Background()
+	found := dbmocks.NewMockSearchResult(ctrl)
+	found.EXPECT().Validate().Return(true, nil)
+	gomock.InOrder(
+		m.coupons.EXPECT().SearchForCouponInGivenFiles(gomock.Any(), gomock.Any()).Return(nil, errors.New("couponbase unreadable")),
+		m.coupons.EXPECT().SearchForCouponInGivenFiles(gomock.Any(), gomock.Any()).Return(found, nil),
+	)
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(db.Cart{ID: "cart-1", UpdatedAt: time.Now()}, nil).AnyTimes()
+	m.carts.EXPECT().SetCartCoupon(gomock.Any(), db.ID("cart-1"), "HAPPYHRS").Return(nil).Times(2)
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), gomock.Any()).Return(map[db.ID]db.Product{}, nil).AnyTimes()
+
+	_, err := svc.ApplyCartCoupon(ctx, "cart-1", openapi.CartCouponReq{CouponCode: "HAPPYHRS"})
+	assert.NoError(t, err)
+	_, err = svc.couponCheckFor("cart-1", "HAPPYHRS").Validate()
+	assert.Error(t, err)
+	_, err = svc.ApplyCartCoupon(ctx, "cart-1", openapi.CartCouponReq{CouponCode: "HAPPYHRS"})
+	assert.NoError(t, err)
+	valid, err := svc.couponCheckFor("cart-1", "HAPPYHRS").Validate()
+	assert.NoError(t, err)
+	assert.True(t, valid, "applying the coupon again searches again")
+}
+
+func TestCartRoutesRequireAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newTestCartService(ctrl)
+	m.carts.EXPECT().CreateCart(gomock.Any(), gomock.Any()).Return(nil)
+	m.products.EXPECT().GetProductsByIDs(gomock.Any(), gomock.Any()).Return(map[db.ID]db.Product{}, nil)
+	router := openapi.NewRouter(openapi.NewCartAPIController(svc))
+	router.Use(RequireAPIKey(KeysByScope(nil, map[string][]string{"create_order": {"shop"}, "kitchen": {"tablet"}})))
+
+	for _, key := range []string{"", "tablet"} {
+		assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, http.MethodPost, "/api/cart", key, ""), "create with key %q", key)
+		assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, http.MethodPost, "/api/cart/cart-1/checkout", key, "{}"), "checkout with key %q", key)
+	}
+	assert.Equal(t, http.StatusCreated, serveWithKey(router, http.MethodPost, "/api/cart", "shop", ""))
+}
+
+func TestCartAPIService_CheckoutEmptyCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newTestCartService(ctrl)
+	m.carts.EXPECT().GetCart(gomock.Any(), db.ID("cart-1")).Return(db.Cart{ID: "cart-1", UpdatedAt: time.Now()}, nil)
+	res, err := svc.CheckoutCart(context.Background(), "cart-1", openapi.Fulfilment{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+// CategoryAPIService implements business logic for the CategoryAPI defined by the generated OpenAPI.
+// It lists the visible categories of the menu from a `db.CategoryDao`, and
+// the products in them the way the ProductAPIService lists products.
+type CategoryAPIService struct {
+	categoryDao db.CategoryDao
+	products    *ProductAPIService
+}
+
+// NewCategoryAPIService creates a default api service
+func NewCategoryAPIService(categoryDao db.CategoryDao, products *ProductAPIService) *CategoryAPIService {
+	return &CategoryAPIService{categoryDao: categoryDao, products: products}
+}
+
+// ListCategories - List categories
+func (s *CategoryAPIService) ListCategories(ctx context.Context) (openapi.ImplResponse, error) {
+	categories, err := s.categoryDao.GetCategories(ctx, false)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res := make([]openapi.Category, 0, len(categories))
+	for _, c := range categories {
+		res = append(res, openapi.Category{Slug: c.Slug, Name: c.Name, Position: c.Position, Parent: c.ParentSlug})
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
+
+// ListCategoryProducts - List the products of a category
+func (s *CategoryAPIService) ListCategoryProducts(ctx context.Context, slug string, available bool, sort string, limit int32, cursor string) (openapi.ImplResponse, error) {
+	category, err := s.categoryDao.GetCategory(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.Response(http.StatusNotFound, nil), nil
+	} else if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	if !category.Visible {
+		return openapi.Response(http.StatusNotFound, nil), nil
+	}
+	return s.products.findProducts(ctx, db.ProductFilter{
+		Category:  category.Slug,
+		Available: available,
+		Sort:      sort,
+		Limit:     int(limit),
+		Cursor:    cursor,
+	})
+}
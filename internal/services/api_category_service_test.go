@@ -0,0 +1,62 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+func TestCategoryAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cd := dbmocks.NewMockCategoryDao(ctrl)
+	pd := dbmocks.NewMockProductDao(ctrl)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), nil).Return(map[db.ID]db.Stock{}, nil).AnyTimes()
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil).AnyTimes()
+	svc := NewCategoryAPIService(cd, NewProductAPIService(pd, sd, id, "", nil))
+	router := openapi.NewRouter(openapi.NewCategoryAPIController(svc))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	cd.EXPECT().GetCategories(gomock.Any(), false).Return([]db.Category{
+		{ID: 1, Slug: "desserts", Name: "Desserts", Position: 1, Visible: true},
+		{ID: 2, Slug: "waffle", Name: "Waffle", Position: 2, ParentSlug: "desserts", Visible: true},
+	}, nil)
+	rec := serve("/api/category")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var categories []openapi.Category
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &categories))
+	assert.Equal(t, []openapi.Category{
+		{Slug: "desserts", Name: "Desserts", Position: 1},
+		{Slug: "waffle", Name: "Waffle", Position: 2, Parent: "desserts"},
+	}, categories)
+
+	cd.EXPECT().GetCategory(gomock.Any(), "desserts").Return(db.Category{ID: 1, Slug: "desserts", Visible: true}, nil)
+	pd.EXPECT().FindProducts(gomock.Any(), db.ProductFilter{Category: "desserts", Sort: db.SortByMenu, Limit: 1}).
+		Return([]db.Product{{Id: "1", Name: "Waffle with Berries", Category: "Waffle", CategorySlug: "waffle"}}, "next", nil)
+	rec = serve("/api/category/desserts/product?limit=1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "next", rec.Header().Get("X-Next-Cursor"))
+	assert.Contains(t, rec.Body.String(), `"categorySlug":"waffle"`)
+
+	cd.EXPECT().GetCategory(gomock.Any(), "drinks").Return(db.Category{ID: 3, Slug: "drinks"}, nil)
+	assert.Equal(t, http.StatusNotFound, serve("/api/category/drinks/product").Code, "hidden categories")
+	cd.EXPECT().GetCategory(gomock.Any(), "cakes").Return(db.Category{}, db.ErrNotFound(sql.ErrNoRows))
+	assert.Equal(t, http.StatusNotFound, serve("/api/category/cakes/product").Code)
+	cd.EXPECT().GetCategory(gomock.Any(), "desserts").Return(db.Category{ID: 1, Slug: "desserts", Visible: true}, nil)
+	assert.Equal(t, http.StatusBadRequest, serve("/api/category/desserts/product?sort=colour").Code)
+}
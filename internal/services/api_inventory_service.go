@@ -0,0 +1,253 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// InventoryAPIService implements business logic for the InventoryAPI defined by the generated OpenAPI.
+// It lets staff set and adjust stock through `db.StockDao`, and ingredients and
+// recipes through `db.IngredientDao`; orders take stock and ingredients
+// themselves when they are stored.
+type InventoryAPIService struct {
+	stockDao      db.StockDao
+	ingredientDao db.IngredientDao
+}
+
+// NewInventoryAPIService creates a default api service
+func NewInventoryAPIService(stockDao db.StockDao, ingredientDao db.IngredientDao) *InventoryAPIService {
+	return &InventoryAPIService{stockDao: stockDao, ingredientDao: ingredientDao}
+}
+
+// ListStock - List stock levels
+func (s *InventoryAPIService) ListStock(ctx context.Context) (openapi.ImplResponse, error) {
+	stock, err := s.stockDao.GetStock(ctx, nil)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	levels := make([]openapi.StockLevel, 0, len(stock))
+	for _, level := range stock {
+		levels = append(levels, stockResponse(level))
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].ProductId < levels[j].ProductId })
+	return openapi.Response(http.StatusOK, levels), nil
+}
+
+// SetStock - Set stock
+func (s *InventoryAPIService) SetStock(ctx context.Context, productId string, stockReq openapi.StockReq) (openapi.ImplResponse, error) {
+	stock, err := s.stockDao.SetStock(ctx, db.Stock{ProductID: productId, OnHand: stockReq.OnHand, LowThreshold: stockReq.LowStockThreshold})
+	if err != nil {
+		return stockFailure(err)
+	}
+	return openapi.Response(http.StatusOK, stockResponse(stock)), nil
+}
+
+// AdjustStock - Adjust stock
+func (s *InventoryAPIService) AdjustStock(ctx context.Context, productId string, stockAdjustReq openapi.StockAdjustReq) (openapi.ImplResponse, error) {
+	stock, err := s.stockDao.AdjustStock(ctx, productId, stockAdjustReq.Delta)
+	if err != nil {
+		return stockFailure(err)
+	}
+	return openapi.Response(http.StatusOK, stockResponse(stock)), nil
+}
+
+// ListIngredients - List ingredients
+func (s *InventoryAPIService) ListIngredients(ctx context.Context) (openapi.ImplResponse, error) {
+	ingredients, err := s.ingredientDao.GetIngredients(ctx)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res := make([]openapi.Ingredient, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		res = append(res, ingredientResponse(ingredient))
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
+
+// ForecastIngredients - Forecast ingredient run-out
+func (s *InventoryAPIService) ForecastIngredients(ctx context.Context, window int32) (openapi.ImplResponse, error) {
+	now := time.Now().UTC()
+	period := time.Duration(window) * time.Minute
+	ingredients, err := s.ingredientDao.GetIngredients(ctx)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	usage, err := s.ingredientDao.GetIngredientUsage(ctx, now.Add(-period))
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	return openapi.Response(http.StatusOK, forecast(ingredients, usage, period, now)), nil
+}
+
+// SetIngredient - Set an ingredient
+func (s *InventoryAPIService) SetIngredient(ctx context.Context, ingredientId string, ingredientReq openapi.IngredientReq) (openapi.ImplResponse, error) {
+	ingredient, err := s.ingredientDao.SetIngredient(ctx, db.Ingredient{
+		ID:     ingredientId,
+		Name:   ingredientReq.Name,
+		Unit:   ingredientReq.Unit,
+		OnHand: ingredientReq.OnHand,
+	})
+	if err != nil {
+		return stockFailure(err)
+	}
+	return openapi.Response(http.StatusOK, ingredientResponse(ingredient)), nil
+}
+
+// AdjustIngredient - Adjust an ingredient
+func (s *InventoryAPIService) AdjustIngredient(ctx context.Context, ingredientId string, ingredientAdjustReq openapi.IngredientAdjustReq) (openapi.ImplResponse, error) {
+	ingredient, err := s.ingredientDao.AdjustIngredient(ctx, ingredientId, ingredientAdjustReq.Delta)
+	if err != nil {
+		return stockFailure(err)
+	}
+	return openapi.Response(http.StatusOK, ingredientResponse(ingredient)), nil
+}
+
+// GetRecipe - Get a recipe
+func (s *InventoryAPIService) GetRecipe(ctx context.Context, productId string) (openapi.ImplResponse, error) {
+	recipe, err := s.ingredientDao.GetRecipe(ctx, productId)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	return openapi.Response(http.StatusOK, recipeResponse(recipe)), nil
+}
+
+// SetRecipe - Set a recipe
+func (s *InventoryAPIService) SetRecipe(ctx context.Context, productId string, recipe openapi.Recipe) (openapi.ImplResponse, error) {
+	r := db.Recipe{ProductID: productId, Ingredients: recipeLines(recipe.Ingredients)}
+	if len(recipe.Modifiers) > 0 {
+		r.Modifiers = make(map[db.ID][]db.RecipeLine, len(recipe.Modifiers))
+	}
+	for _, modifier := range recipe.Modifiers {
+		r.Modifiers[modifier.ModifierId] = append(r.Modifiers[modifier.ModifierId], recipeLines(modifier.Ingredients)...)
+	}
+	if !positiveQuantities(r) {
+		return openapi.Response(http.StatusBadRequest, "quantities must be positive"), nil
+	}
+	if err := s.ingredientDao.SetRecipe(ctx, r); err != nil {
+		var recipeErr *db.RecipeError
+		if errors.As(err, &recipeErr) {
+			return openapi.Response(http.StatusBadRequest, err.Error()), nil
+		}
+		return stockFailure(err)
+	}
+	return openapi.Response(http.StatusOK, recipeResponse(r)), nil
+}
+
+// stockFailure answers a failed change to stock or ingredients.
+func stockFailure(err error) (openapi.ImplResponse, error) {
+	var outOfStock *db.OutOfStockError
+	var shortage *db.IngredientShortageError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return openapi.Response(http.StatusNotFound, nil), nil
+	case errors.As(err, &outOfStock), errors.As(err, &shortage):
+		return openapi.Response(http.StatusConflict, err.Error()), nil
+	}
+	return openapi.Response(http.StatusInternalServerError, nil), err
+}
+
+func stockResponse(stock db.Stock) openapi.StockLevel {
+	return openapi.StockLevel{
+		ProductId:         stock.ProductID,
+		OnHand:            stock.OnHand,
+		LowStockThreshold: stock.LowThreshold,
+		Low:               stock.Low(),
+		UpdatedAt:         stock.UpdatedAt.UTC(),
+	}
+}
+
+func ingredientResponse(ingredient db.Ingredient) openapi.Ingredient {
+	return openapi.Ingredient{
+		Id:        ingredient.ID,
+		Name:      ingredient.Name,
+		Unit:      ingredient.Unit,
+		OnHand:    ingredient.OnHand,
+		UpdatedAt: ingredient.UpdatedAt.UTC(),
+	}
+}
+
+func recipeResponse(recipe db.Recipe) openapi.Recipe {
+	res := openapi.Recipe{ProductId: recipe.ProductID, Ingredients: recipeLineResponses(recipe.Ingredients)}
+	for modifierID, lines := range recipe.Modifiers {
+		res.Modifiers = append(res.Modifiers, openapi.ModifierRecipe{ModifierId: modifierID, Ingredients: recipeLineResponses(lines)})
+	}
+	sort.Slice(res.Modifiers, func(i, j int) bool { return res.Modifiers[i].ModifierId < res.Modifiers[j].ModifierId })
+	return res
+}
+
+func recipeLineResponses(lines []db.RecipeLine) []openapi.RecipeLine {
+	res := make([]openapi.RecipeLine, 0, len(lines))
+	for _, line := range lines {
+		res = append(res, openapi.RecipeLine{IngredientId: line.IngredientID, Quantity: line.Quantity})
+	}
+	return res
+}
+
+func recipeLines(lines []openapi.RecipeLine) []db.RecipeLine {
+	res := make([]db.RecipeLine, 0, len(lines))
+	for _, line := range lines {
+		res = append(res, db.RecipeLine{IngredientID: line.IngredientId, Quantity: line.Quantity})
+	}
+	return res
+}
+
+func positiveQuantities(recipe db.Recipe) bool {
+	for _, line := range recipe.Ingredients {
+		if line.Quantity <= 0 {
+			return false
+		}
+	}
+	for _, lines := range recipe.Modifiers {
+		for _, line := range lines {
+			if line.Quantity <= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// forecast projects when each ingredient runs out if orders keep using it as
+// they did over the last period. Ingredients that run out soonest come first;
+// those nothing has used come last, by ID, with no projection.
+func forecast(ingredients []db.Ingredient, usage map[db.ID]float64, period time.Duration, now time.Time) []openapi.IngredientForecast {
+	res := make([]openapi.IngredientForecast, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		f := openapi.IngredientForecast{
+			IngredientId: ingredient.ID,
+			Name:         ingredient.Name,
+			Unit:         ingredient.Unit,
+			OnHand:       ingredient.OnHand,
+		}
+		if used := usage[ingredient.ID]; used > 0 {
+			f.UsedPerHour = used / period.Hours()
+			f.HoursLeft = ingredient.OnHand / f.UsedPerHour
+			f.RunOutAt = now.Add(time.Duration(f.HoursLeft * float64(time.Hour)))
+		}
+		res = append(res, f)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if (a.UsedPerHour > 0) != (b.UsedPerHour > 0) {
+			return a.UsedPerHour > 0
+		}
+		if a.UsedPerHour > 0 && a.HoursLeft != b.HoursLeft {
+			return a.HoursLeft < b.HoursLeft
+		}
+		return a.IngredientId < b.IngredientId
+	})
+	return res
+}
+
+// available reports whether a product can be ordered: it isn't tracked, or
+// some of it is left, and no ingredient of its recipe has run short.
+func available(stock map[db.ID]db.Stock, short map[db.ID]bool, productID db.ID) bool {
+	level, tracked := stock[productID]
+	return (!tracked || level.OnHand > 0) && !short[productID]
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+func TestInventoryAPIService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	updated := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), nil).Return(map[db.ID]db.Stock{
+		"2": {ProductID: "2", OnHand: 3, LowThreshold: 5, UpdatedAt: updated},
+		"1": {ProductID: "1", OnHand: 20, LowThreshold: 5, UpdatedAt: updated},
+	}, nil)
+	sd.EXPECT().SetStock(gomock.Any(), db.Stock{ProductID: "1", OnHand: 12, LowThreshold: 4}).
+		Return(db.Stock{ProductID: "1", OnHand: 12, LowThreshold: 4, UpdatedAt: updated}, nil)
+	sd.EXPECT().SetStock(gomock.Any(), db.Stock{ProductID: "99", OnHand: 1}).Return(db.Stock{}, db.ErrNotFound(sql.ErrNoRows))
+	sd.EXPECT().AdjustStock(gomock.Any(), db.ID("1"), int32(-20)).Return(db.Stock{}, &db.OutOfStockError{ProductID: "1", OnHand: 12})
+	sd.EXPECT().AdjustStock(gomock.Any(), db.ID("3"), int32(5)).Return(db.Stock{}, db.ErrNotFound(sql.ErrNoRows))
+	svc := NewInventoryAPIService(sd, dbmocks.NewMockIngredientDao(ctrl))
+	ctx := context.Background()
+
+	res, err := svc.ListStock(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []openapi.StockLevel{
+		{ProductId: "1", OnHand: 20, LowStockThreshold: 5, UpdatedAt: updated},
+		{ProductId: "2", OnHand: 3, LowStockThreshold: 5, Low: true, UpdatedAt: updated},
+	}, res.Body)
+
+	res, err = svc.SetStock(ctx, "1", openapi.StockReq{OnHand: 12, LowStockThreshold: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, openapi.StockLevel{ProductId: "1", OnHand: 12, LowStockThreshold: 4, UpdatedAt: updated}, res.Body)
+
+	res, err = svc.SetStock(ctx, "99", openapi.StockReq{OnHand: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	res, err = svc.AdjustStock(ctx, "1", openapi.StockAdjustReq{Delta: -20})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Equal(t, "only 12 of product 1 left", res.Body)
+
+	res, err = svc.AdjustStock(ctx, "3", openapi.StockAdjustReq{Delta: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestInventoryRoutesRequireAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), nil).Return(nil, nil)
+	router := openapi.NewRouter(openapi.NewInventoryAPIController(NewInventoryAPIService(sd, dbmocks.NewMockIngredientDao(ctrl))))
+	router.Use(RequireAPIKey(KeysByScope(nil, map[string][]string{"manage_inventory": {"stockroom"}, "kitchen": {"tablet"}})))
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/api/inventory"},
+		{http.MethodPut, "/api/inventory/1"},
+		{http.MethodPost, "/api/inventory/1/adjust"},
+		{http.MethodGet, "/api/inventory/ingredient"},
+		{http.MethodGet, "/api/inventory/ingredient/forecast"},
+		{http.MethodPut, "/api/inventory/ingredient/flour"},
+		{http.MethodPost, "/api/inventory/ingredient/flour/adjust"},
+		{http.MethodGet, "/api/inventory/recipe/1"},
+		{http.MethodPut, "/api/inventory/recipe/1"},
+	} {
+		assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, route.method, route.path, "", "{}"), "%s %s", route.method, route.path)
+		assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, route.method, route.path, "tablet", "{}"), "%s %s", route.method, route.path)
+	}
+	assert.Equal(t, http.StatusOK, serveWithKey(router, http.MethodGet, "/api/inventory", "stockroom", ""))
+}
+
+func TestPlaceOrderOutOfStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, nil)
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(&db.OutOfStockError{ProductID: "1"})
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl))
+
+	res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Equal(t, "product 1 is out of stock", res.Body)
+}
+
+func TestPlaceOrderIngredientShortage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, nil)
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(&db.IngredientShortageError{IngredientID: "batter", Name: "Waffle batter"})
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl))
+
+	res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Equal(t, "not enough Waffle batter left", res.Body)
+}
+
+func TestForecastIngredients(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ingredients := []db.Ingredient{
+		{ID: "sugar", Name: "Sugar", Unit: "g", OnHand: 2000},
+		{ID: "cream", Name: "Cream", Unit: "ml", OnHand: 300},
+		{ID: "batter", Name: "Waffle batter", Unit: "ml", OnHand: 4500},
+		{ID: "berries", Name: "Berries", Unit: "g", OnHand: 0},
+	}
+	// Over half an hour orders used 450ml of batter and 300ml of cream.
+	got := forecast(ingredients, map[db.ID]float64{"batter": 450, "cream": 300}, 30*time.Minute, now)
+	assert.Equal(t, []openapi.IngredientForecast{
+		{IngredientId: "cream", Name: "Cream", Unit: "ml", OnHand: 300, UsedPerHour: 600, HoursLeft: 0.5, RunOutAt: now.Add(30 * time.Minute)},
+		{IngredientId: "batter", Name: "Waffle batter", Unit: "ml", OnHand: 4500, UsedPerHour: 900, HoursLeft: 5, RunOutAt: now.Add(5 * time.Hour)},
+		{IngredientId: "berries", Name: "Berries", Unit: "g", OnHand: 0},
+		{IngredientId: "sugar", Name: "Sugar", Unit: "g", OnHand: 2000},
+	}, got)
+}
+
+func TestRecipeAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	recipe := db.Recipe{
+		ProductID:   "1",
+		Ingredients: []db.RecipeLine{{IngredientID: "batter", Quantity: 150}},
+		Modifiers:   map[db.ID][]db.RecipeLine{"cream": {{IngredientID: "cream", Quantity: 40}}},
+	}
+	id.EXPECT().SetRecipe(gomock.Any(), recipe).Return(nil)
+	id.EXPECT().SetRecipe(gomock.Any(), db.Recipe{ProductID: "1", Ingredients: []db.RecipeLine{{IngredientID: "flour", Quantity: 1}}}).
+		Return(&db.RecipeError{Reason: "unknown ingredient flour"})
+	id.EXPECT().SetRecipe(gomock.Any(), db.Recipe{ProductID: "99", Ingredients: []db.RecipeLine{}}).Return(db.ErrNotFound(sql.ErrNoRows))
+	id.EXPECT().GetRecipe(gomock.Any(), db.ID("1")).Return(recipe, nil)
+	svc := NewInventoryAPIService(dbmocks.NewMockStockDao(ctrl), id)
+	ctx := context.Background()
+
+	body := openapi.Recipe{
+		Ingredients: []openapi.RecipeLine{{IngredientId: "batter", Quantity: 150}},
+		Modifiers:   []openapi.ModifierRecipe{{ModifierId: "cream", Ingredients: []openapi.RecipeLine{{IngredientId: "cream", Quantity: 40}}}},
+	}
+	res, err := svc.SetRecipe(ctx, "1", body)
+	assert.NoError(t, err)
+	body.ProductId = "1"
+	assert.Equal(t, body, res.Body)
+
+	res, err = svc.GetRecipe(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, body, res.Body)
+
+	res, err = svc.SetRecipe(ctx, "1", openapi.Recipe{Ingredients: []openapi.RecipeLine{{IngredientId: "flour", Quantity: 1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "invalid recipe: unknown ingredient flour", res.Body)
+
+	res, err = svc.SetRecipe(ctx, "1", openapi.Recipe{Ingredients: []openapi.RecipeLine{{IngredientId: "batter", Quantity: -1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	res, err = svc.SetRecipe(ctx, "99", openapi.Recipe{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/assert/yaml"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+// serveWithKey sends router a request with key in its api_key header, if
+// there is one, and returns the status it answers with.
+func serveWithKey(router http.Handler, method, path, key, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("api_key", key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestSecuredRoutesMatchSpec(t *testing.T) {
+	data, err := os.ReadFile("../generated/api/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Paths map[string]map[string]struct {
+			OperationID string                `yaml:"operationId"`
+			Security    []map[string][]string `yaml:"security"`
+		} `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	secured := make(map[string]string)
+	for _, operations := range spec.Paths {
+		for _, op := range operations {
+			for _, requirement := range op.Security {
+				for _, scope := range requirement["api_key"] {
+					secured[strings.ToUpper(op.OperationID[:1])+op.OperationID[1:]] = scope
+				}
+			}
+		}
+	}
+	assert.Equal(t, secured, SecuredRoutes)
+}
+
+func TestRequireAPIKey_AllSecuredRoutes(t *testing.T) {
+	// Requests without the right key never reach the services, so there are
+	// none behind the controllers.
+	routers := []openapi.Router{
+		NewOrderStreamController(nil, 0),
+		openapi.NewProductAPIController(nil),
+		openapi.NewOrderAPIController(nil),
+		openapi.NewCartAPIController(nil),
+		openapi.NewWebhookAPIController(nil),
+		openapi.NewInventoryAPIController(nil),
+		openapi.NewKitchenAPIController(nil),
+	}
+	router := openapi.NewRouter(routers...)
+	router.Use(RequireAPIKey(KeysByScope([]string{"admin"}, map[string][]string{"kitchen": {"tablet"}})))
+
+	param := regexp.MustCompile(`\{[^}]+\}`)
+	seen := 0
+	for _, api := range routers {
+		for _, route := range api.OrderedRoutes() {
+			scope, ok := SecuredRoutes[route.Name]
+			if !ok {
+				continue
+			}
+			seen++
+			path := param.ReplaceAllString(route.Pattern, "1")
+			for _, key := range []string{"", "guess", "tablet"} {
+				if key == "tablet" && scope == "kitchen" {
+					continue
+				}
+				assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, route.Method, path, key, ""), "%s with key %q", route.Name, key)
+			}
+		}
+	}
+	assert.Equal(t, len(SecuredRoutes), seen, "every secured route is served")
+}
+
+func TestRequireAPIKey_CancelOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	order := db.Order{ID: "order-1", Status: db.OrderAccepted, Items: []db.Item{{ProductID: "1", Name: "Margherita", Quantity: 1, UnitPrice: 6}}}
+	oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(order, nil)
+	oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return(nil, nil).Times(2)
+	oc.EXPECT().CancelOrder(gomock.Any(), db.ID("order-1"), "cold food", cancellableStatuses, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ db.ID, _ string, _ []string, refund *db.Refund) error {
+			assert.Equal(t, keyHolder("till"), refund.Operator, "the operator is the key's holder, not the body's")
+			assert.NotContains(t, refund.Operator, "till", "keys aren't written down")
+			return nil
+		})
+	cancelled := order
+	cancelled.Status = db.OrderCancelled
+	oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(cancelled, nil)
+	svc := NewOrderAPIServiceWithCouponDao(oc, dbmocks.NewMockProductDao(ctrl), &testCouponDao{}, dbmocks.NewMockQuoteDao(ctrl))
+	router := openapi.NewRouter(openapi.NewOrderAPIController(svc))
+	router.Use(RequireAPIKey(KeysByScope(nil, map[string][]string{"refund_order": {"till"}, "create_order": {"shop"}})))
+
+	body := `{"reason": "cold food", "operator": "jane"}`
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, http.MethodPost, "/api/order/order-1/cancel", "", body))
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, http.MethodPost, "/api/order/order-1/cancel", "shop", body))
+	assert.Equal(t, http.StatusOK, serveWithKey(router, http.MethodPost, "/api/order/order-1/cancel", "till", body))
+}
+
+func TestKeysByScope(t *testing.T) {
+	keys := KeysByScope([]string{"admin"}, map[string][]string{"kitchen": {"tablet"}})
+	assert.Equal(t, []string{"tablet", "admin"}, keys["kitchen"])
+	assert.Equal(t, []string{"admin"}, keys["refund_order"])
+	assert.Empty(t, KeysByScope(nil, nil)["manage_products"])
+}
+
+func TestRequireAPIKey_Refunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	order := db.Order{ID: "order-1", Status: db.OrderAccepted, Items: []db.Item{{ProductID: "1", Name: "Margherita", Quantity: 1, UnitPrice: 6}}}
+	oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(order, nil)
+	oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return(nil, nil)
+	oc.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, refund db.Refund) (db.Refund, error) {
+		return refund, nil
+	})
+	oc.EXPECT().GetRefundsBetween(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	svc := NewOrderAPIServiceWithCouponDao(oc, dbmocks.NewMockProductDao(ctrl), &testCouponDao{}, dbmocks.NewMockQuoteDao(ctrl))
+	router := openapi.NewRouter(openapi.NewOrderAPIController(svc))
+	router.Use(RequireAPIKey(KeysByScope([]string{"admin"}, map[string][]string{"refund_order": {"till"}, "create_order": {"shop"}})))
+
+	serve := func(method, path, key string) int {
+		return serveWithKey(router, method, path, key, `{"reason": "cold food", "operator": "jane"}`)
+	}
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/order/order-1/refund", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/order/order-1/refund", "shop"), "keys only open their own scope")
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/order/order-1/refund", "till"))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/refund", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/refund", "admin"))
+}
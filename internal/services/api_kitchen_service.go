@@ -0,0 +1,120 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/kitchen"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// KitchenAPIService implements business logic for the KitchenAPI defined by the generated OpenAPI.
+// It shows each station the open lines of the orders `db.KitchenDao` says the
+// kitchen is working on, and records the lines staff bump.
+type KitchenAPIService struct {
+	kitchenDao db.KitchenDao
+	orderDao   db.OrderDao
+	stations   *kitchen.Stations
+	// events announces orders that become ready; nil announces nothing.
+	events *OrderEventBus
+	// imageBase is the URL product image paths are relative to.
+	imageBase string
+}
+
+// NewKitchenAPIService creates a default api service. With nil stations,
+// each product category is prepared at its own station.
+func NewKitchenAPIService(kitchenDao db.KitchenDao, orderDao db.OrderDao, stations *kitchen.Stations, events *OrderEventBus, imageBaseURL string) *KitchenAPIService {
+	return &KitchenAPIService{kitchenDao: kitchenDao, orderDao: orderDao, stations: stations, events: events, imageBase: imageBaseURL}
+}
+
+// GetKitchenQueue - Get a station's queue
+func (s *KitchenAPIService) GetKitchenQueue(ctx context.Context, station string) (openapi.ImplResponse, error) {
+	orders, err := s.kitchenDao.GetOpenOrders(ctx)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	return openapi.Response(http.StatusOK, s.queue(orders, station, time.Now())), nil
+}
+
+// queue lists the unbumped lines of orders prepared at station, most urgent
+// first: the earliest due, then the longest waiting.
+func (s *KitchenAPIService) queue(orders []db.Order, station string, now time.Time) []openapi.KitchenLine {
+	lines := []openapi.KitchenLine{}
+	for _, order := range orders {
+		queuedAt, dueAt := kitchenTimes(order)
+		for i, item := range order.Items {
+			if !item.BumpedAt.IsZero() || !strings.EqualFold(s.stations.Station(item.ProductID, item.Category), station) {
+				continue
+			}
+			line := openapi.KitchenLine{
+				OrderId:        order.ID,
+				Line:           int32(i),
+				ProductId:      item.ProductID,
+				Name:           item.Name,
+				Quantity:       item.Quantity,
+				Note:           item.Note,
+				QueuedAt:       queuedAt.UTC(),
+				DueAt:          dueAt.UTC(),
+				ElapsedSeconds: int64(now.Sub(queuedAt) / time.Second),
+			}
+			if order.Fulfilment != nil {
+				line.Fulfilment = order.Fulfilment.Type
+			}
+			for _, modifier := range item.Modifiers {
+				line.Modifiers = append(line.Modifiers, modifier.Name)
+			}
+			lines = append(lines, line)
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if !a.DueAt.Equal(b.DueAt) {
+			return a.DueAt.Before(b.DueAt)
+		}
+		return a.QueuedAt.Before(b.QueuedAt)
+	})
+	return lines
+}
+
+// kitchenTimes returns when an order reached the kitchen and when it is
+// wanted. Pre-orders reach the kitchen when they are released and are wanted
+// at their scheduled time, takeaways at their pickup time, and everything
+// else as soon as it arrives.
+func kitchenTimes(order db.Order) (queuedAt, dueAt time.Time) {
+	queuedAt = order.CreatedAt
+	if !order.ScheduledFor.IsZero() {
+		return order.ReleasedAt, order.ScheduledFor
+	}
+	if order.Fulfilment != nil && !order.Fulfilment.PickupTime.IsZero() {
+		return queuedAt, order.Fulfilment.PickupTime
+	}
+	return queuedAt, queuedAt
+}
+
+// BumpKitchenLine - Bump an order line
+func (s *KitchenAPIService) BumpKitchenLine(ctx context.Context, orderId string, line int32) (openapi.ImplResponse, error) {
+	if err := s.kitchenDao.BumpLine(ctx, orderId, int(line)); err != nil {
+		var statusErr *db.OrderStatusError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return openapi.Response(http.StatusNotFound, nil), nil
+		case errors.As(err, &statusErr):
+			return openapi.Response(http.StatusConflict, err.Error()), nil
+		}
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	order, err := s.orderDao.GetOrder(ctx, orderId)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res := orderResponse(order, s.imageBase)
+	if order.Status == db.OrderReady {
+		s.events.Publish(StreamOrderStatusChanged, res)
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
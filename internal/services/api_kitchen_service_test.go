@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/kitchen"
+)
+
+func TestKitchenQueue(t *testing.T) {
+	stations, err := kitchen.New(kitchen.Config{Categories: map[string]string{"Waffle": "grill", "Brownie": "grill"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	waffle := db.Item{ProductID: "1", Quantity: 2, Name: "Waffle with Berries", Category: "Waffle", UnitPrice: 6.5,
+		Modifiers: []db.Modifier{{ID: "cream", Name: "Extra cream", PriceDelta: 1}}, Note: "no sugar"}
+	brownie := db.Item{ProductID: "8", Quantity: 1, Name: "Salted Caramel Brownie", Category: "Brownie", UnitPrice: 5}
+	tiramisu := db.Item{ProductID: "4", Quantity: 1, Name: "Classic Tiramisu", Category: "Tiramisu", UnitPrice: 8}
+	bumped := brownie
+	bumped.BumpedAt = now.Add(-time.Minute)
+	orders := []db.Order{
+		{ID: "old", CreatedAt: now.Add(-10 * time.Minute), Items: []db.Item{tiramisu, bumped, waffle}},
+		// Picked up in an hour, so it can wait behind orders wanted now.
+		{ID: "takeaway", CreatedAt: now.Add(-8 * time.Minute), Items: []db.Item{brownie},
+			Fulfilment: &db.Fulfilment{Type: db.FulfilmentTakeaway, PickupName: "Sam", PickupTime: now.Add(time.Hour)}},
+		{ID: "new", CreatedAt: now.Add(-2 * time.Minute), Items: []db.Item{brownie}},
+		// Released two minutes ago for pickup in a quarter of an hour.
+		{ID: "scheduled", CreatedAt: now.Add(-24 * time.Hour), ScheduledFor: now.Add(15 * time.Minute), ReleasedAt: now.Add(-2 * time.Minute), Items: []db.Item{waffle}},
+	}
+	svc := NewKitchenAPIService(nil, nil, stations, nil, "")
+
+	assert.Equal(t, []openapi.KitchenLine{
+		{OrderId: "old", Line: 2, ProductId: "1", Name: "Waffle with Berries", Quantity: 2, Modifiers: []string{"Extra cream"}, Note: "no sugar",
+			QueuedAt: now.Add(-10 * time.Minute), DueAt: now.Add(-10 * time.Minute), ElapsedSeconds: 600},
+		{OrderId: "new", ProductId: "8", Name: "Salted Caramel Brownie", Quantity: 1,
+			QueuedAt: now.Add(-2 * time.Minute), DueAt: now.Add(-2 * time.Minute), ElapsedSeconds: 120},
+		{OrderId: "scheduled", ProductId: "1", Name: "Waffle with Berries", Quantity: 2, Modifiers: []string{"Extra cream"}, Note: "no sugar",
+			QueuedAt: now.Add(-2 * time.Minute), DueAt: now.Add(15 * time.Minute), ElapsedSeconds: 120},
+		{OrderId: "takeaway", ProductId: "8", Name: "Salted Caramel Brownie", Quantity: 1, Fulfilment: db.FulfilmentTakeaway,
+			QueuedAt: now.Add(-8 * time.Minute), DueAt: now.Add(time.Hour), ElapsedSeconds: 480},
+	}, svc.queue(orders, "Grill", now))
+	assert.Equal(t, []string{"old"}, kitchenLineOrders(svc.queue(orders, "tiramisu", now)), "unmapped categories are their own station")
+	assert.Empty(t, svc.queue(orders, "drinks", now))
+}
+
+func kitchenLineOrders(lines []openapi.KitchenLine) []string {
+	ids := []string{}
+	for _, line := range lines {
+		ids = append(ids, line.OrderId)
+	}
+	return ids
+}
+
+func TestBumpKitchenLine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kd := dbmocks.NewMockKitchenDao(ctrl)
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	kd.EXPECT().BumpLine(gomock.Any(), db.ID("o1"), 3).Return(db.ErrNotFound(sql.ErrNoRows))
+	kd.EXPECT().BumpLine(gomock.Any(), db.ID("o2"), 0).Return(&db.OrderStatusError{ID: "o2", Status: db.OrderReady})
+	kd.EXPECT().BumpLine(gomock.Any(), db.ID("o1"), 0).Return(nil)
+	oc.EXPECT().GetOrder(gomock.Any(), db.ID("o1")).Return(db.Order{ID: "o1", Status: db.OrderReady,
+		Items: []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5, BumpedAt: time.Now().UTC()}}}, nil)
+	bus := NewOrderEventBus(0, nil)
+	_, events, cancel := bus.Subscribe(OrderEventFilter{Statuses: []string{db.OrderReady}}, 0)
+	defer cancel()
+	svc := NewKitchenAPIService(kd, oc, nil, bus, "")
+	ctx := context.Background()
+
+	res, err := svc.BumpKitchenLine(ctx, "o1", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	res, err = svc.BumpKitchenLine(ctx, "o2", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Equal(t, "order o2 is ready", res.Body)
+
+	res, err = svc.BumpKitchenLine(ctx, "o1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, db.OrderReady, res.Body.(openapi.Order).Status)
+	select {
+	case event := <-events:
+		assert.Equal(t, StreamOrderStatusChanged, event.Type)
+		assert.Equal(t, "o1", event.Order.Id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the ready order")
+	}
+}
+
+func TestKitchenRoutesRequireAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kd := dbmocks.NewMockKitchenDao(ctrl)
+	kd.EXPECT().GetOpenOrders(gomock.Any()).Return(nil, nil)
+	stations, err := kitchen.New(kitchen.Config{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	router := openapi.NewRouter(openapi.NewKitchenAPIController(NewKitchenAPIService(kd, dbmocks.NewMockOrderDao(ctrl), stations, nil, "")))
+	router.Use(RequireAPIKey(KeysByScope(nil, map[string][]string{"kitchen": {"tablet"}, "manage_inventory": {"stockroom"}})))
+
+	for _, key := range []string{"", "stockroom"} {
+		assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, http.MethodGet, "/api/kitchen/grill/queue", key, ""), "queue with key %q", key)
+		assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, http.MethodPost, "/api/kitchen/order/o1/line/0/bump", key, ""), "bump with key %q", key)
+	}
+	assert.Equal(t, http.StatusOK, serveWithKey(router, http.MethodGet, "/api/kitchen/grill/queue", "tablet", ""))
+}
+
+func TestOrderEventBus_RoutesStations(t *testing.T) {
+	stations, err := kitchen.New(kitchen.Config{Products: map[string]string{"8": "grill"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	bus := NewOrderEventBus(0, stations)
+	_, grill, cancel := bus.Subscribe(OrderEventFilter{Stations: []string{"grill"}}, 0)
+	defer cancel()
+	bus.Publish(StreamOrderCreated, openapi.Order{Id: "cake", Products: []openapi.Product{{Id: "7", Category: "Cake"}}})
+	bus.Publish(StreamOrderCreated, openapi.Order{Id: "brownie", Products: []openapi.Product{{Id: "8", Category: "Brownie"}}})
+	select {
+	case event := <-grill:
+		assert.Equal(t, "brownie", event.Order.Id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the grill")
+	}
+}
@@ -2,12 +2,17 @@ package services
 
 import (
 	"backend-challenge/internal/db"
+	"backend-challenge/internal/delivery"
 	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/slots"
 	"backend-challenge/internal/utils"
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,8 +27,29 @@ type OrderAPIService struct {
 	orderDao   db.OrderDao
 	productDao db.ProductDao
 	couponDao  db.CouponDao
+	quoteDao   db.QuoteDao
+	// couponDiscount is the percentage of the subtotal taken off by a valid coupon.
+	couponDiscount float32
+	quoteTTL       time.Duration
+	// asyncOrders places every order asynchronously, as if the client had
+	// sent Prefer: respond-async.
+	asyncOrders bool
+	// couponRelease is the policy for giving back coupons on refunds.
+	couponRelease string
+	// events receives created orders and status changes; it may be nil.
+	events *OrderEventBus
+	// fulfilment limits when each fulfilment type can be chosen.
+	fulfilment map[string]FulfilmentAvailability
+	// deliveryZones prices delivery orders; nil delivers anywhere for free.
+	deliveryZones *delivery.Zones
+	// slots takes scheduled orders; nil turns scheduling off.
+	slots *slots.Schedule
+	// imageBase is the URL product image paths are relative to.
+	imageBase string
 }
 
+const defaultQuoteTTL = 15 * time.Minute
+
 func SearchForCoupon(filePath string, numberOfThreads int64, coupon string, stopAllchecks <-chan bool) (*atomic.Bool, *sync.WaitGroup, error) {
 	var stopProducers atomic.Bool
 	stopProducers.Store(false)
@@ -47,27 +73,67 @@ func SearchForCoupon(filePath string, numberOfThreads int64, coupon string, stop
 	return atomicBool, wgRecivers, nil
 }
 
-// NewOrderAPIService creates a default api service
-func NewOrderAPIService(orderDao db.OrderDao, productDao db.ProductDao, files []string, couponMin int) *OrderAPIService {
-	return &OrderAPIService{
-		orderDao:   orderDao,
-		productDao: productDao,
-		couponDao:  db.NewCouponDao(files, couponMin),
+// OrderAPIServiceOption configures optional behaviour of an OrderAPIService.
+type OrderAPIServiceOption func(*OrderAPIService)
+
+// WithCouponDiscount sets the percentage of the subtotal taken off by a valid coupon.
+func WithCouponDiscount(percent float32) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		s.couponDiscount = percent
 	}
 }
 
+// WithQuoteTTL sets how long quoted prices are guaranteed.
+func WithQuoteTTL(ttl time.Duration) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		if ttl > 0 {
+			s.quoteTTL = ttl
+		}
+	}
+}
+
+// WithAsyncOrders makes asynchronous placement the default for PlaceOrder.
+func WithAsyncOrders(async bool) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		s.asyncOrders = async
+	}
+}
+
+// NewOrderAPIService creates a default api service
+func NewOrderAPIService(orderDao db.OrderDao, productDao db.ProductDao, quoteDao db.QuoteDao, files []string, couponMin int, opts ...OrderAPIServiceOption) *OrderAPIService {
+	return NewOrderAPIServiceWithCouponDao(orderDao, productDao, db.NewCouponDao(files, couponMin), quoteDao, opts...)
+}
+
 // NewOrderAPIServiceWithCouponDao creates a default api service by injecting couponDao directly.
 // This constructor is useful for unit tests where a couponDao mock can be provided.
-func NewOrderAPIServiceWithCouponDao(orderDao db.OrderDao, productDao db.ProductDao, couponDao db.CouponDao) *OrderAPIService {
-	return &OrderAPIService{
+func NewOrderAPIServiceWithCouponDao(orderDao db.OrderDao, productDao db.ProductDao, couponDao db.CouponDao, quoteDao db.QuoteDao, opts ...OrderAPIServiceOption) *OrderAPIService {
+	s := &OrderAPIService{
 		orderDao:   orderDao,
 		productDao: productDao,
 		couponDao:  couponDao,
+		quoteDao:   quoteDao,
+		quoteTTL:   defaultQuoteTTL,
+
+		couponRelease: CouponReleaseCancel,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // PlaceOrder - Place an order
-func (s *OrderAPIService) PlaceOrder(ctx context.Context, orderReq openapi.OrderReq) (res openapi.ImplResponse, err error) {
+func (s *OrderAPIService) PlaceOrder(ctx context.Context, prefer string, orderReq openapi.OrderReq) (res openapi.ImplResponse, err error) {
+	now := time.Now()
+	fulfilment, err := s.fulfilmentFor(orderReq.Fulfilment, orderReq.ScheduledFor, now)
+	if err != nil {
+		return pricingFailure(err)
+	}
+	// Quoted orders are already priced, so there is nothing to wait for.
+	if orderReq.QuoteId == "" && (s.asyncOrders || prefersAsync(prefer)) {
+		return s.placeOrderAsync(ctx, prefer, orderReq, fulfilment, now)
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 	defer func() {
@@ -78,63 +144,306 @@ func (s *OrderAPIService) PlaceOrder(ctx context.Context, orderReq openapi.Order
 		}
 	}()
 
-	if len(orderReq.CouponCode) < 8 || len(orderReq.CouponCode) > 10 {
-		return openapi.Response(http.StatusUnprocessableEntity, "invalid coupon code"), nil
+	var priced pricedOrder
+	if orderReq.QuoteId != "" {
+		priced, err = s.quotedOrder(ctx, orderReq)
+	} else {
+		priced, err = s.priceOrder(ctx, orderReq, nil)
+	}
+	if err != nil {
+		return pricingFailure(err)
 	}
+	if err := s.fulfil(&priced, fulfilment); err != nil {
+		return pricingFailure(err)
+	}
+	if err := s.schedule(&priced, orderReq.ScheduledFor, now); err != nil {
+		return pricingFailure(err)
+	}
+	return s.createOrder(ctx, priced)
+}
 
-	searchResult, err := s.couponDao.SearchForCouponInGivenFiles(ctx, orderReq)
+// placeOrderAsync checks the request and resolves its products, then stores
+// the order as pending and answers 202 straight away. The coupon search, which
+// can take as long as PlaceOrder's timeout, finishes in the background and
+// moves the order to accepted or rejected; clients poll GetOrder for it.
+func (s *OrderAPIService) placeOrderAsync(ctx context.Context, prefer string, orderReq openapi.OrderReq, fulfilment *db.Fulfilment, now time.Time) (openapi.ImplResponse, error) {
+	if !validCouponFormat(orderReq.CouponCode) {
+		return pricingFailure(reject(http.StatusUnprocessableEntity, "invalid coupon code"))
+	}
+	items, err := s.resolveItems(ctx, orderReq.Items)
 	if err != nil {
-		return openapi.ImplResponse{}, nil
+		return pricingFailure(err)
+	}
+	priced := pricedOrder{items: items, couponCode: orderReq.CouponCode}
+	// The discount is what the order gets if the coupon turns out to be valid;
+	// an invalid coupon rejects the whole order rather than dropping it.
+	priced.discounts = s.discountFor(priced.subtotal())
+	if err := s.fulfil(&priced, fulfilment); err != nil {
+		return pricingFailure(err)
+	}
+	if err := s.schedule(&priced, orderReq.ScheduledFor, now); err != nil {
+		return pricingFailure(err)
 	}
 
-	id := uuid.New().String()
-	items := make([]openapi.OrderItemsInner, 0, len(orderReq.Items))
-	products := make([]openapi.Product, 0, len(orderReq.Items))
+	order := priced.order(uuid.New().String())
+	order.Status = db.OrderPending
+	if err := s.orderDao.CreateOrder(ctx, order); err != nil {
+		return createFailure(err)
+	}
+	s.events.Publish(StreamOrderCreated, orderResponse(order, s.imageBase))
+	go s.finishOrder(order.ID, orderReq)
+
+	headers := map[string][]string{"Location": {"/api/order/" + order.ID}}
+	if prefersAsync(prefer) {
+		headers["Preference-Applied"] = []string{"respond-async"}
+	}
+	return openapi.ResponseWithHeaders(http.StatusAccepted, headers, orderResponse(order, s.imageBase)), nil
+}
 
-	for _, item := range orderReq.Items {
-		if item.Quantity <= 0 {
-			return openapi.Response(http.StatusBadRequest, "quantity must be greater than zero"), nil
+// finishOrder validates the coupon of a pending order and records the outcome.
+// It runs after the request has returned, so it has its own timeout.
+func (s *OrderAPIService) finishOrder(id db.ID, orderReq openapi.OrderReq) {
+	ctx, cancel := context.WithTimeout(context.Background(), couponCheckTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("finishing order %s: recovered from %v", id, r)
+			s.setOrderStatus(ctx, id, db.OrderRejected, "order could not be processed")
 		}
-		product, err := s.productDao.GetProduct(ctx, db.ID(item.ProductId))
-		if err != nil {
-			return openapi.Response(http.StatusBadRequest, "invalid product specified"), nil
+	}()
+
+	result, err := s.couponDao.SearchForCouponInGivenFiles(ctx, orderReq)
+	if err == nil {
+		var valid bool
+		valid, err = result.Validate()
+		if err == nil && !valid {
+			s.setOrderStatus(ctx, id, db.OrderRejected, "invalid coupon code")
+			return
 		}
-		// Convert OrderReqItemsInner to OrderItemsInner using type conversion
-		items = append(items, openapi.OrderItemsInner(item))
-		openapiProduct := openapi.Product{
-			Id:       product.Id,
-			Name:     product.Name,
-			Price:    product.Price,
-			Category: product.Category,
+	}
+	if err != nil {
+		log.Printf("checking coupon for order %s: %v", id, err)
+		s.setOrderStatus(ctx, id, db.OrderRejected, "coupon could not be checked")
+		return
+	}
+	s.setOrderStatus(ctx, id, db.OrderAccepted, "")
+}
+
+func (s *OrderAPIService) setOrderStatus(ctx context.Context, id db.ID, status, reason string) {
+	if err := s.orderDao.UpdateOrderStatus(ctx, id, status, reason); err != nil {
+		log.Printf("updating order %s to %s: %v", id, status, err)
+		return
+	}
+	s.publishStatus(ctx, id)
+}
+
+// publishStatus announces an order's new status.
+func (s *OrderAPIService) publishStatus(ctx context.Context, id db.ID) {
+	s.publishStored(ctx, StreamOrderStatusChanged, id)
+}
+
+// publishStored announces a change to an order. The order is read back so
+// that subscribers see it as stored.
+func (s *OrderAPIService) publishStored(ctx context.Context, eventType string, id db.ID) {
+	if s.events == nil {
+		return
+	}
+	order, err := s.orderDao.GetOrder(ctx, id)
+	if err != nil {
+		log.Printf("publishing %s of order %s: %v", eventType, id, err)
+		return
+	}
+	s.events.Publish(eventType, orderResponse(order, s.imageBase))
+}
+
+// prefersAsync reports whether a Prefer header asks for an asynchronous response.
+func prefersAsync(prefer string) bool {
+	for _, pref := range strings.Split(prefer, ",") {
+		if strings.EqualFold(strings.TrimSpace(pref), "respond-async") {
+			return true
 		}
-		products = append(products, openapiProduct)
 	}
+	return false
+}
 
-	if result, err := searchResult.Validate(); err != nil {
-		return openapi.ImplResponse{}, err
-	} else if !result {
-		return openapi.Response(http.StatusUnprocessableEntity, "invalid coupon code"), nil
+// CheckoutOrder places an order whose coupon search was started earlier, for
+// example when the coupon was applied to a cart, instead of starting a new one.
+func (s *OrderAPIService) CheckoutOrder(ctx context.Context, orderReq openapi.OrderReq, coupon db.SearchResult) (openapi.ImplResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	fulfilment, err := s.fulfilmentFor(orderReq.Fulfilment, orderReq.ScheduledFor, now)
+	if err != nil {
+		return pricingFailure(err)
 	}
+	priced, err := s.priceOrder(ctx, orderReq, coupon)
+	if err != nil {
+		return pricingFailure(err)
+	}
+	if err := s.fulfil(&priced, fulfilment); err != nil {
+		return pricingFailure(err)
+	}
+	if err := s.schedule(&priced, orderReq.ScheduledFor, now); err != nil {
+		return pricingFailure(err)
+	}
+	return s.createOrder(ctx, priced)
+}
 
-	if err = s.orderDao.CreateOrder(ctx, db.Order{
-		ID: id,
-		Items: func() []db.Item {
-			var dbItems []db.Item
-			for _, item := range orderReq.Items {
-				dbItems = append(dbItems, db.Item{
-					ProductID: db.ID(item.ProductId),
-					Quantity:  item.Quantity,
-				})
-			}
-			return dbItems
-		}(),
-	}); err != nil {
-		return openapi.ImplResponse{}, err
+// createOrder stores a priced order and returns it to the client.
+func (s *OrderAPIService) createOrder(ctx context.Context, priced pricedOrder) (openapi.ImplResponse, error) {
+	order := priced.order(uuid.New().String())
+	order.Status = db.OrderAccepted
+	if err := s.orderDao.CreateOrder(ctx, order); err != nil {
+		return createFailure(err)
 	}
+	s.events.Publish(StreamOrderCreated, orderResponse(order, s.imageBase))
 
-	return openapi.Response(http.StatusOK, openapi.Order{
-		Id:       id,
-		Items:    items,
-		Products: products,
+	return openapi.Response(http.StatusOK, orderResponse(order, s.imageBase)), nil
+}
+
+// createFailure answers a failed CreateOrder. Running out of stock or of an
+// ingredient, or finding the pickup slot full, is a conflict the client can
+// resolve by changing the order. A quote another order used first can't be
+// used again.
+func createFailure(err error) (openapi.ImplResponse, error) {
+	var outOfStock *db.OutOfStockError
+	var shortage *db.IngredientShortageError
+	var full *db.SlotFullError
+	var used *db.QuoteUsedError
+	switch {
+	case errors.As(err, &outOfStock), errors.As(err, &shortage):
+		return openapi.Response(http.StatusConflict, err.Error()), nil
+	case errors.As(err, &used):
+		return openapi.Response(http.StatusUnprocessableEntity, "quote has already been used"), nil
+	case errors.As(err, &full):
+		return openapi.Response(http.StatusConflict, fmt.Sprintf("the %s pickup slot is full", full.Start.Local().Format("15:04"))), nil
+	}
+	return openapi.Response(http.StatusInternalServerError, nil), err
+}
+
+// QuoteOrder - Quote an order
+func (s *OrderAPIService) QuoteOrder(ctx context.Context, orderReq openapi.OrderReq) (openapi.ImplResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	fulfilment, err := s.fulfilmentFor(orderReq.Fulfilment, orderReq.ScheduledFor, now)
+	if err != nil {
+		return pricingFailure(err)
+	}
+	priced, err := s.priceOrder(ctx, orderReq, nil)
+	if err != nil {
+		return pricingFailure(err)
+	}
+	if err := s.fulfil(&priced, fulfilment); err != nil {
+		return pricingFailure(err)
+	}
+	// Slot capacity is only taken when the order is placed.
+	if err := s.schedule(&priced, orderReq.ScheduledFor, now); err != nil {
+		return pricingFailure(err)
+	}
+
+	quote := db.Quote{
+		ID:         uuid.New().String(),
+		Items:      priced.items,
+		CouponCode: priced.couponCode,
+		Discounts:  priced.discounts,
+		ExpiresAt:  time.Now().Add(s.quoteTTL).UTC(),
+	}
+	if err := s.quoteDao.CreateQuote(ctx, quote); err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return openapi.Response(http.StatusOK, openapi.Quote{
+		Id:        quote.ID,
+		ExpiresAt: quote.ExpiresAt,
+		Order:     orderResponse(priced.order(""), s.imageBase),
 	}), nil
 }
+
+// GetOrder - Find order by ID
+func (s *OrderAPIService) GetOrder(ctx context.Context, orderId string) (openapi.ImplResponse, error) {
+	order, err := s.orderDao.GetOrder(ctx, db.ID(orderId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return openapi.Response(http.StatusNotFound, nil), nil
+		}
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	// Orders placed before line snapshots existed only carry product IDs; fall back
+	// to the catalog for those lines so they can still be displayed.
+	var legacy []db.ID
+	for _, item := range order.Items {
+		if !item.HasSnapshot() {
+			legacy = append(legacy, item.ProductID)
+		}
+	}
+	if len(legacy) > 0 {
+		// Products deleted since are left without a name or price.
+		products, err := s.productDao.GetProductsByIDs(ctx, legacy)
+		var unknown *db.UnknownProductsError
+		if err != nil && !errors.As(err, &unknown) {
+			return openapi.Response(http.StatusInternalServerError, nil), err
+		}
+		for i, item := range order.Items {
+			if product, ok := products[item.ProductID]; ok && !item.HasSnapshot() {
+				order.Items[i].Name = product.Name
+				order.Items[i].Category = product.Category
+				order.Items[i].UnitPrice = product.Price
+				order.Items[i].Images = product.Images
+			}
+		}
+	}
+	refunds, err := s.orderDao.GetRefunds(ctx, order.ID)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res := orderResponse(order, s.imageBase)
+	res.Refunded = refundedAmount(refunds)
+	for _, refund := range refunds {
+		res.Refunds = append(res.Refunds, refundResponse(refund))
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
+
+// orderResponse builds the API representation of a stored order. Products and the
+// total come from the line snapshots rather than the live catalog.
+func orderResponse(order db.Order, imageBase string) openapi.Order {
+	items := make([]openapi.OrderItemsInner, 0, len(order.Items))
+	products := make([]openapi.Product, 0, len(order.Items))
+	for _, item := range order.Items {
+		line := openapi.OrderItemsInner{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+			Note:      item.Note,
+			LineTotal: roundCents(item.UnitTotal() * float32(item.Quantity)),
+			BumpedAt:  item.BumpedAt,
+		}
+		for _, modifier := range item.Modifiers {
+			line.Modifiers = append(line.Modifiers, modifierResponse(modifier))
+		}
+		items = append(items, line)
+		products = append(products, openapi.Product{
+			Id:       item.ProductID,
+			Name:     item.Name,
+			Price:    item.UnitPrice,
+			Category: item.Category,
+			Image:    productImage(imageBase, item.Images),
+		})
+	}
+	return openapi.Order{
+		Id:           order.ID,
+		Total:        order.Total(),
+		Discounts:    order.Discounts,
+		DeliveryFee:  order.DeliveryFee,
+		Status:       order.Status,
+		StatusReason: order.StatusReason,
+		Fulfilment:   fulfilmentResponse(order.Fulfilment),
+		ScheduledFor: order.ScheduledFor,
+		ReleasedAt:   order.ReleasedAt,
+		CreatedAt:    order.CreatedAt,
+		Items:        items,
+		Products:     products,
+	}
+}
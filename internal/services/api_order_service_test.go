@@ -2,9 +2,12 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -39,6 +42,8 @@ func TestPlaceOrder(t *testing.T) {
 		args       args
 		setupMocks func(ctrl *gomock.Controller) (db.OrderDao, db.ProductDao, db.CouponDao)
 		wantCode   int
+		wantBody   any
+		wantErr    bool
 	}{
 		{
 			name: "invalid coupon length",
@@ -67,11 +72,37 @@ func TestPlaceOrder(t *testing.T) {
 				oc := dbmocks.NewMockOrderDao(ctrl)
 				pc := dbmocks.NewMockProductDao(ctrl)
 				// mock product failure
-				pc.EXPECT().GetProduct(gomock.Any(), db.ID("invalid-prod")).Return(db.Product{}, errors.New("not found"))
+				pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"invalid-prod"}).Return(map[db.ID]db.Product{}, &db.UnknownProductsError{IDs: []db.ID{"invalid-prod"}})
 				return oc, pc, &testCouponDao{found: true}
 			},
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name: "every invalid product is reported",
+			args: args{req: openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}, {ProductId: "bad-1", Quantity: 1}, {ProductId: "bad-2", Quantity: 1}}}},
+			setupMocks: func(ctrl *gomock.Controller) (db.OrderDao, db.ProductDao, db.CouponDao) {
+				oc := dbmocks.NewMockOrderDao(ctrl)
+				pc := dbmocks.NewMockProductDao(ctrl)
+				pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1", "bad-1", "bad-2"}).Return(
+					map[db.ID]db.Product{"1": {Id: "1", Name: "Product 1", Price: 100, Category: "cat"}},
+					&db.UnknownProductsError{IDs: []db.ID{"bad-1", "bad-2"}})
+				return oc, pc, &testCouponDao{found: true}
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: "invalid products specified: bad-1, bad-2",
+		},
+		{
+			name: "product lookup failure",
+			args: args{req: openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}}}},
+			setupMocks: func(ctrl *gomock.Controller) (db.OrderDao, db.ProductDao, db.CouponDao) {
+				oc := dbmocks.NewMockOrderDao(ctrl)
+				pc := dbmocks.NewMockProductDao(ctrl)
+				pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(nil, errors.New("database is locked"))
+				return oc, pc, &testCouponDao{found: true}
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  true,
+		},
 		{
 			name: "create test order successfully",
 			args: args{req: openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}}},
@@ -79,7 +110,7 @@ func TestPlaceOrder(t *testing.T) {
 				oc := dbmocks.NewMockOrderDao(ctrl)
 				pc := dbmocks.NewMockProductDao(ctrl)
 				// mock product lookup
-				pc.EXPECT().GetProduct(gomock.Any(), db.ID("1")).Return(db.Product{Id: "1", Name: "Product 1", Price: 100, Category: "cat"}, nil)
+				pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": db.Product{Id: "1", Name: "Product 1", Price: 100, Category: "cat"}}, nil)
 				// expect CreateOrder to be called and succeed
 				oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(nil)
 				return oc, pc, &testCouponDao{found: true}
@@ -93,12 +124,667 @@ func TestPlaceOrder(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			orderDao, productDao, couponDao := tt.setupMocks(ctrl)
-			svc := NewOrderAPIServiceWithCouponDao(orderDao, productDao, couponDao)
-			res, err := svc.PlaceOrder(context.Background(), tt.args.req)
+			svc := NewOrderAPIServiceWithCouponDao(orderDao, productDao, couponDao, dbmocks.NewMockQuoteDao(ctrl))
+			res, err := svc.PlaceOrder(context.Background(), "", tt.args.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else if err != nil {
+				t.Fatalf("Service error: %v", err)
+			}
+			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantBody != nil {
+				assert.Equal(t, tt.wantBody, res.Body)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderSnapshotsProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": db.Product{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, nil)
+	var stored db.Order
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		stored = order
+		return nil
+	})
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl))
+	res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("Service error: %v", err)
+	}
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, []db.Item{{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5}}, stored.Items)
+	body := res.Body.(openapi.Order)
+	assert.Equal(t, float32(13), body.Total)
+	assert.Equal(t, []openapi.Product{{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, body.Products)
+}
+
+func TestPlaceOrderImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	images := map[string]string{db.ImageThumbnail: "waffle-thumbnail.jpg", db.ImageDesktop: "waffle-desktop.jpg"}
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle", Images: images}}, nil)
+	var stored db.Order
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		stored = order
+		return nil
+	})
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl), WithImageBaseURL("https://cdn.example.com/images"))
+	res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("Service error: %v", err)
+	}
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, images, stored.Items[0].Images)
+	assert.Equal(t, openapi.ProductImage{
+		Thumbnail: "https://cdn.example.com/images/waffle-thumbnail.jpg",
+		Desktop:   "https://cdn.example.com/images/waffle-desktop.jpg",
+	}, res.Body.(openapi.Order).Products[0].Image)
+}
+
+func TestPlaceOrderRejectsArchivedProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1", "6"}).Return(map[db.ID]db.Product{
+		"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"},
+		"6": {Id: "6", Name: "Lemon Meringue Pie", Price: 5, Category: "Pie", ArchivedAt: time.Now()},
+	}, nil)
+	svc := NewOrderAPIServiceWithCouponDao(dbmocks.NewMockOrderDao(ctrl), pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl))
+	res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}, {ProductId: "6", Quantity: 1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "invalid products specified: 6", res.Body)
+}
+
+func TestGetOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(oc *dbmocks.MockOrderDao, pc *dbmocks.MockProductDao)
+		wantCode   int
+		wantOrder  openapi.Order
+	}{
+		{
+			name: "order not found",
+			setupMocks: func(oc *dbmocks.MockOrderDao, pc *dbmocks.MockProductDao) {
+				oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(db.Order{}, db.ErrNotFound(sql.ErrNoRows))
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "snapshot is used instead of the catalog",
+			setupMocks: func(oc *dbmocks.MockOrderDao, pc *dbmocks.MockProductDao) {
+				oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(db.Order{ID: "order-1", Items: []db.Item{
+					{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5},
+				}}, nil)
+				oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantOrder: openapi.Order{
+				Id:       "order-1",
+				Total:    13,
+				Items:    []openapi.OrderItemsInner{{ProductId: "1", Quantity: 2, LineTotal: 13}},
+				Products: []openapi.Product{{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}},
+			},
+		},
+		{
+			name: "legacy line falls back to the catalog",
+			setupMocks: func(oc *dbmocks.MockOrderDao, pc *dbmocks.MockProductDao) {
+				oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(db.Order{ID: "order-1", Items: []db.Item{
+					{ProductID: "1", Quantity: 1},
+				}}, nil)
+				pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": db.Product{Id: "1", Name: "Waffle", Price: 7, Category: "Waffle"}}, nil)
+				oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantOrder: openapi.Order{
+				Id:       "order-1",
+				Total:    7,
+				Items:    []openapi.OrderItemsInner{{ProductId: "1", Quantity: 1, LineTotal: 7}},
+				Products: []openapi.Product{{Id: "1", Name: "Waffle", Price: 7, Category: "Waffle"}},
+			},
+		},
+		{
+			name: "refunds are listed",
+			setupMocks: func(oc *dbmocks.MockOrderDao, pc *dbmocks.MockProductDao) {
+				oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(db.Order{ID: "order-1", Status: db.OrderAccepted, Items: []db.Item{
+					{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5},
+				}}, nil)
+				oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return([]db.Refund{
+					{ID: "refund-1", OrderID: "order-1", Amount: 2.5, Reason: "cold", Operator: "jane", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantOrder: openapi.Order{
+				Id:       "order-1",
+				Total:    13,
+				Status:   db.OrderAccepted,
+				Refunded: 2.5,
+				Refunds: []openapi.Refund{
+					{Id: "refund-1", OrderId: "order-1", Amount: 2.5, Reason: "cold", Operator: "jane", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+				Items:    []openapi.OrderItemsInner{{ProductId: "1", Quantity: 2, LineTotal: 13}},
+				Products: []openapi.Product{{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			oc := dbmocks.NewMockOrderDao(ctrl)
+			pc := dbmocks.NewMockProductDao(ctrl)
+			tt.setupMocks(oc, pc)
+			svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{}, dbmocks.NewMockQuoteDao(ctrl))
+			res, err := svc.GetOrder(context.Background(), "order-1")
+			if err != nil {
+				t.Fatalf("Service error: %v", err)
+			}
+			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantOrder, res.Body)
+			}
+		})
+	}
+}
+
+func TestQuoteOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	qc := dbmocks.NewMockQuoteDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": db.Product{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, nil)
+	var stored db.Quote
+	qc.EXPECT().CreateQuote(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, quote db.Quote) error {
+		stored = quote
+		return nil
+	})
+	// CreateOrder must not be called for a quote.
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, qc, WithCouponDiscount(10), WithQuoteTTL(time.Minute))
+	res, err := svc.QuoteOrder(context.Background(), openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("Service error: %v", err)
+	}
+	assert.Equal(t, http.StatusOK, res.Code)
+	body := res.Body.(openapi.Quote)
+	assert.Equal(t, stored.ID, body.Id)
+	assert.Equal(t, stored.ExpiresAt, body.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), body.ExpiresAt, 5*time.Second)
+	assert.Equal(t, float32(1.3), body.Order.Discounts)
+	assert.Equal(t, float32(11.7), body.Order.Total)
+	assert.Equal(t, float32(1.3), stored.Discounts)
+	assert.Equal(t, "HAPPYHOURS", stored.CouponCode)
+}
+
+func TestPlaceOrderWithQuote(t *testing.T) {
+	quote := db.Quote{
+		ID:         "quote-1",
+		Items:      []db.Item{{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5}},
+		CouponCode: "HAPPYHOURS",
+		Discounts:  1.3,
+		ExpiresAt:  time.Now().Add(time.Minute),
+	}
+	expired := quote
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	used := quote
+	used.UsedBy = "order-1"
+
+	tests := []struct {
+		name     string
+		req      openapi.OrderReq
+		quote    db.Quote
+		quoteErr error
+		archived bool
+		wantCode int
+	}{
+		{
+			name:     "unknown quote",
+			req:      openapi.OrderReq{QuoteId: "quote-1", CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}},
+			quoteErr: db.ErrNotFound(sql.ErrNoRows),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "expired quote",
+			req:      openapi.OrderReq{QuoteId: "quote-1", CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}},
+			quote:    expired,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "used quote",
+			req:      openapi.OrderReq{QuoteId: "quote-1", CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}},
+			quote:    used,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "product archived since",
+			req:      openapi.OrderReq{QuoteId: "quote-1", CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}},
+			quote:    quote,
+			archived: true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "items differ from quote",
+			req:      openapi.OrderReq{QuoteId: "quote-1", CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 3}}},
+			quote:    quote,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "negative line balancing another",
+			req:      openapi.OrderReq{QuoteId: "quote-1", CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 3}, {ProductId: "1", Quantity: -1}}},
+			quote:    quote,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "quoted prices are charged",
+			req:      openapi.OrderReq{QuoteId: "quote-1", CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}, {ProductId: "1", Quantity: 1}}},
+			quote:    quote,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			oc := dbmocks.NewMockOrderDao(ctrl)
+			// The catalog is only checked for archived products when a quote
+			// is honoured; its prices aren't used.
+			pc := dbmocks.NewMockProductDao(ctrl)
+			if tt.wantCode == http.StatusOK || tt.archived {
+				product := db.Product{Id: "1", Name: "Waffle", Price: 9, Category: "Waffle"}
+				if tt.archived {
+					product.ArchivedAt = time.Now()
+				}
+				pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": product}, nil)
+			}
+			qc := dbmocks.NewMockQuoteDao(ctrl)
+			qc.EXPECT().GetQuote(gomock.Any(), db.ID("quote-1")).Return(tt.quote, tt.quoteErr)
+			if tt.wantCode == http.StatusOK {
+				oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+					assert.Equal(t, tt.quote.Items, order.Items)
+					assert.Equal(t, tt.quote.Discounts, order.Discounts)
+					assert.Equal(t, db.ID("quote-1"), order.QuoteID, "the order uses the quote up")
+					return nil
+				})
+			}
+			svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: false}, qc)
+			res, err := svc.PlaceOrder(context.Background(), "", tt.req)
+			if err != nil {
+				t.Fatalf("Service error: %v", err)
+			}
+			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, float32(11.7), res.Body.(openapi.Order).Total)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderMergesDuplicateLines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"2", "1"}).Return(map[db.ID]db.Product{
+		"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"},
+		"2": {Id: "2", Name: "Pie", Price: 5, Category: "Pie"},
+	}, nil)
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		assert.Equal(t, []db.Item{
+			{ProductID: "2", Quantity: 1, Name: "Pie", Category: "Pie", UnitPrice: 5},
+			{ProductID: "1", Quantity: 3, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5},
+		}, order.Items)
+		return nil
+	})
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl))
+	res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{
+		{ProductId: "2", Quantity: 1}, {ProductId: "1", Quantity: 1}, {ProductId: "1", Quantity: 2},
+	}})
+	if err != nil {
+		t.Fatalf("Service error: %v", err)
+	}
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, float32(24.5), res.Body.(openapi.Order).Total)
+}
+
+func TestPlaceOrderWithModifiers(t *testing.T) {
+	waffle := db.Product{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle", ModifierGroups: []db.ModifierGroup{
+		{ID: "size", Name: "Size", Required: true, MaxSelect: 1, Options: []db.Modifier{
+			{ID: "small", GroupID: "size", Name: "Small", PriceDelta: -1},
+			{ID: "large", GroupID: "size", Name: "Large", PriceDelta: 1.5},
+		}},
+		{ID: "extras", Name: "Extras", MinSelect: 2, MaxSelect: 3, Options: []db.Modifier{
+			{ID: "syrup", GroupID: "extras", Name: "Extra syrup", PriceDelta: 0.5},
+			{ID: "cream", GroupID: "extras", Name: "Cream", PriceDelta: 1},
+			{ID: "no-nuts", GroupID: "extras", Name: "No nuts"},
+		}},
+	}}
+	tests := []struct {
+		name      string
+		items     []openapi.OrderReqItemsInner
+		wantCode  int
+		wantItems []db.Item
+		wantTotal float32
+	}{
+		{
+			name:     "required group left out",
+			items:    []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "too many from a group",
+			items:    []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1, Modifiers: []string{"small", "large"}}},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "too few from an optional group that is used",
+			items:    []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1, Modifiers: []string{"small", "syrup"}}},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "modifier of another product",
+			items:    []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1, Modifiers: []string{"small", "ice"}}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "modifier chosen twice",
+			items:    []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1, Modifiers: []string{"small", "small"}}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "note too long",
+			items:    []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1, Modifiers: []string{"small"}, Note: strings.Repeat("x", 201)}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "modifiers are priced into the line",
+			items: []openapi.OrderReqItemsInner{
+				{ProductId: "1", Quantity: 1, Modifiers: []string{"no-nuts", "large", "syrup"}, Note: "well done"},
+				{ProductId: "1", Quantity: 2, Modifiers: []string{"small"}},
+				{ProductId: "1", Quantity: 1, Modifiers: []string{"large", "syrup", "no-nuts"}, Note: " well done "},
+			},
+			wantCode: http.StatusOK,
+			wantItems: []db.Item{
+				{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5, Note: "well done", Modifiers: []db.Modifier{
+					{ID: "large", GroupID: "size", Name: "Large", PriceDelta: 1.5},
+					{ID: "syrup", GroupID: "extras", Name: "Extra syrup", PriceDelta: 0.5},
+					{ID: "no-nuts", GroupID: "extras", Name: "No nuts"},
+				}},
+				{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5, Modifiers: []db.Modifier{
+					{ID: "small", GroupID: "size", Name: "Small", PriceDelta: -1},
+				}},
+			},
+			// (8.5 * 2 + 5.5 * 2) less 10%
+			wantTotal: 25.2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			oc := dbmocks.NewMockOrderDao(ctrl)
+			pc := dbmocks.NewMockProductDao(ctrl)
+			pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": waffle}, nil).MaxTimes(1)
+			if tt.wantCode == http.StatusOK {
+				oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+					assert.Equal(t, tt.wantItems, order.Items)
+					return nil
+				})
+			}
+			svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl), WithCouponDiscount(10))
+
+			res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: tt.items})
+			if err != nil {
+				t.Fatalf("Service error: %v", err)
+			}
+			assert.Equal(t, tt.wantCode, res.Code, res.Body)
+			if tt.wantCode == http.StatusOK {
+				body := res.Body.(openapi.Order)
+				assert.Equal(t, tt.wantTotal, body.Total)
+				assert.Equal(t, float32(17), body.Items[0].LineTotal)
+				assert.Equal(t, "well done", body.Items[0].Note)
+				assert.Len(t, body.Items[0].Modifiers, 3)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderAsync(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefer     string
+		async      bool
+		found      bool
+		wantStatus string
+		wantReason string
+	}{
+		{name: "Prefer header, valid coupon", prefer: "respond-async", found: true, wantStatus: db.OrderAccepted},
+		{name: "Prefer header, invalid coupon", prefer: "wait=10, respond-async", wantStatus: db.OrderRejected, wantReason: "invalid coupon code"},
+		{name: "async by default", async: true, found: true, wantStatus: db.OrderAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			oc := dbmocks.NewMockOrderDao(ctrl)
+			pc := dbmocks.NewMockProductDao(ctrl)
+			pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{
+				"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"},
+			}, nil)
+			var placed db.Order
+			oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+				placed = order
+				return nil
+			})
+			finished := make(chan struct{})
+			oc.EXPECT().UpdateOrderStatus(gomock.Any(), gomock.Any(), tt.wantStatus, tt.wantReason).DoAndReturn(func(_ context.Context, id db.ID, _, _ string) error {
+				assert.Equal(t, placed.ID, id)
+				close(finished)
+				return nil
+			})
+			svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: tt.found}, dbmocks.NewMockQuoteDao(ctrl),
+				WithCouponDiscount(10), WithAsyncOrders(tt.async))
+
+			res, err := svc.PlaceOrder(context.Background(), tt.prefer, openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 2}}})
 			if err != nil {
 				t.Fatalf("Service error: %v", err)
 			}
+			assert.Equal(t, http.StatusAccepted, res.Code)
+			assert.Equal(t, db.OrderPending, placed.Status)
+			assert.Equal(t, []string{"/api/order/" + placed.ID}, res.Headers["Location"])
+			body := res.Body.(openapi.Order)
+			assert.Equal(t, db.OrderPending, body.Status)
+			assert.Equal(t, float32(11.7), body.Total)
+
+			select {
+			case <-finished:
+			case <-time.After(5 * time.Second):
+				t.Fatal("order was not finished")
+			}
+		})
+	}
+}
+
+func TestPlaceOrderAsyncRejectsUnknownProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"bad"}).Return(map[db.ID]db.Product{}, &db.UnknownProductsError{IDs: []db.ID{"bad"}})
+	svc := NewOrderAPIServiceWithCouponDao(dbmocks.NewMockOrderDao(ctrl), pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl))
+
+	res, err := svc.PlaceOrder(context.Background(), "respond-async", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "bad", Quantity: 1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestCancelOrder(t *testing.T) {
+	accepted := db.Order{ID: "order-1", Status: db.OrderAccepted, CouponCode: "HAPPYHRS", Discounts: 1.3, Items: []db.Item{
+		{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5},
+	}}
+	tests := []struct {
+		name    string
+		order   db.Order
+		refunds []db.Refund
+		// anonymous requests come without an API key.
+		anonymous  bool
+		wantCode   int
+		wantRefund *db.Refund
+	}{
+		{name: "rejected order can't be cancelled", order: db.Order{ID: "order-1", Status: db.OrderRejected}, wantCode: http.StatusConflict},
+		{name: "order the kitchen has started can't be cancelled", order: db.Order{ID: "order-1", Status: db.OrderAccepted, Items: []db.Item{
+			{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5, BumpedAt: time.Now()},
+		}}, wantCode: http.StatusConflict},
+		{name: "pending order has nothing to refund", order: db.Order{ID: "order-1", Status: db.OrderPending, Items: accepted.Items}, wantCode: http.StatusOK},
+		{
+			name:       "accepted order is refunded in full",
+			order:      accepted,
+			wantCode:   http.StatusOK,
+			wantRefund: &db.Refund{OrderID: "order-1", Amount: 11.7, Reason: "changed my mind", Operator: "api key till", CouponReleased: true},
+		},
+		{name: "refunds need a key", order: accepted, anonymous: true, wantCode: http.StatusUnauthorized},
+		{
+			name:       "earlier refunds are deducted",
+			order:      accepted,
+			refunds:    []db.Refund{{OrderID: "order-1", Amount: 5}},
+			wantCode:   http.StatusOK,
+			wantRefund: &db.Refund{OrderID: "order-1", Amount: 6.7, Reason: "changed my mind", Operator: "api key till", CouponReleased: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			oc := dbmocks.NewMockOrderDao(ctrl)
+			oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(tt.order, nil)
+			if tt.wantCode == http.StatusOK {
+				if tt.order.Status == db.OrderAccepted {
+					oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return(tt.refunds, nil)
+				}
+				oc.EXPECT().CancelOrder(gomock.Any(), db.ID("order-1"), "changed my mind", cancellableStatuses, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ db.ID, _ string, _ []string, refund *db.Refund) error {
+						if tt.wantRefund == nil {
+							assert.Nil(t, refund)
+							return nil
+						}
+						assert.NotEmpty(t, refund.ID)
+						refund.ID, refund.CreatedAt = "", time.Time{}
+						assert.Equal(t, tt.wantRefund, refund)
+						return nil
+					})
+				cancelled := tt.order
+				cancelled.Status = db.OrderCancelled
+				oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(cancelled, nil)
+				oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return(nil, nil)
+			}
+			svc := NewOrderAPIServiceWithCouponDao(oc, dbmocks.NewMockProductDao(ctrl), &testCouponDao{}, dbmocks.NewMockQuoteDao(ctrl))
+
+			ctx := context.WithValue(context.Background(), keyHolderKey{}, "api key till")
+			if tt.anonymous {
+				ctx = context.Background()
+			}
+			res, err := svc.CancelOrder(ctx, "order-1", openapi.CancelOrderReq{Reason: "changed my mind", Operator: "someone else"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, db.OrderCancelled, res.Body.(openapi.Order).Status)
+			}
+		})
+	}
+}
+
+func TestRefundOrder(t *testing.T) {
+	order := db.Order{ID: "order-1", Status: db.OrderAccepted, CouponCode: "HAPPYHRS", Discounts: 2, Items: []db.Item{
+		{ProductID: "1", Quantity: 2, Name: "Waffle", Category: "Waffle", UnitPrice: 6},
+		{ProductID: "2", Quantity: 1, Name: "Pie", Category: "Pie", UnitPrice: 8},
+	}}
+	tests := []struct {
+		name    string
+		req     openapi.RefundReq
+		refunds []db.Refund
+		// daoErr is what storing the refund fails with.
+		daoErr error
+		// redeemed is false when the coupon redemption was given back already.
+		redeemed    *bool
+		wantCode    int
+		wantAmount  float32
+		wantRelease bool
+	}{
+		{name: "lines are refunded at the discounted price", req: openapi.RefundReq{Lines: []openapi.RefundLine{{ProductId: "1", Quantity: 1}}}, wantCode: http.StatusOK, wantAmount: 5.4},
+		{name: "amount", req: openapi.RefundReq{Amount: 3.5}, wantCode: http.StatusOK, wantAmount: 3.5},
+		{name: "everything left", refunds: []db.Refund{{Amount: 10}}, wantCode: http.StatusOK, wantAmount: 8, wantRelease: true},
+		{name: "coupon already given back", refunds: []db.Refund{{Amount: 10}}, redeemed: new(bool), wantCode: http.StatusOK, wantAmount: 8, wantRelease: true},
+		{name: "amount over what was paid", req: openapi.RefundReq{Amount: 18.5}, wantCode: http.StatusUnprocessableEntity},
+		{name: "amount and lines", req: openapi.RefundReq{Amount: 1, Lines: []openapi.RefundLine{{ProductId: "1", Quantity: 1}}}, wantCode: http.StatusBadRequest},
+		{name: "product not in order", req: openapi.RefundReq{Lines: []openapi.RefundLine{{ProductId: "3", Quantity: 1}}}, wantCode: http.StatusBadRequest},
+		{
+			name:     "line already refunded",
+			req:      openapi.RefundReq{Lines: []openapi.RefundLine{{ProductId: "2", Quantity: 1}}},
+			refunds:  []db.Refund{{Amount: 7.2, Lines: []db.RefundLine{{ProductID: "2", Quantity: 1}}}},
+			daoErr:   &db.RefundQuantityError{OrderID: "order-1", ProductID: "2"},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{name: "nothing left", refunds: []db.Refund{{Amount: 18}}, wantCode: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			oc := dbmocks.NewMockOrderDao(ctrl)
+			oc.EXPECT().GetOrder(gomock.Any(), db.ID("order-1")).Return(order, nil)
+			oc.EXPECT().GetRefunds(gomock.Any(), db.ID("order-1")).Return(tt.refunds, nil)
+			if tt.wantCode == http.StatusOK {
+				oc.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, refund db.Refund) (db.Refund, error) {
+					assert.Equal(t, tt.wantAmount, refund.Amount)
+					assert.Equal(t, tt.wantRelease, refund.CouponReleased)
+					if tt.redeemed != nil {
+						refund.CouponReleased = *tt.redeemed
+					}
+					return refund, nil
+				})
+			} else if tt.daoErr != nil {
+				oc.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).Return(db.Refund{}, tt.daoErr)
+			}
+			svc := NewOrderAPIServiceWithCouponDao(oc, dbmocks.NewMockProductDao(ctrl), &testCouponDao{}, dbmocks.NewMockQuoteDao(ctrl),
+				WithCouponRelease(CouponReleaseRefund))
+
+			tt.req.Reason, tt.req.Operator = "cold food", "jane"
+			res, err := svc.RefundOrder(context.Background(), "order-1", tt.req)
+			assert.NoError(t, err)
 			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantAmount, res.Body.(openapi.Refund).Amount)
+				assert.Equal(t, tt.wantRelease && tt.redeemed == nil, res.Body.(openapi.Refund).CouponReleased)
+			}
 		})
 	}
 }
+
+func TestListRefunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	oc.EXPECT().GetRefundsBetween(gomock.Any(), to.Add(-24*time.Hour), to).Return([]db.Refund{
+		{ID: "refund-1", OrderID: "order-1", Amount: 2.5},
+		{ID: "refund-2", OrderID: "order-2", Amount: 4.25},
+	}, nil)
+	svc := NewOrderAPIServiceWithCouponDao(oc, dbmocks.NewMockProductDao(ctrl), &testCouponDao{}, dbmocks.NewMockQuoteDao(ctrl))
+
+	res, err := svc.ListRefunds(context.Background(), time.Time{}, to)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	report := res.Body.(openapi.RefundReport)
+	assert.Equal(t, int32(2), report.Count)
+	assert.Equal(t, float32(6.75), report.Total)
+
+	res, err = svc.ListRefunds(context.Background(), to, to)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
@@ -3,57 +3,284 @@ package services
 import (
 	"backend-challenge/internal/db"
 	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/images"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
+	"os"
+	"strings"
+	"unicode"
 )
 
 // ProductAPIService implements business logic for the ProductAPI defined by the generated OpenAPI.
-// It exposes product listing and lookup functionality using a `db.ProductDao`.
+// It exposes product listing and lookup functionality using a `db.ProductDao`,
+// marking products whose stock or ingredients have run out as unavailable, and
+// takes uploaded product pictures.
 // Methods are wired in generated API router as an implementation of ProductAPIServicer.
 type ProductAPIService struct {
-	productDao db.ProductDao
+	productDao    db.ProductDao
+	stockDao      db.StockDao
+	ingredientDao db.IngredientDao
+	// imageBase is the URL product image paths are relative to.
+	imageBase string
+	// uploads makes the renditions of uploaded pictures; nil turns uploads off.
+	uploads *images.Store
+	// cacheControl is sent with product reads; empty sends none.
+	cacheControl string
+}
+
+// ProductAPIServiceOption configures optional behaviour of a ProductAPIService.
+type ProductAPIServiceOption func(*ProductAPIService)
+
+// WithProductCacheControl sets the Cache-Control header of product reads,
+// e.g. "no-cache" or "max-age=60".
+func WithProductCacheControl(value string) ProductAPIServiceOption {
+	return func(s *ProductAPIService) {
+		s.cacheControl = value
+	}
 }
 
 // NewProductAPIService creates a default api service
-func NewProductAPIService(productDao db.ProductDao) *ProductAPIService {
-	return &ProductAPIService{productDao: productDao}
+func NewProductAPIService(productDao db.ProductDao, stockDao db.StockDao, ingredientDao db.IngredientDao, imageBaseURL string, uploads *images.Store, opts ...ProductAPIServiceOption) *ProductAPIService {
+	s := &ProductAPIService{productDao: productDao, stockDao: stockDao, ingredientDao: ingredientDao, imageBase: imageBaseURL, uploads: uploads}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // ListProducts - List products
-func (s *ProductAPIService) ListProducts(ctx context.Context) (openapi.ImplResponse, error) {
-	products, err := s.productDao.GetAllProducts(ctx)
+func (s *ProductAPIService) ListProducts(ctx context.Context, category string, minPrice float32, maxPrice float32, q string, onlyAvailable bool, sort string, limit int32, cursor string, ifNoneMatch string, ifModifiedSince string) (openapi.ImplResponse, error) {
+	if maxPrice > 0 && minPrice > maxPrice {
+		return s.withCacheControl(openapi.Response(http.StatusBadRequest, "minPrice is above maxPrice")), nil
+	}
+	filter := db.ProductFilter{
+		// Categories are found by slug, which a category's name makes too.
+		Category:  db.Slug(category),
+		MinPrice:  minPrice,
+		MaxPrice:  maxPrice,
+		Name:      q,
+		Available: onlyAvailable,
+		Sort:      sort,
+		Limit:     int(limit),
+		Cursor:    cursor,
+	}
+	// Dated before it is read, so a change made meanwhile isn't missed.
+	modified, err := s.productDao.CatalogChangedAt(ctx)
 	if err != nil {
 		return openapi.Response(http.StatusInternalServerError, nil), err
 	}
-	openapiProducts := make([]openapi.Product, 0, len(products))
-	for _, p := range products {
-		openapiProduct := openapi.Product{
-			Id:       p.Id,
-			Name:     p.Name,
-			Price:    p.Price,
-			Category: p.Category,
+	// Names without a slug are of no category, so nothing is in them.
+	res := openapi.Response(http.StatusOK, []openapi.Product{})
+	if category == "" || filter.Category != "" {
+		res, err = s.findProducts(ctx, filter)
+		if err != nil {
+			return res, err
+		} else if res.Code != http.StatusOK {
+			return s.withCacheControl(res), nil
 		}
-		openapiProducts = append(openapiProducts, openapiProduct)
+	}
+	return s.conditional(modified, listETag(res), ifNoneMatch, ifModifiedSince, res), nil
+}
+
+// findProducts responds with a page of the products on sale that pass filter,
+// and the cursor of the next page in X-Next-Cursor.
+func (s *ProductAPIService) findProducts(ctx context.Context, filter db.ProductFilter) (openapi.ImplResponse, error) {
+	switch filter.Sort {
+	case "", db.SortByMenu, db.SortByName, db.SortByNameDesc, db.SortByPrice, db.SortByPriceDesc, db.SortByPopularity:
+	default:
+		return openapi.Response(http.StatusBadRequest, fmt.Sprintf("unknown sort %q", filter.Sort)), nil
+	}
+	products, next, err := s.productDao.FindProducts(ctx, filter)
+	var invalid *db.InvalidCursorError
+	if errors.As(err, &invalid) {
+		return openapi.Response(http.StatusBadRequest, err.Error()), nil
+	} else if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	openapiProducts, err := s.withAvailabilities(ctx, products)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	if next != "" {
+		return openapi.ResponseWithHeaders(http.StatusOK, map[string][]string{"X-Next-Cursor": {next}}, openapiProducts), nil
 	}
 	return openapi.Response(http.StatusOK, openapiProducts), nil
 }
 
-// GetProduct - Find product by ID
-func (s *ProductAPIService) GetProduct(ctx context.Context, productId int64) (openapi.ImplResponse, error) {
-	productIdStr := strconv.FormatInt(productId, 10)
-	product, err := s.productDao.GetProduct(ctx, db.ID(productIdStr))
+// SearchProducts - Search products
+func (s *ProductAPIService) SearchProducts(ctx context.Context, q string, limit int32) (openapi.ImplResponse, error) {
+	if strings.IndexFunc(q, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) < 0 {
+		return openapi.Response(http.StatusBadRequest, "q has no words to search for"), nil
+	}
+	found, err := s.productDao.SearchProducts(ctx, q, int(limit))
+	if errors.Is(err, db.ErrSearchUnavailable) {
+		return openapi.Response(http.StatusNotImplemented, err.Error()), nil
+	} else if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	products := make([]db.Product, len(found.Hits))
+	for i, hit := range found.Hits {
+		products[i] = hit.Product
+	}
+	openapiProducts, err := s.withAvailabilities(ctx, products)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res := openapi.ProductSearch{Query: found.Query, Results: make([]openapi.ProductSearchHit, len(found.Hits))}
+	for i, hit := range found.Hits {
+		res.Results[i] = openapi.ProductSearchHit{Product: openapiProducts[i], Snippet: hit.Snippet, Score: hit.Score}
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
+
+// GetProduct - Find product by ID or slug
+func (s *ProductAPIService) GetProduct(ctx context.Context, productId string, ifNoneMatch string, ifModifiedSince string) (openapi.ImplResponse, error) {
+	if !db.ValidID(productId) {
+		return malformedProductID()
+	}
+	modified, err := s.productDao.CatalogChangedAt(ctx)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	// IDs win over slugs, which are made not to be another product's ID.
+	product, err := s.productDao.GetProduct(ctx, db.ID(productId))
+	if errors.Is(err, sql.ErrNoRows) {
+		product, err = s.productDao.GetProductBySlug(ctx, productId)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return openapi.Response(http.StatusNotFound, nil), nil
+		}
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res, err := s.withAvailability(ctx, product)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	etag := productReadETag(product.Version, res)
+	return s.conditional(modified, etag, ifNoneMatch, ifModifiedSince, openapi.Response(http.StatusOK, res)), nil
+}
+
+// withAvailabilities builds the API representation of products on sale,
+// checking which can be sold right now.
+func (s *ProductAPIService) withAvailabilities(ctx context.Context, products []db.Product) ([]openapi.Product, error) {
+	stock, err := s.stockDao.GetStock(ctx, nil)
 	if err != nil {
-		if _, ok := err.(db.ErrNotFound); ok {
+		return nil, err
+	}
+	short, err := s.ingredientDao.GetShortProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]openapi.Product, 0, len(products))
+	for _, p := range products {
+		product := productResponse(p, s.imageBase)
+		product.Available = available(stock, short, p.Id)
+		res = append(res, product)
+	}
+	return res, nil
+}
+
+// withAvailability builds the API representation of a single product,
+// checking whether it can be sold right now.
+func (s *ProductAPIService) withAvailability(ctx context.Context, product db.Product) (openapi.Product, error) {
+	stock, err := s.stockDao.GetStock(ctx, []db.ID{product.Id})
+	if err != nil {
+		return openapi.Product{}, err
+	}
+	short, err := s.ingredientDao.GetShortProducts(ctx)
+	if err != nil {
+		return openapi.Product{}, err
+	}
+	res := productResponse(product, s.imageBase)
+	res.Available = !res.Archived && available(stock, short, product.Id)
+	return res, nil
+}
+
+// UploadProductImage - Upload a product image
+func (s *ProductAPIService) UploadProductImage(ctx context.Context, productId string, file *os.File) (openapi.ImplResponse, error) {
+	defer os.Remove(file.Name())
+	if s.uploads == nil {
+		return openapi.Response(http.StatusNotImplemented, "image uploads are not configured"), nil
+	}
+	if !db.ValidID(productId) {
+		return malformedProductID()
+	}
+	id := db.ID(productId)
+	if _, err := s.productDao.GetProduct(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
 			return openapi.Response(http.StatusNotFound, nil), nil
 		}
 		return openapi.Response(http.StatusInternalServerError, nil), err
 	}
-	openapiProduct := openapi.Product{
-		Id:       product.Id,
-		Name:     product.Name,
-		Price:    product.Price,
-		Category: product.Category,
+
+	// The generated controller hands over the upload closed.
+	original, err := os.Open(file.Name())
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	defer original.Close()
+	urls, err := s.uploads.Save(original)
+	switch {
+	case errors.Is(err, images.ErrTooLarge):
+		return openapi.Response(http.StatusRequestEntityTooLarge, err.Error()), nil
+	case errors.Is(err, images.ErrUnsupportedType):
+		return openapi.Response(http.StatusUnsupportedMediaType, err.Error()), nil
+	case errors.Is(err, images.ErrInvalid):
+		return openapi.Response(http.StatusUnprocessableEntity, err.Error()), nil
+	case err != nil:
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+
+	// Sizes that are no longer configured lose their old picture rather
+	// than keep one of something else.
+	for _, variant := range images.Variants {
+		if url, ok := urls[variant]; ok {
+			err = s.productDao.SetProductImage(ctx, id, variant, url)
+		} else {
+			err = s.productDao.DeleteProductImage(ctx, id, variant)
+		}
+		if err != nil {
+			return openapi.Response(http.StatusInternalServerError, nil), err
+		}
+	}
+	return s.GetProduct(ctx, productId, "", "")
+}
+
+// productResponse builds the API representation of a catalog product.
+func productResponse(p db.Product, imageBase string) openapi.Product {
+	res := openapi.Product{
+		Id:           p.Id,
+		Slug:         p.Slug,
+		Name:         p.Name,
+		Price:        p.Price,
+		Category:     p.Category,
+		CategorySlug: p.CategorySlug,
+		Description:  p.Description,
+		Tags:         p.Tags,
+		Image:        productImage(imageBase, p.Images),
+		Version:      p.Version,
+		Archived:     !p.ArchivedAt.IsZero(),
 	}
-	return openapi.Response(http.StatusOK, openapiProduct), nil
+	for _, group := range p.ModifierGroups {
+		g := openapi.ModifierGroup{
+			Id:            group.ID,
+			Name:          group.Name,
+			Required:      group.Required,
+			MinSelections: group.MinSelect,
+			MaxSelections: group.MaxSelect,
+		}
+		for _, option := range group.Options {
+			g.Options = append(g.Options, modifierResponse(option))
+		}
+		res.ModifierGroups = append(res.ModifierGroups, g)
+	}
+	return res
+}
+
+func modifierResponse(m db.Modifier) openapi.Modifier {
+	return openapi.Modifier{Id: m.ID, Name: m.Name, PriceDelta: m.PriceDelta}
 }
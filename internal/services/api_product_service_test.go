@@ -1,11 +1,17 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"image"
+	"image/png"
 	"net/http"
-	"strconv"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -13,18 +19,28 @@ import (
 	db "backend-challenge/internal/db"
 	dbmocks "backend-challenge/internal/db/mocks"
 	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/images"
 )
 
 func TestGetProduct(t *testing.T) {
+	notFound := db.ErrNotFound(sql.ErrNoRows)
+	one := db.Product{Id: "1", Slug: "product-one", Name: "Product One", Price: 100, Category: "cat"}
 	tests := []struct {
 		name      string
-		productID int64
+		productID string
 		product   db.Product
 		prodErr   error
-		wantCode  int
+		// bySlug and slugErr are what looking productID up as a slug returns,
+		// once no product has it as its ID.
+		bySlug   db.Product
+		slugErr  error
+		wantCode int
 	}{
-		{name: "product not exist", productID: int64(999), product: db.Product{}, prodErr: db.ErrNotFound(sql.ErrNoRows), wantCode: http.StatusNotFound},
-		{name: "product exists", productID: int64(1), product: db.Product{Id: "1", Name: "Product One", Price: 100, Category: "cat"}, prodErr: nil, wantCode: http.StatusOK},
+		{name: "product not exist", productID: "999", prodErr: notFound, slugErr: notFound, wantCode: http.StatusNotFound},
+		{name: "product exists", productID: "1", product: one, prodErr: nil, wantCode: http.StatusOK},
+		{name: "opaque string ID", productID: "SKU_12.b", product: db.Product{Id: "SKU_12.b", Name: "Product Two", Category: "cat"}, wantCode: http.StatusOK},
+		{name: "slug", productID: "product-one", prodErr: notFound, bySlug: one, wantCode: http.StatusOK},
+		{name: "malformed ID", productID: "one two", wantCode: http.StatusBadRequest},
 	}
 
 	for _, tt := range tests {
@@ -32,13 +48,27 @@ func TestGetProduct(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			pd := dbmocks.NewMockProductDao(ctrl)
-			pd.EXPECT().GetProduct(gomock.Any(), db.ID(strconv.FormatInt(tt.productID, 10))).Return(tt.product, tt.prodErr).Times(1)
-			svc := NewProductAPIService(pd)
-			res, err := svc.GetProduct(context.Background(), tt.productID)
+			pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+			if tt.wantCode != http.StatusBadRequest {
+				pd.EXPECT().GetProduct(gomock.Any(), db.ID(tt.productID)).Return(tt.product, tt.prodErr).Times(1)
+			}
+			if tt.prodErr != nil {
+				pd.EXPECT().GetProductBySlug(gomock.Any(), tt.productID).Return(tt.bySlug, tt.slugErr).Times(1)
+			}
+			sd := dbmocks.NewMockStockDao(ctrl)
+			sd.EXPECT().GetStock(gomock.Any(), gomock.Any()).Return(map[db.ID]db.Stock{}, nil).AnyTimes()
+			id := dbmocks.NewMockIngredientDao(ctrl)
+			id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil).AnyTimes()
+			svc := NewProductAPIService(pd, sd, id, "", nil)
+			res, err := svc.GetProduct(context.Background(), tt.productID, "", "")
 			if err != nil {
 				t.Fatalf("Service error: %v", err)
 			}
 			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantCode == http.StatusOK && tt.bySlug.Id != "" {
+				assert.Equal(t, tt.bySlug.Id, res.Body.(openapi.Product).Id)
+				assert.Equal(t, tt.bySlug.Slug, res.Body.(openapi.Product).Slug)
+			}
 		})
 	}
 }
@@ -60,9 +90,14 @@ func TestListProducts(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			pd := dbmocks.NewMockProductDao(ctrl)
-			pd.EXPECT().GetAllProducts(gomock.Any()).Return(tt.products, tt.prodErr).Times(1)
-			svc := NewProductAPIService(pd)
-			res, err := svc.ListProducts(context.Background())
+			pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+			pd.EXPECT().FindProducts(gomock.Any(), db.ProductFilter{}).Return(tt.products, "", tt.prodErr).Times(1)
+			sd := dbmocks.NewMockStockDao(ctrl)
+			sd.EXPECT().GetStock(gomock.Any(), nil).Return(map[db.ID]db.Stock{}, nil)
+			id := dbmocks.NewMockIngredientDao(ctrl)
+			id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil)
+			svc := NewProductAPIService(pd, sd, id, "", nil)
+			res, err := svc.ListProducts(context.Background(), "", 0, 0, "", false, "", 0, "", "", "")
 			if err != nil {
 				t.Fatalf("Service error: %v", err)
 			}
@@ -77,3 +112,218 @@ func TestListProducts(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProductModifierGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pd := dbmocks.NewMockProductDao(ctrl)
+	pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+	pd.EXPECT().GetProduct(gomock.Any(), db.ID("1")).Return(db.Product{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle", ModifierGroups: []db.ModifierGroup{
+		{ID: "size", Name: "Size", Required: true, MinSelect: 1, MaxSelect: 1, Options: []db.Modifier{{ID: "large", GroupID: "size", Name: "Large", PriceDelta: 1.5}}},
+	}}, nil)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Stock{}, nil)
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil)
+	res, err := NewProductAPIService(pd, sd, id, "", nil).GetProduct(context.Background(), "1", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []openapi.ModifierGroup{
+		{Id: "size", Name: "Size", Required: true, MinSelections: 1, MaxSelections: 1, Options: []openapi.Modifier{{Id: "large", Name: "Large", PriceDelta: 1.5}}},
+	}, res.Body.(openapi.Product).ModifierGroups)
+}
+
+func TestListProductsAvailability(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pd := dbmocks.NewMockProductDao(ctrl)
+	pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+	pd.EXPECT().FindProducts(gomock.Any(), db.ProductFilter{}).Return([]db.Product{
+		{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"},
+		{Id: "2", Name: "Brownie", Price: 5.5, Category: "Brownie"},
+		{Id: "3", Name: "Macaron", Price: 8, Category: "Macaron"},
+		{Id: "4", Name: "Pistachio Baklava", Price: 4, Category: "Baklava"},
+	}, "", nil)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), nil).Return(map[db.ID]db.Stock{
+		"2": {ProductID: "2", OnHand: 0},
+		"3": {ProductID: "3", OnHand: 4, LowThreshold: 5},
+	}, nil)
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{"4": true}, nil)
+
+	res, err := NewProductAPIService(pd, sd, id, "", nil).ListProducts(context.Background(), "", 0, 0, "", false, "", 0, "", "", "")
+	assert.NoError(t, err)
+	availability := map[string]bool{}
+	for _, product := range res.Body.([]openapi.Product) {
+		availability[product.Id] = product.Available
+	}
+	assert.Equal(t, map[string]bool{"1": true, "2": false, "3": true, "4": false}, availability)
+}
+
+func TestGetProductImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pd := dbmocks.NewMockProductDao(ctrl)
+	pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+	pd.EXPECT().GetProduct(gomock.Any(), db.ID("1")).Return(db.Product{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle", Images: map[string]string{
+		db.ImageThumbnail: "waffle-thumbnail.jpg",
+		db.ImageMobile:    "/waffle-mobile.jpg",
+		db.ImageDesktop:   "https://images.example.com/waffle-desktop.jpg",
+	}}, nil)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Stock{}, nil)
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil)
+	res, err := NewProductAPIService(pd, sd, id, "https://cdn.example.com/images/", nil).GetProduct(context.Background(), "1", "", "")
+	assert.NoError(t, err)
+	// The tablet picture is missing, and absolute URLs are kept as they are.
+	assert.Equal(t, openapi.ProductImage{
+		Thumbnail: "https://cdn.example.com/images/waffle-thumbnail.jpg",
+		Mobile:    "https://cdn.example.com/images/waffle-mobile.jpg",
+		Desktop:   "https://images.example.com/waffle-desktop.jpg",
+	}, res.Body.(openapi.Product).Image)
+}
+
+func TestUploadProductImage(t *testing.T) {
+	store, err := images.New(images.Config{
+		Dir:      t.TempDir(),
+		URL:      "http://localhost:8080/images/",
+		Variants: map[string]images.Size{db.ImageThumbnail: {Width: 10, Height: 10}, db.ImageDesktop: {Width: 40}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload := func(t *testing.T, data []byte) *os.File {
+		f, err := os.CreateTemp(t.TempDir(), "upload")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(data)
+		f.Close()
+		return f
+	}
+	var original bytes.Buffer
+	png.Encode(&original, image.NewGray(image.Rect(0, 0, 60, 30)))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pd := dbmocks.NewMockProductDao(ctrl)
+	pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), gomock.Any()).Return(map[db.ID]db.Stock{}, nil).AnyTimes()
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil).AnyTimes()
+	svc := NewProductAPIService(pd, sd, id, "https://cdn.example.com/", store)
+
+	t.Run("unknown product", func(t *testing.T) {
+		pd.EXPECT().GetProduct(gomock.Any(), db.ID("9")).Return(db.Product{}, db.ErrNotFound(sql.ErrNoRows))
+		res, err := svc.UploadProductImage(context.Background(), "9", upload(t, original.Bytes()))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+	t.Run("not an image", func(t *testing.T) {
+		pd.EXPECT().GetProduct(gomock.Any(), db.ID("1")).Return(db.Product{Id: "1"}, nil)
+		res, err := svc.UploadProductImage(context.Background(), "1", upload(t, []byte("%PDF-1.4")))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+	})
+	t.Run("stores renditions", func(t *testing.T) {
+		stored := map[string]string{}
+		pd.EXPECT().GetProduct(gomock.Any(), db.ID("1")).Return(db.Product{Id: "1"}, nil)
+		pd.EXPECT().SetProductImage(gomock.Any(), db.ID("1"), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ db.ID, variant, path string) error {
+			stored[variant] = path
+			return nil
+		}).Times(2)
+		pd.EXPECT().DeleteProductImage(gomock.Any(), db.ID("1"), db.ImageMobile).Return(nil)
+		pd.EXPECT().DeleteProductImage(gomock.Any(), db.ID("1"), db.ImageTablet).Return(nil)
+		pd.EXPECT().GetProduct(gomock.Any(), db.ID("1")).DoAndReturn(func(context.Context, db.ID) (db.Product, error) {
+			return db.Product{Id: "1", Images: stored}, nil
+		})
+		file := upload(t, original.Bytes())
+		res, err := svc.UploadProductImage(context.Background(), "1", file)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.Code)
+		pictures := res.Body.(openapi.Product).Image
+		assert.Regexp(t, `^http://localhost:8080/images/[0-9a-f]+-thumbnail\.jpg$`, pictures.Thumbnail)
+		assert.Regexp(t, `^http://localhost:8080/images/[0-9a-f]+-desktop\.jpg$`, pictures.Desktop)
+		assert.Empty(t, pictures.Mobile)
+		_, err = os.Stat(file.Name())
+		assert.True(t, os.IsNotExist(err), "the upload is removed")
+
+		// The renditions are served with long-lived cache headers.
+		router := openapi.NewRouter(NewImageController(store))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(pictures.Thumbnail, "http://localhost:8080"), nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/jpeg", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Cache-Control"), "immutable")
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/images/missing.jpg", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestListProductsQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pd := dbmocks.NewMockProductDao(ctrl)
+	pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), nil).Return(map[db.ID]db.Stock{}, nil).AnyTimes()
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil).AnyTimes()
+	router := openapi.NewRouter(openapi.NewProductAPIController(NewProductAPIService(pd, sd, id, "", nil)))
+	serve := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/product?"+query, nil))
+		return rec
+	}
+
+	pd.EXPECT().FindProducts(gomock.Any(), db.ProductFilter{
+		Category: "waffle", MinPrice: 5, MaxPrice: 7.5, Name: "berr", Available: true,
+		Sort: db.SortByPopularity, Limit: 2, Cursor: "abc",
+	}).Return([]db.Product{{Id: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle"}}, "def", nil)
+	rec := serve("category=Waffle&minPrice=5&maxPrice=7.5&q=berr&available=true&sort=popularity&limit=2&cursor=abc")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "def", rec.Header().Get("X-Next-Cursor"))
+
+	pd.EXPECT().FindProducts(gomock.Any(), db.ProductFilter{Sort: db.SortByMenu, Cursor: "stale"}).Return(nil, "", &db.InvalidCursorError{Cursor: "stale"})
+	assert.Equal(t, http.StatusBadRequest, serve("cursor=stale").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("sort=colour").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("minPrice=8&maxPrice=7").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("limit=500").Code)
+	assert.Equal(t, "[]\n", serve("category=%E2%98%95").Body.String(), "no category has a name without letters")
+}
+
+func TestSearchProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pd := dbmocks.NewMockProductDao(ctrl)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), nil).Return(map[db.ID]db.Stock{"1": {ProductID: "1", OnHand: 0}}, nil)
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil)
+	svc := NewProductAPIService(pd, sd, id, "", nil)
+
+	pd.EXPECT().SearchProducts(gomock.Any(), "wafle", 20).Return(db.ProductSearch{Query: "waffle", Hits: []db.ProductHit{
+		{Product: db.Product{Id: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle", Tags: []string{"breakfast"}}, Snippet: "<mark>Waffle</mark> with Berries", Score: 2.5},
+	}}, nil)
+	res, err := svc.SearchProducts(context.Background(), "wafle", 20)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, openapi.ProductSearch{Query: "waffle", Results: []openapi.ProductSearchHit{{
+		Product: openapi.Product{Id: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle", Tags: []string{"breakfast"}, Available: false},
+		Snippet: "<mark>Waffle</mark> with Berries",
+		Score:   2.5,
+	}}}, res.Body)
+
+	res, err = svc.SearchProducts(context.Background(), " *? ", 20)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	// The route is matched before /api/product/{productId}.
+	pd.EXPECT().SearchProducts(gomock.Any(), "waffle", 20).Return(db.ProductSearch{}, db.ErrSearchUnavailable)
+	rec := httptest.NewRecorder()
+	openapi.NewRouter(openapi.NewProductAPIController(svc)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/product/search?q=waffle", nil))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/slots"
+	"context"
+	"net/http"
+	"time"
+)
+
+// SlotAPIService implements business logic for the SlotAPI defined by the generated OpenAPI.
+// It lists the pickup slots of the schedule with the room `db.SlotDao` says
+// each has left.
+type SlotAPIService struct {
+	schedule *slots.Schedule
+	slotDao  db.SlotDao
+}
+
+// NewSlotAPIService creates a default api service. A nil schedule has no slots.
+func NewSlotAPIService(schedule *slots.Schedule, slotDao db.SlotDao) *SlotAPIService {
+	return &SlotAPIService{schedule: schedule, slotDao: slotDao}
+}
+
+// ListSlots - List pickup slots
+func (s *SlotAPIService) ListSlots(ctx context.Context, date string) (openapi.ImplResponse, error) {
+	now := time.Now()
+	day := now
+	if date != "" {
+		var err error
+		if day, err = time.ParseInLocation(time.DateOnly, date, now.Location()); err != nil {
+			return openapi.Response(http.StatusBadRequest, "date must look like 2024-03-01"), nil
+		}
+	}
+	if s.schedule == nil {
+		return openapi.Response(http.StatusOK, []openapi.PickupSlot{}), nil
+	}
+	starts := s.schedule.Day(day)
+	usage, err := s.slotDao.GetSlotUsage(ctx, starts[0], starts[len(starts)-1].Add(s.schedule.Length))
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	taken := make(map[int64]db.SlotUsage, len(usage))
+	for _, slot := range usage {
+		taken[slot.Start.Unix()] = slot
+	}
+	res := make([]openapi.PickupSlot, 0, len(starts))
+	for _, start := range starts {
+		res = append(res, s.slotResponse(start, taken[start.Unix()], now))
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
+
+// slotResponse describes the slot starting at start. It is available if
+// some time in it passes the lead-time rules and it has room for an order.
+func (s *SlotAPIService) slotResponse(start time.Time, taken db.SlotUsage, now time.Time) openapi.PickupSlot {
+	end := start.Add(s.schedule.Length)
+	res := openapi.PickupSlot{Start: start, End: end}
+	_, errFirst := s.schedule.Check(start, now)
+	_, errLast := s.schedule.Check(end.Add(-time.Minute), now)
+	res.Available = errFirst == nil || errLast == nil
+	if s.schedule.MaxOrders > 0 {
+		res.RemainingOrders = max(s.schedule.MaxOrders-taken.Orders, 0)
+		res.Available = res.Available && res.RemainingOrders > 0
+	}
+	if s.schedule.MaxItems > 0 {
+		res.RemainingItems = max(s.schedule.MaxItems-taken.Items, 0)
+		res.Available = res.Available && res.RemainingItems > 0
+	}
+	return res
+}
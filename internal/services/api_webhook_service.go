@@ -0,0 +1,175 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookAPIService implements business logic for the WebhookAPI defined by the generated OpenAPI.
+// It manages registrations and deliveries through `db.WebhookDao`; sending is
+// left to the WebhookDispatcher.
+type WebhookAPIService struct {
+	webhookDao db.WebhookDao
+}
+
+// NewWebhookAPIService creates a default api service
+func NewWebhookAPIService(webhookDao db.WebhookDao) *WebhookAPIService {
+	return &WebhookAPIService{webhookDao: webhookDao}
+}
+
+// CreateWebhook - Register a webhook
+func (s *WebhookAPIService) CreateWebhook(ctx context.Context, webhookReq openapi.WebhookReq) (openapi.ImplResponse, error) {
+	u, err := url.Parse(webhookReq.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return openapi.Response(http.StatusBadRequest, "url must be an absolute http(s) URL"), nil
+	}
+	if privateHost(u.Hostname()) {
+		return openapi.Response(http.StatusBadRequest, "url must not point at this machine or a private network"), nil
+	}
+	for _, event := range webhookReq.Events {
+		if !validEventType(event) {
+			return openapi.Response(http.StatusBadRequest, "unknown event type: "+event), nil
+		}
+	}
+	webhook := db.Webhook{
+		ID:        uuid.New().String(),
+		URL:       webhookReq.Url,
+		Secret:    webhookReq.Secret,
+		Events:    webhookReq.Events,
+		CreatedAt: time.Now().UTC(),
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return openapi.Response(http.StatusInternalServerError, nil), err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	if err := s.webhookDao.CreateWebhook(ctx, webhook); err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	// The secret is shown once, here; listings leave it out.
+	res := webhookResponse(webhook)
+	res.Secret = webhook.Secret
+	return openapi.Response(http.StatusCreated, res), nil
+}
+
+// privateHost reports whether a webhook host is plainly this machine or on a
+// private network. Names that resolve there are caught when sending.
+func privateHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && privateAddr(addr)
+}
+
+// ListWebhooks - List webhooks
+func (s *WebhookAPIService) ListWebhooks(ctx context.Context) (openapi.ImplResponse, error) {
+	webhooks, err := s.webhookDao.GetWebhooks(ctx)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res := make([]openapi.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		res = append(res, webhookResponse(webhook))
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
+
+// DeleteWebhook - Delete a webhook
+func (s *WebhookAPIService) DeleteWebhook(ctx context.Context, webhookId string) (openapi.ImplResponse, error) {
+	if err := s.webhookDao.DeleteWebhook(ctx, db.ID(webhookId)); err != nil {
+		return webhookFailure(err)
+	}
+	return openapi.Response(http.StatusNoContent, nil), nil
+}
+
+// ListWebhookDeliveries - List deliveries
+func (s *WebhookAPIService) ListWebhookDeliveries(ctx context.Context, webhookId string, status string) (openapi.ImplResponse, error) {
+	switch status {
+	case "", db.DeliveryPending, db.DeliveryDelivered, db.DeliveryDead:
+	default:
+		return openapi.Response(http.StatusBadRequest, "unknown delivery status: "+status), nil
+	}
+	deliveries, err := s.webhookDao.GetDeliveries(ctx, db.ID(webhookId), status)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	res := make([]openapi.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		res = append(res, deliveryResponse(delivery))
+	}
+	return openapi.Response(http.StatusOK, res), nil
+}
+
+// ReplayWebhookDelivery - Replay a delivery
+func (s *WebhookAPIService) ReplayWebhookDelivery(ctx context.Context, webhookId string, deliveryId string) (openapi.ImplResponse, error) {
+	delivery, err := s.webhookDao.GetDelivery(ctx, db.ID(deliveryId))
+	if err != nil {
+		return webhookFailure(err)
+	}
+	if delivery.WebhookID != webhookId {
+		return openapi.Response(http.StatusNotFound, nil), nil
+	}
+	if err := s.webhookDao.ReplayDelivery(ctx, delivery.ID); err != nil {
+		return webhookFailure(err)
+	}
+	if delivery, err = s.webhookDao.GetDelivery(ctx, delivery.ID); err != nil {
+		return webhookFailure(err)
+	}
+	return openapi.Response(http.StatusOK, deliveryResponse(delivery)), nil
+}
+
+func validEventType(eventType string) bool {
+	switch eventType {
+	case db.EventOrderCreated, db.EventOrderAccepted, db.EventOrderRejected, db.EventOrderCancelled, db.EventOrderRefunded, db.EventOrderReleased, db.EventOrderReady,
+		db.EventProductLowStock:
+		return true
+	}
+	return false
+}
+
+func webhookFailure(err error) (openapi.ImplResponse, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.Response(http.StatusNotFound, nil), nil
+	}
+	return openapi.Response(http.StatusInternalServerError, nil), err
+}
+
+func webhookResponse(webhook db.Webhook) openapi.Webhook {
+	return openapi.Webhook{
+		Id:        webhook.ID,
+		Url:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt.UTC(),
+	}
+}
+
+func deliveryResponse(delivery db.Delivery) openapi.WebhookDelivery {
+	return openapi.WebhookDelivery{
+		Id:            delivery.ID,
+		WebhookId:     delivery.WebhookID,
+		EventId:       delivery.Event.ID,
+		EventType:     delivery.Event.Type,
+		OrderId:       delivery.Event.OrderID,
+		Status:        delivery.Status,
+		Attempts:      int32(delivery.Attempts),
+		NextAttemptAt: delivery.NextAttemptAt.UTC(),
+		LastError:     delivery.LastError,
+		UpdatedAt:     delivery.UpdatedAt.UTC(),
+	}
+}
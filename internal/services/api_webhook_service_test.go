@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+func TestWebhookAPIService_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      openapi.WebhookReq
+		wantCode int
+	}{
+		{name: "relative url", req: openapi.WebhookReq{Url: "/orders"}, wantCode: http.StatusBadRequest},
+		{name: "localhost", req: openapi.WebhookReq{Url: "http://localhost:8080/admin"}, wantCode: http.StatusBadRequest},
+		{name: "loopback address", req: openapi.WebhookReq{Url: "http://127.0.0.1/"}, wantCode: http.StatusBadRequest},
+		{name: "private network", req: openapi.WebhookReq{Url: "https://10.1.2.3/hook"}, wantCode: http.StatusBadRequest},
+		{name: "link-local metadata address", req: openapi.WebhookReq{Url: "http://169.254.169.254/latest/meta-data"}, wantCode: http.StatusBadRequest},
+		{name: "IPv6 loopback", req: openapi.WebhookReq{Url: "http://[::1]:9000/"}, wantCode: http.StatusBadRequest},
+		{name: "unknown event", req: openapi.WebhookReq{Url: "https://kitchen.example.com", Events: []string{"order.eaten"}}, wantCode: http.StatusBadRequest},
+		{name: "secret is generated", req: openapi.WebhookReq{Url: "https://kitchen.example.com", Events: []string{db.EventOrderCreated}}, wantCode: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			webhookDao := dbmocks.NewMockWebhookDao(ctrl)
+			if tt.wantCode == http.StatusCreated {
+				webhookDao.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, webhook db.Webhook) error {
+					assert.Len(t, webhook.Secret, 64)
+					return nil
+				})
+			}
+			res, err := NewWebhookAPIService(webhookDao).CreateWebhook(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, res.Code)
+			if tt.wantCode == http.StatusCreated {
+				assert.NotEmpty(t, res.Body.(openapi.Webhook).Secret)
+			}
+		})
+	}
+}
+
+func TestWebhookAPIService_ReplayWebhookDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookDao := dbmocks.NewMockWebhookDao(ctrl)
+	svc := NewWebhookAPIService(webhookDao)
+	dead := db.Delivery{ID: "d1", WebhookID: "hook-1", Status: db.DeliveryDead, Attempts: 8}
+
+	webhookDao.EXPECT().GetDelivery(gomock.Any(), db.ID("missing")).Return(db.Delivery{}, db.ErrNotFound(sql.ErrNoRows))
+	res, err := svc.ReplayWebhookDelivery(context.Background(), "hook-1", "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	webhookDao.EXPECT().GetDelivery(gomock.Any(), db.ID("d1")).Return(dead, nil)
+	res, err = svc.ReplayWebhookDelivery(context.Background(), "hook-2", "d1")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	webhookDao.EXPECT().GetDelivery(gomock.Any(), db.ID("d1")).Return(dead, nil)
+	webhookDao.EXPECT().ReplayDelivery(gomock.Any(), db.ID("d1")).Return(nil)
+	webhookDao.EXPECT().GetDelivery(gomock.Any(), db.ID("d1")).Return(db.Delivery{ID: "d1", WebhookID: "hook-1", Status: db.DeliveryPending}, nil)
+	res, err = svc.ReplayWebhookDelivery(context.Background(), "hook-1", "d1")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, db.DeliveryPending, res.Body.(openapi.WebhookDelivery).Status)
+}
+
+func TestWebhookRoutesRequireAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookDao := dbmocks.NewMockWebhookDao(ctrl)
+	webhookDao.EXPECT().GetWebhooks(gomock.Any()).Return(nil, nil)
+	router := openapi.NewRouter(openapi.NewWebhookAPIController(NewWebhookAPIService(webhookDao)))
+	router.Use(RequireAPIKey(KeysByScope(nil, map[string][]string{"manage_webhooks": {"ops"}, "kitchen": {"tablet"}})))
+
+	serve := func(method, path, key string) int {
+		return serveWithKey(router, method, path, key, `{"url": "https://kitchen.example.com"}`)
+	}
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/webhook"},
+		{http.MethodGet, "/api/webhook"},
+		{http.MethodDelete, "/api/webhook/hook-1"},
+		{http.MethodGet, "/api/webhook/hook-1/delivery"},
+		{http.MethodPost, "/api/webhook/hook-1/delivery/d1/replay"},
+	} {
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, ""), "%s %s", route.method, route.path)
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, "tablet"), "%s %s", route.method, route.path)
+	}
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/webhook", "ops"))
+}
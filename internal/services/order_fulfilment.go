@@ -0,0 +1,188 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	"backend-challenge/internal/delivery"
+	openapi "backend-challenge/internal/generated/openapi"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pickupGrace is how far in the past a takeaway pickup time may be, to allow
+// for clock skew and the time spent filling in the order.
+const pickupGrace = time.Minute
+
+// FulfilmentAvailability limits when a fulfilment type can be chosen.
+type FulfilmentAvailability struct {
+	Disabled bool
+	// From and Until are local times of day such as "21:00". Either may be
+	// empty to leave that end open; an Until earlier than From runs past
+	// midnight.
+	From, Until string
+}
+
+// allows reports whether the type is available at t.
+func (a FulfilmentAvailability) allows(t time.Time) bool {
+	if a.Disabled {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	from, hasFrom := minuteOfDay(a.From)
+	until, hasUntil := minuteOfDay(a.Until)
+	switch {
+	case hasFrom && hasUntil && until < from:
+		return minute >= from || minute < until
+	case hasFrom && minute < from:
+		return false
+	case hasUntil && minute >= until:
+		return false
+	}
+	return true
+}
+
+// minuteOfDay parses a "15:04" time of day into minutes after midnight. It
+// reports false for an empty or malformed value.
+func minuteOfDay(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// WithFulfilmentAvailability sets when each fulfilment type can be chosen.
+// Types without an entry are always available.
+func WithFulfilmentAvailability(availability map[string]FulfilmentAvailability) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		s.fulfilment = availability
+	}
+}
+
+// fulfilmentFor checks the requested fulfilment against the rules of its type
+// and the type's availability at now, or at the time a pre-order is scheduled
+// for or a takeaway is picked up. A scheduled takeaway is picked up at its
+// scheduled time. It returns nil if the request doesn't say how the order is
+// fulfilled.
+func (s *OrderAPIService) fulfilmentFor(req openapi.Fulfilment, scheduledFor, now time.Time) (*db.Fulfilment, error) {
+	if req == (openapi.Fulfilment{}) {
+		return nil, nil
+	}
+	var fulfilment db.Fulfilment
+	at := now
+	if !scheduledFor.IsZero() {
+		at = scheduledFor.In(now.Location())
+	}
+	switch req.Type {
+	case db.FulfilmentDineIn:
+		table := strings.TrimSpace(req.TableNumber)
+		if table == "" {
+			return nil, reject(http.StatusBadRequest, "dine-in orders need a table number")
+		}
+		fulfilment = db.Fulfilment{Type: req.Type, TableNumber: table}
+	case db.FulfilmentTakeaway:
+		if !scheduledFor.IsZero() {
+			if !req.PickupTime.IsZero() && !req.PickupTime.Equal(scheduledFor) {
+				return nil, reject(http.StatusBadRequest, "pickup time and scheduled time differ")
+			}
+			req.PickupTime = scheduledFor
+		}
+		name := strings.TrimSpace(req.PickupName)
+		if name == "" || req.PickupTime.IsZero() {
+			return nil, reject(http.StatusBadRequest, "takeaway orders need a pickup name and time")
+		}
+		if req.PickupTime.Before(now.Add(-pickupGrace)) {
+			return nil, reject(http.StatusUnprocessableEntity, "pickup time is in the past")
+		}
+		at = req.PickupTime.In(now.Location())
+		fulfilment = db.Fulfilment{Type: req.Type, PickupName: name, PickupTime: req.PickupTime.UTC()}
+	case db.FulfilmentDelivery:
+		address := db.Address{
+			Line1:        strings.TrimSpace(req.Address.Line1),
+			Line2:        strings.TrimSpace(req.Address.Line2),
+			City:         strings.TrimSpace(req.Address.City),
+			Postcode:     strings.TrimSpace(req.Address.Postcode),
+			Instructions: strings.TrimSpace(req.Address.Instructions),
+			Lat:          req.Address.Latitude,
+			Lng:          req.Address.Longitude,
+		}
+		if address.Line1 == "" || address.City == "" || address.Postcode == "" {
+			return nil, reject(http.StatusBadRequest, "delivery orders need an address with line1, city and postcode")
+		}
+		if address.Lat < -90 || address.Lat > 90 || address.Lng < -180 || address.Lng > 180 {
+			return nil, reject(http.StatusBadRequest, "delivery address coordinates are out of range")
+		}
+		fulfilment = db.Fulfilment{Type: req.Type, Address: &address}
+	case "":
+		return nil, reject(http.StatusBadRequest, "fulfilment type is required")
+	default:
+		return nil, reject(http.StatusBadRequest, fmt.Sprintf("unknown fulfilment type %q", req.Type))
+	}
+	if availability, ok := s.fulfilment[req.Type]; ok && !availability.allows(at) {
+		return nil, reject(http.StatusUnprocessableEntity, fmt.Sprintf("%s is not available at %s", strings.ReplaceAll(req.Type, "_", "-"), at.Format("15:04")))
+	}
+	return &fulfilment, nil
+}
+
+// WithDeliveryZones sets the zones delivery orders are priced by. Without
+// zones, delivery goes anywhere and is free.
+func WithDeliveryZones(zones *delivery.Zones) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		s.deliveryZones = zones
+	}
+}
+
+// fulfil attaches fulfilment to a priced order and, for delivery, finds the
+// address's zone and charges its fee. Zone minimums and free delivery are
+// judged on the subtotal before any discount.
+func (s *OrderAPIService) fulfil(priced *pricedOrder, fulfilment *db.Fulfilment) error {
+	priced.fulfilment = fulfilment
+	if fulfilment == nil || fulfilment.Type != db.FulfilmentDelivery || s.deliveryZones == nil {
+		return nil
+	}
+	dest := delivery.Destination{Postcode: fulfilment.Address.Postcode}
+	if fulfilment.Address.Lat != 0 || fulfilment.Address.Lng != 0 {
+		dest.Point = &delivery.Point{Lat: fulfilment.Address.Lat, Lng: fulfilment.Address.Lng}
+	}
+	quote, err := s.deliveryZones.Quote(dest, priced.subtotal())
+	var outOfZone *delivery.OutOfZoneError
+	var minimum *delivery.MinimumOrderError
+	if errors.As(err, &outOfZone) || errors.As(err, &minimum) {
+		return reject(http.StatusUnprocessableEntity, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	zoned := *fulfilment
+	zoned.Zone = quote.Zone
+	priced.fulfilment = &zoned
+	priced.deliveryFee = quote.Fee
+	return nil
+}
+
+func fulfilmentResponse(fulfilment *db.Fulfilment) openapi.Fulfilment {
+	if fulfilment == nil {
+		return openapi.Fulfilment{}
+	}
+	res := openapi.Fulfilment{
+		Type:        fulfilment.Type,
+		TableNumber: fulfilment.TableNumber,
+		PickupName:  fulfilment.PickupName,
+		PickupTime:  fulfilment.PickupTime,
+		Zone:        fulfilment.Zone,
+	}
+	if fulfilment.Address != nil {
+		res.Address = openapi.DeliveryAddress{
+			Line1:        fulfilment.Address.Line1,
+			Line2:        fulfilment.Address.Line2,
+			City:         fulfilment.Address.City,
+			Postcode:     fulfilment.Address.Postcode,
+			Instructions: fulfilment.Address.Instructions,
+			Latitude:     fulfilment.Address.Lat,
+			Longitude:    fulfilment.Address.Lng,
+		}
+	}
+	return res
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	"backend-challenge/internal/delivery"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+func TestFulfilmentAvailability_Allows(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name         string
+		availability FulfilmentAvailability
+		at           time.Time
+		want         bool
+	}{
+		{name: "no limits", at: at(3, 0), want: true},
+		{name: "disabled", availability: FulfilmentAvailability{Disabled: true}, at: at(12, 0), want: false},
+		{name: "before opening", availability: FulfilmentAvailability{From: "10:00", Until: "21:00"}, at: at(9, 59), want: false},
+		{name: "open", availability: FulfilmentAvailability{From: "10:00", Until: "21:00"}, at: at(10, 0), want: true},
+		{name: "closed at until", availability: FulfilmentAvailability{From: "10:00", Until: "21:00"}, at: at(21, 0), want: false},
+		{name: "only until", availability: FulfilmentAvailability{Until: "21:00"}, at: at(1, 0), want: true},
+		{name: "past midnight, late", availability: FulfilmentAvailability{From: "18:00", Until: "02:00"}, at: at(23, 30), want: true},
+		{name: "past midnight, early", availability: FulfilmentAvailability{From: "18:00", Until: "02:00"}, at: at(1, 59), want: true},
+		{name: "past midnight, closed", availability: FulfilmentAvailability{From: "18:00", Until: "02:00"}, at: at(12, 0), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.availability.allows(tt.at))
+		})
+	}
+}
+
+func TestFulfilmentFor(t *testing.T) {
+	now := time.Date(2024, 1, 1, 20, 30, 0, 0, time.Local)
+	svc := NewOrderAPIServiceWithCouponDao(nil, nil, &testCouponDao{}, nil, WithFulfilmentAvailability(map[string]FulfilmentAvailability{
+		db.FulfilmentDelivery: {Until: "21:00"},
+		db.FulfilmentTakeaway: {From: "08:00", Until: "22:00"},
+	}))
+	address := openapi.DeliveryAddress{Line1: "1 Collins St", City: "Melbourne", Postcode: "3000"}
+	tests := []struct {
+		name     string
+		req      openapi.Fulfilment
+		now      time.Time
+		want     *db.Fulfilment
+		wantCode int
+	}{
+		{name: "none", now: now},
+		{name: "missing type", req: openapi.Fulfilment{TableNumber: "4"}, now: now, wantCode: http.StatusBadRequest},
+		{name: "unknown type", req: openapi.Fulfilment{Type: "drone"}, now: now, wantCode: http.StatusBadRequest},
+		{name: "dine-in without table", req: openapi.Fulfilment{Type: db.FulfilmentDineIn}, now: now, wantCode: http.StatusBadRequest},
+		{
+			name: "dine-in keeps only its fields",
+			req:  openapi.Fulfilment{Type: db.FulfilmentDineIn, TableNumber: " 4 ", PickupName: "Sam"},
+			now:  now,
+			want: &db.Fulfilment{Type: db.FulfilmentDineIn, TableNumber: "4"},
+		},
+		{name: "takeaway without time", req: openapi.Fulfilment{Type: db.FulfilmentTakeaway, PickupName: "Sam"}, now: now, wantCode: http.StatusBadRequest},
+		{
+			name:     "takeaway in the past",
+			req:      openapi.Fulfilment{Type: db.FulfilmentTakeaway, PickupName: "Sam", PickupTime: now.Add(-time.Hour)},
+			now:      now,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "takeaway after closing",
+			req:      openapi.Fulfilment{Type: db.FulfilmentTakeaway, PickupName: "Sam", PickupTime: now.Add(2 * time.Hour)},
+			now:      now,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "takeaway",
+			req:  openapi.Fulfilment{Type: db.FulfilmentTakeaway, PickupName: "Sam", PickupTime: now.Add(time.Hour)},
+			now:  now,
+			want: &db.Fulfilment{Type: db.FulfilmentTakeaway, PickupName: "Sam", PickupTime: now.Add(time.Hour).UTC()},
+		},
+		{
+			name:     "delivery without postcode",
+			req:      openapi.Fulfilment{Type: db.FulfilmentDelivery, Address: openapi.DeliveryAddress{Line1: "1 Collins St", City: "Melbourne"}},
+			now:      now,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "delivery",
+			req:  openapi.Fulfilment{Type: db.FulfilmentDelivery, Address: address},
+			now:  now,
+			want: &db.Fulfilment{Type: db.FulfilmentDelivery, Address: &db.Address{Line1: "1 Collins St", City: "Melbourne", Postcode: "3000"}},
+		},
+		{
+			name: "delivery with coordinates",
+			req: openapi.Fulfilment{Type: db.FulfilmentDelivery, Address: openapi.DeliveryAddress{
+				Line1: "1 Collins St", City: "Melbourne", Postcode: "3000", Latitude: -37.8136, Longitude: 144.9631,
+			}},
+			now: now,
+			want: &db.Fulfilment{Type: db.FulfilmentDelivery, Address: &db.Address{
+				Line1: "1 Collins St", City: "Melbourne", Postcode: "3000", Lat: -37.8136, Lng: 144.9631,
+			}},
+		},
+		{
+			name: "delivery with bad coordinates",
+			req: openapi.Fulfilment{Type: db.FulfilmentDelivery, Address: openapi.DeliveryAddress{
+				Line1: "1 Collins St", City: "Melbourne", Postcode: "3000", Latitude: 144.9631, Longitude: -37.8136,
+			}},
+			now:      now,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "delivery after hours",
+			req:      openapi.Fulfilment{Type: db.FulfilmentDelivery, Address: address},
+			now:      now.Add(time.Hour),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.fulfilmentFor(tt.req, time.Time{}, tt.now)
+			if tt.wantCode != 0 {
+				var rejection *orderRejection
+				if assert.ErrorAs(t, err, &rejection) {
+					assert.Equal(t, tt.wantCode, rejection.code)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlaceOrderWithFulfilment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, nil)
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		assert.Equal(t, &db.Fulfilment{Type: db.FulfilmentDineIn, TableNumber: "4"}, order.Fulfilment)
+		return nil
+	})
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl), WithFulfilmentAvailability(map[string]FulfilmentAvailability{
+		db.FulfilmentDelivery: {Disabled: true},
+	}))
+	items := []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}}
+
+	res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: items, Fulfilment: openapi.Fulfilment{
+		Type: db.FulfilmentDelivery, Address: openapi.DeliveryAddress{Line1: "1 Collins St", City: "Melbourne", Postcode: "3000"},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	res, err = svc.PlaceOrder(context.Background(), "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: items, Fulfilment: openapi.Fulfilment{
+		Type: db.FulfilmentDineIn, TableNumber: "4",
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, openapi.Fulfilment{Type: db.FulfilmentDineIn, TableNumber: "4"}, res.Body.(openapi.Order).Fulfilment)
+}
+
+func TestPlaceOrderWithDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	zones, err := delivery.New(delivery.Config{
+		Origin: delivery.Point{Lat: -37.8136, Lng: 144.9631},
+		Zones: []delivery.ZoneConfig{
+			{Name: "inner", Postcodes: []string{"3000"}, FreeOver: 20, Fees: []delivery.FeeTier{{Fee: 3}}},
+			{Name: "outer", Radius: 10, MinOrder: 10, Fees: []delivery.FeeTier{{Fee: 7.5}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, nil).AnyTimes()
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl), WithDeliveryZones(zones))
+	place := func(quantity int32, address openapi.DeliveryAddress) openapi.ImplResponse {
+		address.Line1, address.City = "1 Main St", "Melbourne"
+		res, err := svc.PlaceOrder(context.Background(), "", openapi.OrderReq{
+			CouponCode: "HAPPYHOURS",
+			Items:      []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: quantity}},
+			Fulfilment: openapi.Fulfilment{Type: db.FulfilmentDelivery, Address: address},
+		})
+		assert.NoError(t, err)
+		return res
+	}
+
+	// 6.50 plus the inner zone's fee.
+	res := place(1, openapi.DeliveryAddress{Postcode: "3000"})
+	assert.Equal(t, http.StatusOK, res.Code)
+	order := res.Body.(openapi.Order)
+	assert.Equal(t, float32(3), order.DeliveryFee)
+	assert.Equal(t, float32(9.5), order.Total)
+	assert.Equal(t, "inner", order.Fulfilment.Zone)
+
+	// Over the inner zone's free delivery threshold.
+	res = place(4, openapi.DeliveryAddress{Postcode: "3000"})
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, float32(0), res.Body.(openapi.Order).DeliveryFee)
+
+	// Inside the outer zone's radius but under its minimum.
+	res = place(1, openapi.DeliveryAddress{Postcode: "3121", Latitude: -37.82, Longitude: 145.0})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	res = place(1, openapi.DeliveryAddress{Postcode: "3199"})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Contains(t, res.Body, "3199")
+}
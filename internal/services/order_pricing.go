@@ -0,0 +1,327 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// orderRejection is returned by the pricing pipeline when a request can't be
+// fulfilled. It carries the status and message reported to the client.
+type orderRejection struct {
+	code    int
+	message string
+}
+
+func (e *orderRejection) Error() string {
+	return e.message
+}
+
+func reject(code int, message string) error {
+	return &orderRejection{code: code, message: message}
+}
+
+// pricingFailure converts an error from the pricing pipeline into the service
+// result: rejections are reported to the client, anything else is a server error.
+func pricingFailure(err error) (openapi.ImplResponse, error) {
+	var rejection *orderRejection
+	if errors.As(err, &rejection) {
+		return openapi.Response(rejection.code, rejection.message), nil
+	}
+	return openapi.Response(http.StatusInternalServerError, nil), err
+}
+
+// pricedOrder is an order request that has been through product resolution,
+// coupon validation and discount computation, but has not been stored.
+type pricedOrder struct {
+	items       []db.Item
+	couponCode  string
+	discounts   float32
+	fulfilment  *db.Fulfilment
+	deliveryFee float32
+	// scheduledFor and slot are set for pre-orders.
+	scheduledFor time.Time
+	slot         *db.Slot
+	// quoteID is the quote the order is priced from, if any.
+	quoteID db.ID
+}
+
+func (p pricedOrder) order(id db.ID) db.Order {
+	return db.Order{ID: id, Items: p.items, CouponCode: p.couponCode, Discounts: p.discounts, Fulfilment: p.fulfilment, DeliveryFee: p.deliveryFee,
+		ScheduledFor: p.scheduledFor, Slot: p.slot, QuoteID: p.quoteID}
+}
+
+func (p pricedOrder) subtotal() float32 {
+	var subtotal float32
+	for _, item := range p.items {
+		subtotal += item.UnitTotal() * float32(item.Quantity)
+	}
+	return subtotal
+}
+
+// validCouponFormat applies the cheap checks a promo code must pass before the
+// coupon files are searched for it.
+func validCouponFormat(code string) bool {
+	return len(code) >= 8 && len(code) <= 10
+}
+
+// discountFor returns the discount a valid coupon gives on subtotal.
+func (s *OrderAPIService) discountFor(subtotal float32) float32 {
+	return roundCents(subtotal * s.couponDiscount / 100)
+}
+
+// priceOrder runs the checkout pipeline shared by PlaceOrder, QuoteOrder and
+// cart checkout. The coupon scan runs in the background while products are
+// resolved; callers that already started one pass it as searchResult,
+// otherwise pass nil.
+func (s *OrderAPIService) priceOrder(ctx context.Context, orderReq openapi.OrderReq, searchResult db.SearchResult) (pricedOrder, error) {
+	if searchResult == nil {
+		if !validCouponFormat(orderReq.CouponCode) {
+			return pricedOrder{}, reject(http.StatusUnprocessableEntity, "invalid coupon code")
+		}
+		var err error
+		searchResult, err = s.couponDao.SearchForCouponInGivenFiles(ctx, orderReq)
+		if err != nil {
+			return pricedOrder{}, err
+		}
+	}
+
+	items, err := s.resolveItems(ctx, orderReq.Items)
+	if err != nil {
+		return pricedOrder{}, err
+	}
+	priced := pricedOrder{items: items, couponCode: orderReq.CouponCode}
+
+	if result, err := searchResult.Validate(); err != nil {
+		return pricedOrder{}, err
+	} else if !result {
+		return pricedOrder{}, reject(http.StatusUnprocessableEntity, "invalid coupon code")
+	}
+	priced.discounts = s.discountFor(priced.subtotal())
+	return priced, nil
+}
+
+// resolveItems merges the requested lines and snapshots the product and
+// modifiers behind each one, rejecting the request if any product is unknown
+// or the modifiers chosen for it don't fit its modifier groups.
+func (s *OrderAPIService) resolveItems(ctx context.Context, reqItems []openapi.OrderReqItemsInner) ([]db.Item, error) {
+	lines, err := mergeLines(reqItems)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.productsOnSale(ctx, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]db.Item, 0, len(lines))
+	for _, line := range lines {
+		product := products[line.ProductID]
+		modifiers, err := chooseModifiers(product, line.Modifiers)
+		if err != nil {
+			return nil, err
+		}
+		// Snapshot the product so the stored order keeps the price charged today.
+		item := db.Item{
+			ProductID: product.Id,
+			Quantity:  line.Quantity,
+			Name:      product.Name,
+			Category:  product.Category,
+			UnitPrice: product.Price,
+			Modifiers: modifiers,
+			Note:      line.Note,
+			Images:    product.Images,
+		}
+		if item.UnitTotal() < 0 {
+			return nil, reject(http.StatusUnprocessableEntity, fmt.Sprintf("modifiers chosen for product %s make its price negative", product.Id))
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// chooseModifiers resolves the modifiers selected for a product, which mergeLines
+// left holding only their IDs, and checks every modifier group's selection
+// limits. The result is in menu order.
+func chooseModifiers(product db.Product, selected []db.Modifier) ([]db.Modifier, error) {
+	chosen := make(map[db.ID]bool, len(selected))
+	for _, modifier := range selected {
+		chosen[modifier.ID] = true
+	}
+	var modifiers []db.Modifier
+	for _, group := range product.ModifierGroups {
+		var count int32
+		for _, option := range group.Options {
+			if chosen[option.ID] {
+				modifiers = append(modifiers, option)
+				delete(chosen, option.ID)
+				count++
+			}
+		}
+		least := group.MinSelect
+		if group.Required && least < 1 {
+			least = 1
+		}
+		if (group.Required || count > 0) && count < least {
+			return nil, reject(http.StatusUnprocessableEntity, fmt.Sprintf("product %s: choose at least %d from %s", product.Id, least, group.Name))
+		}
+		if group.MaxSelect > 0 && count > group.MaxSelect {
+			return nil, reject(http.StatusUnprocessableEntity, fmt.Sprintf("product %s: choose at most %d from %s", product.Id, group.MaxSelect, group.Name))
+		}
+	}
+	if len(chosen) > 0 {
+		var unknown []string
+		for _, modifier := range selected {
+			if chosen[modifier.ID] {
+				unknown = append(unknown, modifier.ID)
+			}
+		}
+		return nil, reject(http.StatusBadRequest, fmt.Sprintf("invalid modifiers for product %s: %s", product.Id, strings.Join(unknown, ", ")))
+	}
+	return modifiers, nil
+}
+
+// maxNoteLength is the longest free-text note accepted on an order line.
+const maxNoteLength = 200
+
+// mergeLines checks quantities, modifiers and notes and collapses repeated
+// lines into one, keeping the order in which they first appear. Lines are
+// only repeats if they ask for the same product with the same modifiers and
+// note.
+func mergeLines(reqItems []openapi.OrderReqItemsInner) ([]db.Item, error) {
+	lines := make([]db.Item, 0, len(reqItems))
+	index := make(map[string]int, len(reqItems))
+	for _, item := range reqItems {
+		if item.Quantity <= 0 {
+			return nil, reject(http.StatusBadRequest, "quantity must be greater than zero")
+		}
+		note := strings.TrimSpace(item.Note)
+		if utf8.RuneCountInString(note) > maxNoteLength {
+			return nil, reject(http.StatusBadRequest, fmt.Sprintf("note must be at most %d characters", maxNoteLength))
+		}
+		var modifiers []db.Modifier
+		seen := make(map[string]bool, len(item.Modifiers))
+		for _, id := range item.Modifiers {
+			if seen[id] {
+				return nil, reject(http.StatusBadRequest, fmt.Sprintf("modifier %s chosen twice for product %s", id, item.ProductId))
+			}
+			seen[id] = true
+			modifiers = append(modifiers, db.Modifier{ID: id})
+		}
+		key := lineKey(item.ProductId, item.Modifiers, note)
+		if i, ok := index[key]; ok {
+			lines[i].Quantity += item.Quantity
+			continue
+		}
+		index[key] = len(lines)
+		lines = append(lines, db.Item{ProductID: db.ID(item.ProductId), Quantity: item.Quantity, Modifiers: modifiers, Note: note})
+	}
+	return lines, nil
+}
+
+// productsOnSale resolves the products of the lines of an order, rejecting
+// the request if any is unknown or has been archived.
+func (s *OrderAPIService) productsOnSale(ctx context.Context, lines []db.Item) (map[db.ID]db.Product, error) {
+	ids := make([]db.ID, 0, len(lines))
+	for _, line := range lines {
+		if !slices.Contains(ids, line.ProductID) {
+			ids = append(ids, line.ProductID)
+		}
+	}
+	products, err := s.productDao.GetProductsByIDs(ctx, ids)
+	var unknown *db.UnknownProductsError
+	if errors.As(err, &unknown) {
+		return nil, reject(http.StatusBadRequest, "invalid products specified: "+strings.Join(unknown.IDs, ", "))
+	} else if err != nil {
+		return nil, err
+	}
+	var archived []db.ID
+	for _, id := range ids {
+		if !products[id].ArchivedAt.IsZero() {
+			archived = append(archived, id)
+		}
+	}
+	if len(archived) > 0 {
+		return nil, reject(http.StatusBadRequest, "invalid products specified: "+strings.Join(archived, ", "))
+	}
+	return products, nil
+}
+
+// lineKey identifies the lines that mergeLines collapses into one.
+func lineKey(productID db.ID, modifierIDs []db.ID, note string) string {
+	ids := slices.Clone(modifierIDs)
+	slices.Sort(ids)
+	return strings.Join([]string{productID, strings.Join(ids, ","), note}, "\x00")
+}
+
+func itemKey(item db.Item) string {
+	ids := make([]db.ID, 0, len(item.Modifiers))
+	for _, modifier := range item.Modifiers {
+		ids = append(ids, modifier.ID)
+	}
+	return lineKey(item.ProductID, ids, item.Note)
+}
+
+// quotedOrder prices an order from a stored quote instead of the live catalog.
+// The request must carry the same items and coupon the quote was issued for,
+// and the quote must not have been used by another order. Products archived
+// since the quote can no longer be ordered.
+func (s *OrderAPIService) quotedOrder(ctx context.Context, orderReq openapi.OrderReq) (pricedOrder, error) {
+	quote, err := s.quoteDao.GetQuote(ctx, db.ID(orderReq.QuoteId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pricedOrder{}, reject(http.StatusUnprocessableEntity, "unknown quote")
+		}
+		return pricedOrder{}, err
+	}
+	if time.Now().After(quote.ExpiresAt) {
+		return pricedOrder{}, reject(http.StatusUnprocessableEntity, "quote has expired")
+	}
+	if quote.UsedBy != "" {
+		return pricedOrder{}, reject(http.StatusUnprocessableEntity, "quote has already been used")
+	}
+	// A line taking some away could make up for another asking for more.
+	for _, item := range orderReq.Items {
+		if item.Quantity <= 0 {
+			return pricedOrder{}, reject(http.StatusBadRequest, "quantity must be greater than zero")
+		}
+	}
+	if quote.CouponCode != orderReq.CouponCode || !sameQuantities(quote.Items, orderReq.Items) {
+		return pricedOrder{}, reject(http.StatusUnprocessableEntity, "order does not match quote")
+	}
+	if _, err := s.productsOnSale(ctx, quote.Items); err != nil {
+		return pricedOrder{}, err
+	}
+	return pricedOrder{items: quote.Items, couponCode: quote.CouponCode, discounts: quote.Discounts, quoteID: quote.ID}, nil
+}
+
+// sameQuantities reports whether the stored lines and the request ask for the
+// same quantity of every product, modifier selection and note.
+func sameQuantities(items []db.Item, reqItems []openapi.OrderReqItemsInner) bool {
+	quantities := make(map[string]int32)
+	for _, item := range items {
+		quantities[itemKey(item)] += item.Quantity
+	}
+	for _, item := range reqItems {
+		quantities[lineKey(item.ProductId, item.Modifiers, strings.TrimSpace(item.Note))] -= item.Quantity
+	}
+	for _, quantity := range quantities {
+		if quantity != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func roundCents(amount float32) float32 {
+	return float32(math.Round(float64(amount)*100) / 100)
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Coupon release policies decide when refunding an order gives its coupon
+// redemption back so the code can be used again.
+const (
+	// CouponReleaseNever keeps every redemption.
+	CouponReleaseNever = "never"
+	// CouponReleaseCancel releases the coupon of cancelled orders.
+	CouponReleaseCancel = "cancel"
+	// CouponReleaseRefund also releases it once an order is refunded in full.
+	CouponReleaseRefund = "refund"
+)
+
+// cancellableStatuses are the states an order can still be cancelled from, as
+// long as the kitchen hasn't bumped any of its lines.
+var cancellableStatuses = []string{db.OrderPending, db.OrderAccepted}
+
+// refundReportWindow is the range ListRefunds covers when no start is given.
+const refundReportWindow = 24 * time.Hour
+
+// WithCouponRelease sets the coupon release policy, one of the CouponRelease*
+// constants.
+func WithCouponRelease(policy string) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		switch policy {
+		case CouponReleaseNever, CouponReleaseCancel, CouponReleaseRefund:
+			s.couponRelease = policy
+		}
+	}
+}
+
+// CancelOrder - Cancel an order
+func (s *OrderAPIService) CancelOrder(ctx context.Context, orderId string, cancelOrderReq openapi.CancelOrderReq) (openapi.ImplResponse, error) {
+	order, err := s.orderDao.GetOrder(ctx, db.ID(orderId))
+	if err != nil {
+		return refundFailure(err)
+	}
+	if !slices.Contains(cancellableStatuses, order.Status) {
+		return refundFailure(&db.OrderStatusError{ID: order.ID, Status: order.Status})
+	}
+	if slices.ContainsFunc(order.Items, func(item db.Item) bool { return !item.BumpedAt.IsZero() }) {
+		return refundFailure(&db.OrderPreparingError{ID: order.ID})
+	}
+
+	reason := cancelOrderReq.Reason
+	if reason == "" {
+		reason = "cancelled"
+	}
+	// The refund is made by whoever holds the key, whatever the body says.
+	operator := holderOf(ctx)
+	if operator == "" && order.Status == db.OrderAccepted {
+		return openapi.Response(http.StatusUnauthorized, "missing or unknown API key"), nil
+	}
+	// Pending orders haven't been charged yet; accepted ones get back whatever
+	// earlier refunds left.
+	var refund *db.Refund
+	if order.Status == db.OrderAccepted {
+		refunds, err := s.orderDao.GetRefunds(ctx, order.ID)
+		if err != nil {
+			return openapi.Response(http.StatusInternalServerError, nil), err
+		}
+		if remaining := roundCents(order.Total() - refundedAmount(refunds)); remaining > 0 {
+			refund = &db.Refund{
+				ID:             uuid.New().String(),
+				OrderID:        order.ID,
+				Amount:         remaining,
+				Reason:         reason,
+				Operator:       operator,
+				CouponReleased: s.couponRelease != CouponReleaseNever,
+				CreatedAt:      time.Now().UTC(),
+			}
+		}
+	}
+	if err := s.orderDao.CancelOrder(ctx, order.ID, reason, cancellableStatuses, refund); err != nil {
+		return refundFailure(err)
+	}
+	s.publishStatus(ctx, order.ID)
+	return s.GetOrder(ctx, orderId)
+}
+
+// RefundOrder - Refund an order
+func (s *OrderAPIService) RefundOrder(ctx context.Context, orderId string, refundReq openapi.RefundReq) (openapi.ImplResponse, error) {
+	order, err := s.orderDao.GetOrder(ctx, db.ID(orderId))
+	if err != nil {
+		return refundFailure(err)
+	}
+	if order.Status == db.OrderPending || order.Status == db.OrderRejected || order.Status == db.OrderCancelled {
+		return openapi.Response(http.StatusConflict, fmt.Sprintf("order is %s and has nothing to refund", order.Status)), nil
+	}
+	refunds, err := s.orderDao.GetRefunds(ctx, order.ID)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	remaining := roundCents(order.Total() - refundedAmount(refunds))
+
+	refund := db.Refund{
+		ID:        uuid.New().String(),
+		OrderID:   order.ID,
+		Reason:    refundReq.Reason,
+		Operator:  refundReq.Operator,
+		CreatedAt: time.Now().UTC(),
+	}
+	switch {
+	case refundReq.Amount < 0:
+		return openapi.Response(http.StatusBadRequest, "amount must not be negative"), nil
+	case refundReq.Amount > 0 && len(refundReq.Lines) > 0:
+		return openapi.Response(http.StatusBadRequest, "refund either an amount or lines, not both"), nil
+	case len(refundReq.Lines) > 0:
+		refund.Lines, refund.Amount, err = refundLines(order, refundReq.Lines)
+		if err != nil {
+			return refundFailure(err)
+		}
+	case refundReq.Amount > 0:
+		refund.Amount = roundCents(refundReq.Amount)
+	default:
+		refund.Amount = remaining
+	}
+	if refund.Amount <= 0 {
+		return openapi.Response(http.StatusUnprocessableEntity, "nothing left to refund"), nil
+	}
+	if refund.Amount > remaining {
+		return refundFailure(&db.RefundLimitError{OrderID: order.ID, Remaining: remaining})
+	}
+	refund.CouponReleased = s.couponRelease == CouponReleaseRefund && refund.Amount == remaining
+
+	stored, err := s.orderDao.CreateRefund(ctx, refund)
+	if err != nil {
+		return refundFailure(err)
+	}
+	return openapi.Response(http.StatusOK, refundResponse(stored)), nil
+}
+
+// ListRefunds - Report refunds
+func (s *OrderAPIService) ListRefunds(ctx context.Context, from time.Time, to time.Time) (openapi.ImplResponse, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-refundReportWindow)
+	}
+	if !from.Before(to) {
+		return openapi.Response(http.StatusBadRequest, "from must be before to"), nil
+	}
+	refunds, err := s.orderDao.GetRefundsBetween(ctx, from, to)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	report := openapi.RefundReport{
+		From:    from.UTC(),
+		To:      to.UTC(),
+		Count:   int32(len(refunds)),
+		Total:   refundedAmount(refunds),
+		Refunds: make([]openapi.Refund, 0, len(refunds)),
+	}
+	for _, refund := range refunds {
+		report.Refunds = append(report.Refunds, refundResponse(refund))
+	}
+	return openapi.Response(http.StatusOK, report), nil
+}
+
+// refundLines prices the requested lines at what was paid for them, the line
+// snapshot less its share of the order discount. Refund lines name a product,
+// so when it was ordered on several lines with different modifiers each unit
+// is refunded at the product's average price. The DAO checks that no more of
+// each product is refunded than was ordered, counting earlier line refunds, as
+// it stores the refund.
+func refundLines(order db.Order, reqLines []openapi.RefundLine) ([]db.RefundLine, float32, error) {
+	ordered := make(map[db.ID]int32, len(order.Items))
+	paid := make(map[db.ID]float32, len(order.Items))
+	var subtotal float32
+	for _, item := range order.Items {
+		ordered[item.ProductID] += item.Quantity
+		paid[item.ProductID] += item.UnitTotal() * float32(item.Quantity)
+		subtotal += item.UnitTotal() * float32(item.Quantity)
+	}
+	prices := make(map[db.ID]float32, len(paid))
+	for id, total := range paid {
+		prices[id] = total / float32(ordered[id])
+	}
+
+	var lines []db.RefundLine
+	var amount float32
+	for _, reqLine := range reqLines {
+		id := db.ID(reqLine.ProductId)
+		if _, ok := ordered[id]; !ok {
+			return nil, 0, reject(http.StatusBadRequest, "product not in order: "+id)
+		}
+		if reqLine.Quantity <= 0 {
+			return nil, 0, reject(http.StatusBadRequest, "quantity must be greater than zero")
+		}
+		lines = append(lines, db.RefundLine{ProductID: id, Quantity: reqLine.Quantity})
+		amount += prices[id] * float32(reqLine.Quantity)
+	}
+	if subtotal > 0 {
+		amount -= amount * order.Discounts / subtotal
+	}
+	return lines, roundCents(amount), nil
+}
+
+func refundedAmount(refunds []db.Refund) float32 {
+	var total float32
+	for _, refund := range refunds {
+		total += refund.Amount
+	}
+	return roundCents(total)
+}
+
+// refundFailure reports order state and refund limit errors to the client,
+// alongside the rejections and missing orders every order endpoint handles.
+func refundFailure(err error) (openapi.ImplResponse, error) {
+	var status *db.OrderStatusError
+	var preparing *db.OrderPreparingError
+	var limit *db.RefundLimitError
+	var quantity *db.RefundQuantityError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return openapi.Response(http.StatusNotFound, nil), nil
+	case errors.As(err, &status):
+		return openapi.Response(http.StatusConflict, fmt.Sprintf("order is %s", status.Status)), nil
+	case errors.As(err, &preparing):
+		return openapi.Response(http.StatusConflict, "order is already being prepared"), nil
+	case errors.As(err, &limit):
+		return openapi.Response(http.StatusUnprocessableEntity, fmt.Sprintf("refund exceeds amount paid; %.2f left to refund", limit.Remaining)), nil
+	case errors.As(err, &quantity):
+		return openapi.Response(http.StatusUnprocessableEntity, fmt.Sprintf("only %d of product %s left to refund", quantity.Remaining, quantity.ProductID)), nil
+	}
+	return pricingFailure(err)
+}
+
+func refundResponse(refund db.Refund) openapi.Refund {
+	res := openapi.Refund{
+		Id:             refund.ID,
+		OrderId:        refund.OrderID,
+		Amount:         refund.Amount,
+		Reason:         refund.Reason,
+		Operator:       refund.Operator,
+		CouponReleased: refund.CouponReleased,
+		CreatedAt:      refund.CreatedAt.UTC(),
+	}
+	for _, line := range refund.Lines {
+		res.Lines = append(res.Lines, openapi.RefundLine{ProductId: line.ProductID, Quantity: line.Quantity})
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	"backend-challenge/internal/slots"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// WithSlots lets orders be scheduled ahead into the pickup slots of
+// schedule. Without it, scheduled orders are refused.
+func WithSlots(schedule *slots.Schedule) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		s.slots = schedule
+	}
+}
+
+// schedule books a pre-order into the slot its time falls in. The slot's
+// capacity is checked and taken when the order is stored. Orders without a
+// scheduled time are left alone.
+func (s *OrderAPIService) schedule(priced *pricedOrder, scheduledFor, now time.Time) error {
+	if scheduledFor.IsZero() {
+		return nil
+	}
+	if s.slots == nil {
+		return reject(http.StatusUnprocessableEntity, "orders can't be scheduled ahead")
+	}
+	start, err := s.slots.Check(scheduledFor.In(now.Location()), now)
+	var unavailable *slots.UnavailableError
+	if errors.As(err, &unavailable) {
+		return reject(http.StatusUnprocessableEntity, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	priced.scheduledFor = scheduledFor.UTC()
+	priced.slot = &db.Slot{Start: start.UTC(), MaxOrders: s.slots.MaxOrders, MaxItems: s.slots.MaxItems}
+	return nil
+}
+
+// ReleaseScheduled releases the scheduled orders due at the kitchen, those
+// wanted within the schedule's release offset, and announces each one. It
+// returns how many were released.
+func (s *OrderAPIService) ReleaseScheduled(ctx context.Context) (int, error) {
+	if s.slots == nil {
+		return 0, nil
+	}
+	ids, err := s.orderDao.ReleaseScheduledOrders(ctx, time.Now().Add(s.slots.Release))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.publishStored(ctx, StreamOrderReleased, id)
+	}
+	return len(ids), nil
+}
+
+// ReleaseScheduledEvery runs ReleaseScheduled on every tick of interval until ctx is done.
+func (s *OrderAPIService) ReleaseScheduledEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := s.ReleaseScheduled(ctx); err != nil {
+				log.Printf("releasing scheduled orders: %v", err)
+			} else if n > 0 {
+				log.Printf("released %d scheduled orders", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/slots"
+)
+
+func TestPlaceScheduledOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}}, nil).AnyTimes()
+	// Slots all day, so the test doesn't depend on when it runs.
+	schedule, err := slots.New(slots.Config{Length: 15 * time.Minute, MaxOrders: 2, MinLead: 30 * time.Minute})
+	if !assert.NoError(t, err) {
+		return
+	}
+	at := time.Now().Add(2 * time.Hour).Truncate(time.Second).UTC()
+	start, _ := schedule.Slot(at.Local())
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		assert.Equal(t, at, order.ScheduledFor)
+		assert.Equal(t, &db.Slot{Start: start.UTC(), MaxOrders: 2}, order.Slot)
+		return nil
+	})
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(&db.SlotFullError{Start: start.UTC()})
+	items := []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}}
+	ctx := context.Background()
+
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl))
+	res, err := svc.PlaceOrder(ctx, "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: items, ScheduledFor: at})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Equal(t, "orders can't be scheduled ahead", res.Body)
+
+	svc = NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl), WithSlots(schedule))
+	res, err = svc.PlaceOrder(ctx, "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: items, ScheduledFor: time.Now().Add(10 * time.Minute)})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Equal(t, "orders must be scheduled at least 30 minutes ahead", res.Body)
+
+	res, err = svc.PlaceOrder(ctx, "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: items, ScheduledFor: at})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, at, res.Body.(openapi.Order).ScheduledFor)
+
+	res, err = svc.PlaceOrder(ctx, "", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: items, ScheduledFor: at})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Equal(t, "the "+start.Format("15:04")+" pickup slot is full", res.Body)
+}
+
+func TestReleaseScheduled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	schedule, err := slots.New(slots.Config{Length: 15 * time.Minute, Release: 20 * time.Minute})
+	if !assert.NoError(t, err) {
+		return
+	}
+	scheduledFor := time.Now().Add(15 * time.Minute).UTC()
+	oc.EXPECT().ReleaseScheduledOrders(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, by time.Time) ([]db.ID, error) {
+		assert.WithinDuration(t, time.Now().Add(20*time.Minute), by, time.Minute)
+		return []db.ID{"o1"}, nil
+	})
+	oc.EXPECT().GetOrder(gomock.Any(), db.ID("o1")).Return(db.Order{
+		ID: "o1", Status: db.OrderAccepted, ScheduledFor: scheduledFor, ReleasedAt: time.Now().UTC(),
+		Items: []db.Item{{ProductID: "1", Quantity: 1, Name: "Waffle", Category: "Waffle", UnitPrice: 6.5}},
+	}, nil)
+	bus := NewOrderEventBus(0, nil)
+	_, events, cancel := bus.Subscribe(OrderEventFilter{Stations: []string{"waffle"}}, 0)
+	defer cancel()
+
+	// Before its release, the kitchen doesn't see a scheduled order.
+	bus.Publish(StreamOrderCreated, openapi.Order{Id: "o1", Status: db.OrderAccepted, ScheduledFor: scheduledFor,
+		Products: []openapi.Product{{Id: "1", Name: "Waffle", Category: "Waffle"}}})
+	svc := NewOrderAPIServiceWithCouponDao(oc, dbmocks.NewMockProductDao(ctrl), &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl),
+		WithSlots(schedule), WithOrderEvents(bus))
+	n, err := svc.ReleaseScheduled(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	select {
+	case event := <-events:
+		assert.Equal(t, StreamOrderReleased, event.Type)
+		assert.Equal(t, "o1", event.Order.Id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order.released event")
+	}
+}
+
+func TestListSlots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sd := dbmocks.NewMockSlotDao(ctrl)
+	schedule, err := slots.New(slots.Config{Length: time.Hour, From: "09:00", Until: "12:00", MaxOrders: 2, MaxItems: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	day := time.Now().AddDate(0, 0, 1)
+	at := func(hour int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, time.Local)
+	}
+	sd.EXPECT().GetSlotUsage(gomock.Any(), at(9), at(12)).Return([]db.SlotUsage{
+		{Start: at(9).UTC(), Orders: 2, Items: 3},
+		{Start: at(10).UTC(), Orders: 1, Items: 8},
+	}, nil)
+	svc := NewSlotAPIService(schedule, sd)
+
+	res, err := svc.ListSlots(context.Background(), day.Format(time.DateOnly))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, []openapi.PickupSlot{
+		{Start: at(9), End: at(10), RemainingItems: 7},
+		{Start: at(10), End: at(11), Available: true, RemainingOrders: 1, RemainingItems: 2},
+		{Start: at(11), End: at(12), Available: true, RemainingOrders: 2, RemainingItems: 10},
+	}, res.Body)
+
+	res, err = svc.ListSlots(context.Background(), "tomorrow")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	db "backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/kitchen"
+
+	"github.com/gorilla/mux"
+)
+
+// Order stream event types, sent as the SSE event name.
+const (
+	StreamOrderCreated       = "order.created"
+	StreamOrderStatusChanged = "order.status_changed"
+	StreamOrderReleased      = "order.released"
+)
+
+const (
+	defaultStreamReplay    = 256
+	defaultStreamHeartbeat = 15 * time.Second
+	// streamSubscriberBuffer is how many events a subscriber may fall behind
+	// before it is dropped; the client then resumes from the replay buffer.
+	streamSubscriberBuffer = 64
+)
+
+// OrderEvent is an order change published on an OrderEventBus.
+type OrderEvent struct {
+	// ID increases by one for every event published on the bus.
+	ID    uint64
+	Type  string
+	Order openapi.Order
+}
+
+// OrderEventFilter selects the events a subscriber receives. Empty fields match
+// everything. Filtering by station is what kitchens do, so scheduled orders
+// only match it once they have been released.
+type OrderEventFilter struct {
+	OrderID  string
+	Statuses []string
+	Stations []string
+}
+
+func (f OrderEventFilter) matches(event OrderEvent, stations *kitchen.Stations) bool {
+	if f.OrderID != "" && event.Order.Id != f.OrderID {
+		return false
+	}
+	if len(f.Statuses) > 0 && !containsFold(f.Statuses, event.Order.Status) {
+		return false
+	}
+	if len(f.Stations) > 0 {
+		if !event.Order.ScheduledFor.IsZero() && event.Order.ReleasedAt.IsZero() {
+			return false
+		}
+		for _, station := range orderStations(event.Order, stations) {
+			if containsFold(f.Stations, station) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// orderStations lists the kitchen stations an order needs.
+func orderStations(order openapi.Order, stations *kitchen.Stations) []string {
+	needed := make([]string, 0, len(order.Products))
+	for _, product := range order.Products {
+		needed = append(needed, stations.Station(product.Id, product.Category))
+	}
+	return needed
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderEventBus fans order events out to in-process subscribers, such as the
+// SSE streams. It keeps the most recent events so that reconnecting clients
+// can catch up with what they missed. Publishing on a nil bus does nothing.
+type OrderEventBus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	replay      []OrderEvent
+	replaySize  int
+	subscribers map[*orderSubscriber]struct{}
+	// stations decides which stations an order's lines go to, for
+	// subscribers filtering by station.
+	stations *kitchen.Stations
+}
+
+type orderSubscriber struct {
+	filter OrderEventFilter
+	events chan OrderEvent
+}
+
+// NewOrderEventBus creates a bus that keeps the last replaySize events and
+// routes order lines to stations. With nil stations, each product category
+// is prepared at its own station.
+func NewOrderEventBus(replaySize int, stations *kitchen.Stations) *OrderEventBus {
+	if replaySize <= 0 {
+		replaySize = defaultStreamReplay
+	}
+	return &OrderEventBus{
+		replaySize:  replaySize,
+		subscribers: map[*orderSubscriber]struct{}{},
+		stations:    stations,
+	}
+}
+
+// Publish numbers an event and sends it to every matching subscriber.
+// Subscribers that are too far behind are dropped rather than blocking the
+// publisher.
+func (b *OrderEventBus) Publish(eventType string, order openapi.Order) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := OrderEvent{ID: b.lastID, Type: eventType, Order: order}
+	if len(b.replay) == b.replaySize {
+		b.replay = append(b.replay[:0], b.replay[1:]...)
+	}
+	b.replay = append(b.replay, event)
+
+	for sub := range b.subscribers {
+		if !sub.filter.matches(event, b.stations) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe returns the buffered events after lastEventID that match filter,
+// followed by a channel of new ones. A lastEventID of 0 replays nothing; one
+// the bus does not know, for example from before a restart, replays the whole
+// buffer. The channel is closed when the subscriber is dropped or cancel is
+// called.
+func (b *OrderEventBus) Subscribe(filter OrderEventFilter, lastEventID uint64) (missed []OrderEvent, events <-chan OrderEvent, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastEventID > 0 {
+		if lastEventID > b.lastID {
+			lastEventID = 0
+		}
+		for _, event := range b.replay {
+			if event.ID > lastEventID && filter.matches(event, b.stations) {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	sub := &orderSubscriber{filter: filter, events: make(chan OrderEvent, streamSubscriberBuffer)}
+	b.subscribers[sub] = struct{}{}
+	return missed, sub.events, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// WithOrderEvents publishes created orders and status changes on bus.
+func WithOrderEvents(bus *OrderEventBus) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		s.events = bus
+	}
+}
+
+// OrderStreamController serves order events as Server-Sent Events, the
+// streamOrders and streamOrder operations of the API spec. Streams do not fit
+// the generated request/response controllers, so it implements
+// openapi.Router itself and must be passed to openapi.NewRouter before the
+// OrderAPIController, whose /api/order/{orderId} would otherwise match
+// /api/order/stream.
+type OrderStreamController struct {
+	bus       *OrderEventBus
+	heartbeat time.Duration
+}
+
+// NewOrderStreamController creates a controller streaming the events of bus,
+// with a comment sent every heartbeat to keep idle connections open.
+func NewOrderStreamController(bus *OrderEventBus, heartbeat time.Duration) *OrderStreamController {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+	return &OrderStreamController{bus: bus, heartbeat: heartbeat}
+}
+
+// Routes returns all the api routes for the OrderStreamController
+func (c *OrderStreamController) Routes() openapi.Routes {
+	routes := openapi.Routes{}
+	for _, route := range c.OrderedRoutes() {
+		routes[route.Name] = route
+	}
+	return routes
+}
+
+// OrderedRoutes returns all the api routes in a deterministic order for the OrderStreamController
+func (c *OrderStreamController) OrderedRoutes() []openapi.Route {
+	return []openapi.Route{
+		{Name: "StreamOrders", Method: http.MethodGet, Pattern: "/api/order/stream", HandlerFunc: c.StreamOrders},
+		{Name: "StreamOrder", Method: http.MethodGet, Pattern: "/api/order/{orderId}/stream", HandlerFunc: c.StreamOrder},
+	}
+}
+
+// StreamOrders - Stream events for all orders, optionally filtered by status and station
+func (c *OrderStreamController) StreamOrders(w http.ResponseWriter, r *http.Request) {
+	c.stream(w, r, streamFilter(r, ""))
+}
+
+// StreamOrder - Stream events for one order
+func (c *OrderStreamController) StreamOrder(w http.ResponseWriter, r *http.Request) {
+	c.stream(w, r, streamFilter(r, mux.Vars(r)["orderId"]))
+}
+
+// streamFilter reads the status and station query parameters, each of which
+// may be repeated or comma separated.
+func streamFilter(r *http.Request, orderID string) OrderEventFilter {
+	query := r.URL.Query()
+	return OrderEventFilter{
+		OrderID:  orderID,
+		Statuses: splitQuery(query["status"]),
+		Stations: splitQuery(query["station"]),
+	}
+}
+
+func splitQuery(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+func (c *OrderStreamController) stream(w http.ResponseWriter, r *http.Request, filter OrderEventFilter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	for _, status := range filter.Statuses {
+		if !containsFold([]string{db.OrderPending, db.OrderAccepted, db.OrderReady, db.OrderRejected, db.OrderCancelled}, status) {
+			http.Error(w, fmt.Sprintf("unknown status %q", status), http.StatusBadRequest)
+			return
+		}
+	}
+	// EventSource sends Last-Event-ID when it reconnects; the query parameter
+	// lets other clients resume too.
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	var lastEventID uint64
+	if lastID != "" {
+		var err error
+		if lastEventID, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	missed, events, cancel := c.bus.Subscribe(filter, lastEventID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stops reverse proxies such as nginx from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range missed {
+		if err := writeStreamEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(c.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects with
+				// Last-Event-ID and catches up from the replay buffer.
+				return
+			}
+			if err := writeStreamEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event OrderEvent) error {
+	data, err := json.Marshal(event.Order)
+	if err != nil {
+		log.Printf("encoding order event %d: %v", event.ID, err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
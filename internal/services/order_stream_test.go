@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+func eventIDs(events []OrderEvent) []uint64 {
+	ids := []uint64{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestOrderEventBus_Replay(t *testing.T) {
+	bus := NewOrderEventBus(3, nil)
+	for _, status := range []string{db.OrderPending, db.OrderAccepted, db.OrderPending, db.OrderRejected, db.OrderAccepted} {
+		bus.Publish(StreamOrderCreated, openapi.Order{Id: "1", Status: status, Products: []openapi.Product{{Category: "Waffle"}}})
+	}
+	tests := []struct {
+		name   string
+		filter OrderEventFilter
+		lastID uint64
+		want   []uint64
+	}{
+		{name: "new subscriber", lastID: 0, want: []uint64{}},
+		{name: "resume", lastID: 3, want: []uint64{4, 5}},
+		{name: "resume from evicted event", lastID: 1, want: []uint64{3, 4, 5}},
+		{name: "unknown event", lastID: 99, want: []uint64{3, 4, 5}},
+		{name: "status filter", filter: OrderEventFilter{Statuses: []string{"ACCEPTED"}}, lastID: 2, want: []uint64{5}},
+		{name: "station filter", filter: OrderEventFilter{Stations: []string{"grill"}}, lastID: 2, want: []uint64{}},
+		{name: "other order", filter: OrderEventFilter{OrderID: "2"}, lastID: 2, want: []uint64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missed, _, cancel := bus.Subscribe(tt.filter, tt.lastID)
+			defer cancel()
+			assert.Equal(t, tt.want, eventIDs(missed))
+		})
+	}
+}
+
+func TestOrderEventBus_DropsSlowSubscriber(t *testing.T) {
+	bus := NewOrderEventBus(0, nil)
+	_, events, cancel := bus.Subscribe(OrderEventFilter{}, 0)
+	defer cancel()
+	for i := 0; i <= streamSubscriberBuffer; i++ {
+		bus.Publish(StreamOrderCreated, openapi.Order{Id: "1"})
+	}
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, streamSubscriberBuffer, received)
+}
+
+func TestOrderStreamController(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bus := NewOrderEventBus(10, nil)
+	bus.Publish(StreamOrderCreated, openapi.Order{Id: "1", Status: db.OrderPending})
+	bus.Publish(StreamOrderStatusChanged, openapi.Order{Id: "1", Status: db.OrderAccepted})
+	// The order routes are registered too, to check that they don't swallow the stream.
+	orderAPI := openapi.NewOrderAPIController(NewOrderAPIServiceWithCouponDao(dbmocks.NewMockOrderDao(ctrl), dbmocks.NewMockProductDao(ctrl), &testCouponDao{}, dbmocks.NewMockQuoteDao(ctrl)))
+	router := openapi.NewRouter(NewOrderStreamController(bus, 50*time.Millisecond), orderAPI)
+	router.Use(RequireAPIKey(map[string][]string{"kitchen": {"tablet"}}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Every order's events need a key; one order's only need its ID.
+	res, err := http.Get(server.URL + "/api/order/stream")
+	if err != nil {
+		t.Fatalf("stream request: %v", err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/order/stream?status=accepted,rejected", nil)
+	req.Header.Set("api_key", "tablet")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request: %v", err)
+	}
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(res.Body)
+	readEvent := func() string {
+		var event []string
+		for lines.Scan() && lines.Text() != "" {
+			event = append(event, lines.Text())
+		}
+		return strings.Join(event, "\n")
+	}
+	// A new subscriber gets nothing from before it connected.
+	assert.Equal(t, ": heartbeat", readEvent())
+	bus.Publish(StreamOrderStatusChanged, openapi.Order{Id: "2", Status: db.OrderPending})
+	bus.Publish(StreamOrderStatusChanged, openapi.Order{Id: "2", Status: db.OrderRejected, StatusReason: "invalid coupon code"})
+	event := readEvent()
+	for event == ": heartbeat" {
+		event = readEvent()
+	}
+	assert.True(t, strings.HasPrefix(event, "id: 4\nevent: order.status_changed\ndata: {"), event)
+	assert.Contains(t, event, `"statusReason":"invalid coupon code"`)
+
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/order/1/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request: %v", err)
+	}
+	defer res.Body.Close()
+	lines = bufio.NewScanner(res.Body)
+	assert.True(t, strings.HasPrefix(readEvent(), "id: 2\nevent: order.status_changed\n"))
+
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/order/stream?status=eaten", nil)
+	req.Header.Set("api_key", "tablet")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request: %v", err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestPlaceOrderPublishesEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oc := dbmocks.NewMockOrderDao(ctrl)
+	pc := dbmocks.NewMockProductDao(ctrl)
+	pc.EXPECT().GetProductsByIDs(gomock.Any(), []db.ID{"1"}).Return(map[db.ID]db.Product{
+		"1": {Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"},
+	}, nil)
+	var placed db.Order
+	oc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, order db.Order) error {
+		placed = order
+		return nil
+	})
+	oc.EXPECT().UpdateOrderStatus(gomock.Any(), gomock.Any(), db.OrderAccepted, "").Return(nil)
+	oc.EXPECT().GetOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, db.ID) (db.Order, error) {
+		placed.Status = db.OrderAccepted
+		return placed, nil
+	})
+	bus := NewOrderEventBus(0, nil)
+	_, events, cancel := bus.Subscribe(OrderEventFilter{Stations: []string{"waffle"}}, 0)
+	defer cancel()
+	svc := NewOrderAPIServiceWithCouponDao(oc, pc, &testCouponDao{found: true}, dbmocks.NewMockQuoteDao(ctrl), WithOrderEvents(bus))
+
+	_, err := svc.PlaceOrder(context.Background(), "respond-async", openapi.OrderReq{CouponCode: "HAPPYHOURS", Items: []openapi.OrderReqItemsInner{{ProductId: "1", Quantity: 1}}})
+	assert.NoError(t, err)
+	for _, want := range []struct{ eventType, status string }{
+		{StreamOrderCreated, db.OrderPending},
+		{StreamOrderStatusChanged, db.OrderAccepted},
+	} {
+		select {
+		case event := <-events:
+			assert.Equal(t, want.eventType, event.Type)
+			assert.Equal(t, want.status, event.Order.Status)
+			assert.Equal(t, placed.ID, event.Order.Id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", want.eventType)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateProduct - Create a product
+func (s *ProductAPIService) CreateProduct(ctx context.Context, productReq openapi.ProductReq) (openapi.ImplResponse, error) {
+	product, err := s.productDao.CreateProduct(ctx, db.Product{
+		Name:        productReq.Name,
+		Price:       productReq.Price,
+		Category:    productReq.Category,
+		Description: productReq.Description,
+		Tags:        productReq.Tags,
+	})
+	if err != nil {
+		return productWriteFailure(err)
+	}
+	return s.productWritten(ctx, http.StatusCreated, product)
+}
+
+// UpdateProduct - Replace a product
+func (s *ProductAPIService) UpdateProduct(ctx context.Context, productId string, ifMatch string, productReq openapi.ProductReq) (openapi.ImplResponse, error) {
+	if !db.ValidID(productId) {
+		return malformedProductID()
+	}
+	if ifMatch == "" {
+		return ifMatchMissing()
+	}
+	product, err := s.productDao.UpdateProduct(ctx, db.Product{
+		Id:          db.ID(productId),
+		Name:        productReq.Name,
+		Price:       productReq.Price,
+		Category:    productReq.Category,
+		Description: productReq.Description,
+		Tags:        productReq.Tags,
+	}, ifMatchVersion(ifMatch))
+	if err != nil {
+		return productWriteFailure(err)
+	}
+	return s.productWritten(ctx, http.StatusOK, product)
+}
+
+// PatchProduct - Update a product
+func (s *ProductAPIService) PatchProduct(ctx context.Context, productId string, ifMatch string, productPatch openapi.ProductPatch) (openapi.ImplResponse, error) {
+	if !db.ValidID(productId) {
+		return malformedProductID()
+	}
+	if ifMatch == "" {
+		return ifMatchMissing()
+	}
+	// The product is read where it is written, so a patch can't be based on
+	// a cached copy that has fallen behind.
+	product, err := s.productDao.PatchProduct(ctx, db.ID(productId), db.ProductPatch{
+		Name:        productPatch.Name,
+		Price:       productPatch.Price,
+		Category:    productPatch.Category,
+		Description: productPatch.Description,
+		Tags:        productPatch.Tags,
+	}, ifMatchVersion(ifMatch))
+	if err != nil {
+		return productWriteFailure(err)
+	}
+	return s.productWritten(ctx, http.StatusOK, product)
+}
+
+// ArchiveProduct - Archive a product
+func (s *ProductAPIService) ArchiveProduct(ctx context.Context, productId string, ifMatch string) (openapi.ImplResponse, error) {
+	return s.setArchived(ctx, productId, ifMatch, true)
+}
+
+// RestoreProduct - Restore a product
+func (s *ProductAPIService) RestoreProduct(ctx context.Context, productId string, ifMatch string) (openapi.ImplResponse, error) {
+	return s.setArchived(ctx, productId, ifMatch, false)
+}
+
+func (s *ProductAPIService) setArchived(ctx context.Context, productId string, ifMatch string, archived bool) (openapi.ImplResponse, error) {
+	if !db.ValidID(productId) {
+		return malformedProductID()
+	}
+	if ifMatch == "" {
+		return ifMatchMissing()
+	}
+	product, err := s.productDao.SetProductArchived(ctx, db.ID(productId), archived, ifMatchVersion(ifMatch))
+	if err != nil {
+		return productWriteFailure(err)
+	}
+	return s.productWritten(ctx, http.StatusOK, product)
+}
+
+// productWritten is the response to a catalog write: the product as it now
+// is, with the ETag its next write must send in If-Match.
+func (s *ProductAPIService) productWritten(ctx context.Context, code int, product db.Product) (openapi.ImplResponse, error) {
+	res, err := s.withAvailability(ctx, product)
+	if err != nil {
+		return openapi.Response(http.StatusInternalServerError, nil), err
+	}
+	headers := map[string][]string{"ETag": {productETag(product.Version)}}
+	if code == http.StatusCreated {
+		headers["Location"] = []string{"/api/product/" + product.Id}
+	}
+	return openapi.ResponseWithHeaders(code, headers, res), nil
+}
+
+// productWriteFailure converts an error from a catalog write into the service
+// result.
+func productWriteFailure(err error) (openapi.ImplResponse, error) {
+	var invalid validator.ValidationErrors
+	var stale *db.ProductVersionError
+	var category *db.InvalidCategoryError
+	var id *db.ProductIDError
+	switch {
+	case errors.As(err, &invalid), errors.As(err, &category):
+		return openapi.Response(http.StatusBadRequest, err.Error()), nil
+	case errors.Is(err, sql.ErrNoRows):
+		return openapi.Response(http.StatusNotFound, nil), nil
+	case errors.As(err, &id):
+		return openapi.Response(http.StatusConflict, err.Error()), nil
+	case errors.As(err, &stale):
+		headers := map[string][]string{"ETag": {productETag(stale.Version)}}
+		return openapi.ResponseWithHeaders(http.StatusPreconditionFailed, headers, err.Error()), nil
+	}
+	return openapi.Response(http.StatusInternalServerError, nil), err
+}
+
+func malformedProductID() (openapi.ImplResponse, error) {
+	return openapi.Response(http.StatusBadRequest, "productId must be 1 to 64 letters, digits, '-', '.', '_' or '~'"), nil
+}
+
+func ifMatchMissing() (openapi.ImplResponse, error) {
+	return openapi.Response(http.StatusPreconditionRequired, "If-Match must be sent with the product's ETag"), nil
+}
+
+// productETag is the strong ETag of a product version.
+func productETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion reads the product version an If-Match header asks for: 0,
+// which matches any version, for "*", and -1, which matches none, for anything
+// that isn't a product ETag. The ETags of product reads carry a hash after
+// the version, which is left out.
+func ifMatchVersion(ifMatch string) int64 {
+	tag := strings.TrimSpace(ifMatch)
+	if tag == "*" {
+		return 0
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return -1
+	}
+	tag, _, _ = strings.Cut(tag[1:len(tag)-1], "-")
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return -1
+	}
+	return version
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+func adminTestService(ctrl *gomock.Controller) (*ProductAPIService, *dbmocks.MockProductDao) {
+	pd := dbmocks.NewMockProductDao(ctrl)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), gomock.Any()).Return(map[db.ID]db.Stock{}, nil).AnyTimes()
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil).AnyTimes()
+	return NewProductAPIService(pd, sd, id, "", nil), pd
+}
+
+func TestCreateProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, pd := adminTestService(ctrl)
+	pd.EXPECT().CreateProduct(gomock.Any(), db.Product{Name: "Lemon Pie", Price: 5, Category: "Pie"}).Return(db.Product{Id: "10", Name: "Lemon Pie", Price: 5, Category: "Pie", Version: 1}, nil)
+
+	res, err := svc.CreateProduct(context.Background(), openapi.ProductReq{Name: "Lemon Pie", Price: 5, Category: "Pie"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, map[string][]string{"ETag": {`"1"`}, "Location": {"/api/product/10"}}, res.Headers)
+	assert.Equal(t, openapi.Product{Id: "10", Name: "Lemon Pie", Price: 5, Category: "Pie", Version: 1, Available: true}, res.Body)
+}
+
+func TestUpdateProduct(t *testing.T) {
+	req := openapi.ProductReq{Name: "Lemon Meringue Pie", Price: 6, Category: "Pie"}
+	want := db.Product{Id: "6", Name: "Lemon Meringue Pie", Price: 6, Category: "Pie"}
+	tests := []struct {
+		name        string
+		ifMatch     string
+		version     int64
+		err         error
+		wantCode    int
+		wantHeaders map[string][]string
+	}{
+		{name: "no If-Match", wantCode: http.StatusPreconditionRequired},
+		{name: "current version", ifMatch: `"3"`, version: 3, wantCode: http.StatusOK, wantHeaders: map[string][]string{"ETag": {`"4"`}}},
+		{name: "ETag of a read", ifMatch: `"3-9f86d081884c7d65"`, version: 3, wantCode: http.StatusOK, wantHeaders: map[string][]string{"ETag": {`"4"`}}},
+		{name: "any version", ifMatch: "*", version: 0, wantCode: http.StatusOK, wantHeaders: map[string][]string{"ETag": {`"4"`}}},
+		{name: "weak ETags never match", ifMatch: `W/"3"`, version: -1, err: &db.ProductVersionError{ID: "6", Version: 3},
+			wantCode: http.StatusPreconditionFailed, wantHeaders: map[string][]string{"ETag": {`"3"`}}},
+		{name: "stale version", ifMatch: `"2"`, version: 2, err: &db.ProductVersionError{ID: "6", Version: 3},
+			wantCode: http.StatusPreconditionFailed, wantHeaders: map[string][]string{"ETag": {`"3"`}}},
+		{name: "unknown product", ifMatch: `"1"`, version: 1, err: db.ErrNotFound(sql.ErrNoRows), wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, pd := adminTestService(ctrl)
+			if tt.ifMatch != "" {
+				updated := want
+				updated.Version = 4
+				pd.EXPECT().UpdateProduct(gomock.Any(), want, tt.version).Return(updated, tt.err)
+			}
+			res, err := svc.UpdateProduct(context.Background(), "6", tt.ifMatch, req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, res.Code)
+			assert.Equal(t, tt.wantHeaders, res.Headers)
+		})
+	}
+}
+
+func TestPatchProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, pd := adminTestService(ctrl)
+	pd.EXPECT().PatchProduct(gomock.Any(), db.ID("6"), db.ProductPatch{}, int64(2)).
+		Return(db.Product{}, &db.ProductVersionError{ID: "6", Version: 3})
+
+	res, err := svc.PatchProduct(context.Background(), "6", `"2"`, openapi.ProductPatch{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, res.Code)
+	assert.Equal(t, []string{`"3"`}, res.Headers["ETag"])
+
+	// Only the fields sent are passed on, at any version for "*".
+	price := float32(0)
+	pd.EXPECT().PatchProduct(gomock.Any(), db.ID("6"), db.ProductPatch{Price: &price}, int64(0)).
+		Return(db.Product{Id: "6", Name: "Lemon Meringue Pie", Category: "Pie", Version: 4}, nil)
+	res, err = svc.PatchProduct(context.Background(), "6", "*", openapi.ProductPatch{Price: &price})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, int64(4), res.Body.(openapi.Product).Version)
+}
+
+func TestArchiveProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, pd := adminTestService(ctrl)
+	pd.EXPECT().SetProductArchived(gomock.Any(), db.ID("6"), true, int64(3)).Return(db.Product{Id: "6", Name: "Lemon Meringue Pie", Version: 4, ArchivedAt: time.Now()}, nil)
+	res, err := svc.ArchiveProduct(context.Background(), "6", `"3"`)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Code)
+	body := res.Body.(openapi.Product)
+	assert.True(t, body.Archived)
+	assert.False(t, body.Available, "archived products can't be ordered")
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, pd := adminTestService(ctrl)
+	pd.EXPECT().CatalogChangedAt(gomock.Any()).Return(time.Time{}, nil)
+	pd.EXPECT().FindProducts(gomock.Any(), db.ProductFilter{Sort: db.SortByMenu}).Return(nil, "", nil)
+	pd.EXPECT().CreateProduct(gomock.Any(), gomock.Any()).Return(db.Product{Id: "10", Version: 1}, nil)
+	router := openapi.NewRouter(openapi.NewProductAPIController(svc))
+	router.Use(RequireAPIKey(map[string][]string{"manage_products": {"secret"}}))
+
+	serve := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"name": "Lemon Pie", "price": 5, "category": "Pie"}`))
+		if key != "" {
+			req.Header.Set("api_key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/product", ""), "reading the menu is open")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/product", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/product", "guess"))
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/api/product", "secret"))
+}
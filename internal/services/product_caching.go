@@ -0,0 +1,89 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+// conditional adds the caching headers to res, a read made after the catalog
+// last changed at modified, and turns it into a 304 if the client's copy,
+// described by If-None-Match or, without it, If-Modified-Since, is still
+// current. Dates are to the second, so only ETags tell apart the changes made
+// within one.
+func (s *ProductAPIService) conditional(modified time.Time, etag, ifNoneMatch, ifModifiedSince string, res openapi.ImplResponse) openapi.ImplResponse {
+	modified = modified.UTC().Truncate(time.Second)
+	headers := map[string][]string{
+		"ETag":          {etag},
+		"Last-Modified": {modified.Format(http.TimeFormat)},
+	}
+	if s.cacheControl != "" {
+		headers["Cache-Control"] = []string{s.cacheControl}
+	}
+	notModified := false
+	if ifNoneMatch != "" {
+		notModified = noneMatch(ifNoneMatch, etag)
+	} else if since, err := http.ParseTime(ifModifiedSince); err == nil {
+		notModified = !modified.After(since)
+	}
+	if notModified {
+		return openapi.ResponseWithHeaders(http.StatusNotModified, headers, nil)
+	}
+	for key, values := range res.Headers {
+		headers[key] = values
+	}
+	return openapi.ResponseWithHeaders(res.Code, headers, res.Body)
+}
+
+// withCacheControl adds the configured Cache-Control header to res, a product
+// read that has no version to validate, such as a rejected one.
+func (s *ProductAPIService) withCacheControl(res openapi.ImplResponse) openapi.ImplResponse {
+	if s.cacheControl == "" {
+		return res
+	}
+	headers := map[string][]string{"Cache-Control": {s.cacheControl}}
+	for key, values := range res.Headers {
+		headers[key] = values
+	}
+	return openapi.ResponseWithHeaders(res.Code, headers, res.Body)
+}
+
+// noneMatch reports whether an If-None-Match header lists etag. As for any
+// If-None-Match, weak ETags match their strong ones.
+func noneMatch(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// productReadETag is the strong ETag of a product as read: its version, which
+// If-Match takes, and a hash of the response, which covers its availability.
+func productReadETag(version int64, product openapi.Product) string {
+	return `"` + strconv.FormatInt(version, 10) + "-" + contentHash(product) + `"`
+}
+
+// listETag is the strong ETag of a page of products and its next cursor.
+func listETag(res openapi.ImplResponse) string {
+	return `"` + contentHash(res.Body, res.Headers["X-Next-Cursor"]) + `"`
+}
+
+// contentHash is a short hash of the JSON of values.
+func contentHash(values ...any) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, v := range values {
+		// Responses are made of types that always encode.
+		_ = enc.Encode(v)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+	openapi "backend-challenge/internal/generated/openapi"
+)
+
+func TestConditionalProductReads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	pd := dbmocks.NewMockProductDao(ctrl)
+	pd.EXPECT().GetProduct(gomock.Any(), db.ID("1")).Return(db.Product{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle", Version: 3}, nil).AnyTimes()
+	pd.EXPECT().FindProducts(gomock.Any(), db.ProductFilter{Sort: db.SortByMenu, Limit: 1}).
+		Return([]db.Product{{Id: "1", Name: "Waffle", Price: 6.5, Category: "Waffle", Version: 3}}, "next", nil).AnyTimes()
+	changed := time.Date(2026, 10, 19, 8, 0, 0, 500, time.UTC)
+	pd.EXPECT().CatalogChangedAt(gomock.Any()).DoAndReturn(func(any) (time.Time, error) { return changed, nil }).AnyTimes()
+	onHand := int32(5)
+	sd := dbmocks.NewMockStockDao(ctrl)
+	sd.EXPECT().GetStock(gomock.Any(), gomock.Any()).DoAndReturn(func(any, []db.ID) (map[db.ID]db.Stock, error) {
+		return map[db.ID]db.Stock{"1": {ProductID: "1", OnHand: onHand}}, nil
+	}).AnyTimes()
+	id := dbmocks.NewMockIngredientDao(ctrl)
+	id.EXPECT().GetShortProducts(gomock.Any()).Return(map[db.ID]bool{}, nil).AnyTimes()
+	svc := NewProductAPIService(pd, sd, id, "", nil, WithProductCacheControl("no-cache"))
+	router := openapi.NewRouter(openapi.NewProductAPIController(svc))
+	serve := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, path := range []string{"/api/product/1", "/api/product?limit=1"} {
+		onHand = 5
+		rec := serve(path)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		etag, modified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+		assert.Regexp(t, `^"[0-9a-f-]+"$`, etag, path)
+		assert.Equal(t, "Mon, 19 Oct 2026 08:00:00 GMT", modified, path)
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"), path)
+		assert.Equal(t, etag, serve(path).Header().Get("ETag"), "the same response has the same ETag")
+
+		rec = serve(path, "If-None-Match", `"other", `+etag)
+		assert.Equal(t, http.StatusNotModified, rec.Code, path)
+		assert.Empty(t, rec.Body.String(), path)
+		assert.Equal(t, etag, rec.Header().Get("ETag"), path)
+		assert.Equal(t, modified, rec.Header().Get("Last-Modified"), path)
+		assert.Equal(t, http.StatusNotModified, serve(path, "If-Modified-Since", modified).Code, path)
+		assert.Equal(t, http.StatusOK, serve(path, "If-None-Match", `"other"`, "If-Modified-Since", modified).Code,
+			"If-Modified-Since is ignored with If-None-Match")
+
+		// Selling out changes the response without changing the catalog.
+		onHand = 0
+		changed = changed.Add(time.Minute)
+		rec = serve(path, "If-None-Match", etag)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.NotEqual(t, etag, rec.Header().Get("ETag"), path)
+		rec = serve(path, "If-Modified-Since", modified)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		later, err := http.ParseTime(rec.Header().Get("Last-Modified"))
+		assert.NoError(t, err)
+		earlier, _ := http.ParseTime(modified)
+		assert.True(t, later.After(earlier), "stock changes date the read")
+		changed = changed.Add(-time.Minute)
+	}
+	assert.Equal(t, "next", serve("/api/product?limit=1").Header().Get("X-Next-Cursor"))
+
+	// Categories no product can be in are read like any other.
+	rec := serve("/api/product?category=%21%21")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+	assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, http.StatusNotModified, serve("/api/product?category=%21%21", "If-None-Match", rec.Header().Get("ETag")).Code)
+	for _, path := range []string{"/api/product?minPrice=5&maxPrice=1", "/api/product?sort=colour"} {
+		rec := serve(path)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, path)
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"), path)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	openapi "backend-challenge/internal/generated/openapi"
+	"backend-challenge/internal/images"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// WithImageBaseURL sets the URL that stored product image paths are relative to.
+func WithImageBaseURL(base string) OrderAPIServiceOption {
+	return func(s *OrderAPIService) {
+		s.imageBase = base
+	}
+}
+
+// productImage builds the URLs of a product's pictures. Variants without a
+// picture are left empty.
+func productImage(base string, paths map[string]string) openapi.ProductImage {
+	return openapi.ProductImage{
+		Thumbnail: imageURL(base, paths[db.ImageThumbnail]),
+		Mobile:    imageURL(base, paths[db.ImageMobile]),
+		Tablet:    imageURL(base, paths[db.ImageTablet]),
+		Desktop:   imageURL(base, paths[db.ImageDesktop]),
+	}
+}
+
+// imageURL resolves a stored image path against base. Absolute URLs, and
+// every path when there is no base, are returned as they are.
+func imageURL(base, path string) string {
+	if path == "" || base == "" {
+		return path
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// ImageController serves the renditions of uploaded product pictures. They are
+// named after their content, so clients may cache them for good.
+type ImageController struct {
+	dir string
+}
+
+// NewImageController creates a controller serving the renditions in store. A
+// nil store has none.
+func NewImageController(store *images.Store) *ImageController {
+	if store == nil {
+		return &ImageController{}
+	}
+	return &ImageController{dir: store.Dir()}
+}
+
+// Routes returns all the api routes for the ImageController
+func (c *ImageController) Routes() openapi.Routes {
+	routes := openapi.Routes{}
+	for _, route := range c.OrderedRoutes() {
+		routes[route.Name] = route
+	}
+	return routes
+}
+
+// OrderedRoutes returns all the api routes in a deterministic order for the ImageController
+func (c *ImageController) OrderedRoutes() []openapi.Route {
+	return []openapi.Route{
+		{Name: "GetImage", Method: http.MethodGet, Pattern: "/images/{name}", HandlerFunc: c.GetImage},
+	}
+}
+
+// GetImage - Serve a product picture rendition
+func (c *ImageController) GetImage(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	// Renditions being written are hidden.
+	if c.dir == "" || strings.HasPrefix(name, ".") || name != filepath.Base(name) {
+		http.NotFound(w, r)
+		return
+	}
+	path := filepath.Join(c.dir, name)
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	http.ServeFile(w, r, path)
+}
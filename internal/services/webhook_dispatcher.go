@@ -0,0 +1,226 @@
+package services
+
+import (
+	"backend-challenge/internal/db"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Headers sent with every webhook request. Receivers should verify the
+// signature and can use the event ID to drop duplicates, since a delivery
+// whose response was lost is sent again.
+const (
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookEventIDHeader   = "X-Webhook-Event-Id"
+	webhookAttemptHeader   = "X-Webhook-Attempt"
+)
+
+const (
+	defaultWebhookAttempts   = 8
+	defaultWebhookBackoff    = 10 * time.Second
+	defaultWebhookMaxBackoff = time.Hour
+	defaultWebhookTimeout    = 10 * time.Second
+	// webhookBatchSize bounds how many deliveries one DispatchDue call sends.
+	webhookBatchSize = 50
+)
+
+// WebhookDispatcher sends queued deliveries to their webhooks. Failed
+// deliveries are retried with exponential backoff and marked dead once they
+// run out of attempts; dead ones are only sent again when replayed.
+type WebhookDispatcher struct {
+	webhookDao  db.WebhookDao
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// WebhookDispatcherOption configures optional behaviour of a WebhookDispatcher.
+type WebhookDispatcherOption func(*WebhookDispatcher)
+
+// WithWebhookAttempts sets how many times a delivery is tried before it is dead.
+func WithWebhookAttempts(attempts int) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		if attempts > 0 {
+			d.maxAttempts = attempts
+		}
+	}
+}
+
+// WithWebhookBackoff sets the wait after the first failure, which doubles after
+// every further failure up to max.
+func WithWebhookBackoff(backoff, max time.Duration) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		if backoff > 0 {
+			d.backoff = backoff
+		}
+		if max > 0 {
+			d.maxBackoff = max
+		}
+	}
+}
+
+// WithWebhookTimeout bounds a single request to a webhook.
+func WithWebhookTimeout(timeout time.Duration) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		if timeout > 0 {
+			d.client.Timeout = timeout
+		}
+	}
+}
+
+// NewWebhookDispatcher creates a dispatcher sending the deliveries queued in webhookDao.
+func NewWebhookDispatcher(webhookDao db.WebhookDao, opts ...WebhookDispatcherOption) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		webhookDao:  webhookDao,
+		client:      &http.Client{Timeout: defaultWebhookTimeout, Transport: publicTransport()},
+		maxAttempts: defaultWebhookAttempts,
+		backoff:     defaultWebhookBackoff,
+		maxBackoff:  defaultWebhookMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// DispatchDue sends the deliveries that are due and records the outcome of
+// each. It returns how many were delivered.
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	deliveries, err := d.webhookDao.GetDueDeliveries(ctx, time.Now(), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, delivery := range deliveries {
+		delivery.Attempts++
+		if err := d.send(ctx, delivery); err != nil {
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= d.maxAttempts {
+				delivery.Status = db.DeliveryDead
+			} else {
+				delivery.NextAttemptAt = time.Now().Add(d.retryAfter(delivery.Attempts))
+			}
+		} else {
+			delivery.Status = db.DeliveryDelivered
+			delivery.LastError = ""
+			delivered++
+		}
+		if err := d.webhookDao.UpdateDelivery(ctx, delivery); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// DispatchEvery runs DispatchDue on every tick of interval until ctx is done.
+func (d *WebhookDispatcher) DispatchEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchDue(ctx); err != nil {
+				log.Printf("dispatching webhooks: %v", err)
+			}
+		}
+	}
+}
+
+// retryAfter is the wait before the next attempt after the given number of
+// failed attempts.
+func (d *WebhookDispatcher) retryAfter(attempts int) time.Duration {
+	wait := d.backoff
+	for i := 1; i < attempts && wait < d.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.maxBackoff)
+}
+
+// send POSTs the delivery's event. Any response other than 2xx is a failure.
+func (d *WebhookDispatcher) send(ctx context.Context, delivery db.Delivery) error {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookSignatureHeader, signWebhook(delivery.Secret, body))
+	req.Header.Set(webhookEventHeader, delivery.Event.Type)
+	req.Header.Set(webhookEventIDHeader, delivery.Event.ID)
+	req.Header.Set(webhookAttemptHeader, strconv.Itoa(delivery.Attempts))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.New(res.Status)
+	}
+	return nil
+}
+
+// publicTransport is an HTTP transport that only connects to public addresses,
+// so that a webhook whose name resolves to this machine or a private network,
+// now or after it was registered, can't reach the services behind them. It
+// doesn't go through a proxy, which would hide where requests end up.
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if privateAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook address %s isn't public", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// privateAddr reports whether addr is this machine's or a private network's:
+// unspecified, loopback, private, link-local or shared address space.
+func privateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, private in all but name.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// signWebhook returns the signature header value for body: "sha256=" and the
+// hex HMAC-SHA256 of the body keyed with the webhook secret.
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	db "backend-challenge/internal/db"
+	dbmocks "backend-challenge/internal/db/mocks"
+)
+
+func TestWebhookDispatcher_DispatchDue(t *testing.T) {
+	var received []db.Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhookSignatureHeader) != signWebhook("s3cret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event db.Event
+		assert.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, event.Type, r.Header.Get(webhookEventHeader))
+		received = append(received, event)
+	}))
+	defer receiver.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	event := db.Event{ID: "event-1", Type: db.EventOrderCreated, OrderID: "order-1", Data: json.RawMessage(`{"id":"order-1"}`)}
+	tests := []struct {
+		name         string
+		delivery     db.Delivery
+		wantStatus   string
+		wantError    string
+		wantBackoff  time.Duration
+		wantReceived int
+		// guarded keeps the transport that refuses private addresses, which
+		// the test receivers listen on.
+		guarded bool
+	}{
+		{
+			name:         "delivered with a valid signature",
+			delivery:     db.Delivery{ID: "d1", Event: event, Status: db.DeliveryPending, URL: receiver.URL, Secret: "s3cret"},
+			wantStatus:   db.DeliveryDelivered,
+			wantReceived: 1,
+		},
+		{
+			name:        "rejected signature is retried",
+			delivery:    db.Delivery{ID: "d2", Event: event, Status: db.DeliveryPending, URL: receiver.URL, Secret: "wrong"},
+			wantStatus:  db.DeliveryPending,
+			wantError:   "401 Unauthorized",
+			wantBackoff: time.Second,
+		},
+		{
+			name:        "backoff doubles with each attempt",
+			delivery:    db.Delivery{ID: "d3", Event: event, Status: db.DeliveryPending, Attempts: 2, URL: failing.URL, Secret: "s3cret"},
+			wantStatus:  db.DeliveryPending,
+			wantError:   "503 Service Unavailable",
+			wantBackoff: 4 * time.Second,
+		},
+		{
+			name:        "private addresses aren't sent to",
+			delivery:    db.Delivery{ID: "d5", Event: event, Status: db.DeliveryPending, URL: receiver.URL, Secret: "s3cret"},
+			wantStatus:  db.DeliveryPending,
+			wantError:   "webhook address 127.0.0.1 isn't public",
+			wantBackoff: time.Second,
+			guarded:     true,
+		},
+		{
+			name:       "last attempt is dead",
+			delivery:   db.Delivery{ID: "d4", Event: event, Status: db.DeliveryPending, Attempts: 4, URL: failing.URL, Secret: "s3cret"},
+			wantStatus: db.DeliveryDead,
+			wantError:  "503 Service Unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			webhookDao := dbmocks.NewMockWebhookDao(ctrl)
+			webhookDao.EXPECT().GetDueDeliveries(gomock.Any(), gomock.Any(), webhookBatchSize).Return([]db.Delivery{tt.delivery}, nil)
+			webhookDao.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d db.Delivery) error {
+				assert.Equal(t, tt.wantStatus, d.Status)
+				assert.Equal(t, tt.delivery.Attempts+1, d.Attempts)
+				if tt.wantError == "" {
+					assert.Empty(t, d.LastError)
+				} else {
+					assert.Contains(t, d.LastError, tt.wantError)
+				}
+				if tt.wantBackoff > 0 {
+					assert.WithinDuration(t, time.Now().Add(tt.wantBackoff), d.NextAttemptAt, 500*time.Millisecond)
+				}
+				return nil
+			})
+			dispatcher := NewWebhookDispatcher(webhookDao, WithWebhookAttempts(5), WithWebhookBackoff(time.Second, time.Minute))
+			if !tt.guarded {
+				dispatcher.client.Transport = http.DefaultTransport
+			}
+
+			_, err := dispatcher.DispatchDue(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, received, tt.wantReceived)
+		})
+	}
+}
+
+func TestWebhookDispatcher_RetryAfterIsCapped(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, WithWebhookBackoff(10*time.Second, time.Minute))
+	assert.Equal(t, 10*time.Second, dispatcher.retryAfter(1))
+	assert.Equal(t, 40*time.Second, dispatcher.retryAfter(3))
+	assert.Equal(t, time.Minute, dispatcher.retryAfter(4))
+	assert.Equal(t, time.Minute, dispatcher.retryAfter(50))
+}
@@ -0,0 +1,131 @@
+package slots
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config divides the opening hours of each day into pickup slots and limits
+// how many scheduled orders each slot takes.
+type Config struct {
+	// Length of a slot, a whole number of minutes such as 15m. Zero turns
+	// scheduled orders off.
+	Length time.Duration `yaml:"length"`
+	// From and Until are the local times of day of the first slot's start
+	// and the last slot's end, such as "08:00" and "22:00".
+	From  string `yaml:"from"`
+	Until string `yaml:"until"`
+	// MaxOrders and MaxItems cap the orders, and the items across them, a
+	// slot takes; zero leaves that side unlimited.
+	MaxOrders int32 `yaml:"maxOrders"`
+	MaxItems  int32 `yaml:"maxItems"`
+	// MinLead is how far ahead an order must be scheduled, and MaxAhead how
+	// far ahead it may be; a zero MaxAhead has no limit.
+	MinLead  time.Duration `yaml:"minLead"`
+	MaxAhead time.Duration `yaml:"maxAhead"`
+	// Release is how long before its pickup time a scheduled order goes to
+	// the kitchen.
+	Release time.Duration `yaml:"release"`
+}
+
+// Schedule is a parsed Config.
+type Schedule struct {
+	Config
+	from, until int
+}
+
+// New checks cfg and builds its schedule.
+func New(cfg Config) (*Schedule, error) {
+	if cfg.Length <= 0 || cfg.Length%time.Minute != 0 {
+		return nil, fmt.Errorf("slots: length %s is not a whole number of minutes", cfg.Length)
+	}
+	s := &Schedule{Config: cfg, from: 0, until: 24 * 60}
+	if cfg.From != "" {
+		t, err := time.Parse("15:04", cfg.From)
+		if err != nil {
+			return nil, fmt.Errorf("slots: from %q is not a time of day like 08:00", cfg.From)
+		}
+		s.from = t.Hour()*60 + t.Minute()
+	}
+	if cfg.Until != "" {
+		t, err := time.Parse("15:04", cfg.Until)
+		if err != nil {
+			return nil, fmt.Errorf("slots: until %q is not a time of day like 22:00", cfg.Until)
+		}
+		s.until = t.Hour()*60 + t.Minute()
+	}
+	if s.until-s.from < s.minutes() {
+		return nil, fmt.Errorf("slots: no %s slot fits between %s and %s", cfg.Length, cfg.From, cfg.Until)
+	}
+	return s, nil
+}
+
+func (s *Schedule) minutes() int {
+	return int(s.Length / time.Minute)
+}
+
+// Day returns the start of every slot on the day of date, in date's location.
+func (s *Schedule) Day(date time.Time) []time.Time {
+	var starts []time.Time
+	for minute := s.from; minute+s.minutes() <= s.until; minute += s.minutes() {
+		starts = append(starts, time.Date(date.Year(), date.Month(), date.Day(), minute/60, minute%60, 0, 0, date.Location()))
+	}
+	return starts
+}
+
+// Slot returns the start of the slot at falls in, in at's location. It
+// reports false outside the slots.
+func (s *Schedule) Slot(at time.Time) (time.Time, bool) {
+	minute := at.Hour()*60 + at.Minute()
+	if minute < s.from {
+		return time.Time{}, false
+	}
+	start := s.from + (minute-s.from)/s.minutes()*s.minutes()
+	if start+s.minutes() > s.until {
+		return time.Time{}, false
+	}
+	return time.Date(at.Year(), at.Month(), at.Day(), start/60, start%60, 0, 0, at.Location()), true
+}
+
+// UnavailableError is returned for times an order can't be scheduled for.
+type UnavailableError struct {
+	Reason string
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Reason
+}
+
+// Check finds the slot for an order scheduled at at, placed at now, and
+// checks the lead-time rules.
+func (s *Schedule) Check(at, now time.Time) (time.Time, error) {
+	start, ok := s.Slot(at)
+	if !ok {
+		return time.Time{}, &UnavailableError{Reason: fmt.Sprintf("no pickup slot at %s", at.Format("15:04"))}
+	}
+	if earliest := now.Add(s.MinLead); at.Before(earliest) {
+		return time.Time{}, &UnavailableError{Reason: fmt.Sprintf("orders must be scheduled at least %s ahead", formatLead(s.MinLead))}
+	}
+	if s.MaxAhead > 0 && at.After(now.Add(s.MaxAhead)) {
+		return time.Time{}, &UnavailableError{Reason: fmt.Sprintf("orders can be scheduled at most %s ahead", formatLead(s.MaxAhead))}
+	}
+	return start, nil
+}
+
+// formatLead writes d in days, hours or minutes, whichever divides it.
+func formatLead(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int(d/time.Hour), "hour")
+	}
+	return plural(int(d/time.Minute), "minute")
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,67 @@
+package slots
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule_Day(t *testing.T) {
+	s, err := New(Config{Length: 45 * time.Minute, From: "11:00", Until: "13:30"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	day := time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return time.Date(2024, 3, 1, h, m, 0, 0, time.UTC) }
+	// A slot that would end after Until isn't offered.
+	assert.Equal(t, []time.Time{at(11, 0), at(11, 45), at(12, 30)}, s.Day(day))
+
+	start, ok := s.Slot(at(12, 40))
+	assert.True(t, ok)
+	assert.Equal(t, at(12, 30), start)
+	_, ok = s.Slot(at(10, 59))
+	assert.False(t, ok)
+	_, ok = s.Slot(at(13, 15))
+	assert.False(t, ok)
+}
+
+func TestSchedule_Check(t *testing.T) {
+	s, err := New(Config{Length: 15 * time.Minute, From: "08:00", Until: "22:00", MinLead: 30 * time.Minute, MaxAhead: 48 * time.Hour})
+	if !assert.NoError(t, err) {
+		return
+	}
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		at      time.Time
+		want    time.Time
+		wantErr string
+	}{
+		{name: "lunch", at: now.Add(4*time.Hour + 37*time.Minute), want: now.Add(4*time.Hour + 30*time.Minute)},
+		{name: "at the lead time", at: now.Add(30 * time.Minute), want: now.Add(30 * time.Minute)},
+		{name: "too soon", at: now.Add(20 * time.Minute), wantErr: "orders must be scheduled at least 30 minutes ahead"},
+		{name: "too far", at: now.Add(72 * time.Hour), wantErr: "orders can be scheduled at most 2 days ahead"},
+		{name: "closed", at: now.Add(14 * time.Hour), wantErr: "no pickup slot at 22:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Check(tt.at, now)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew_RejectsBadConfig(t *testing.T) {
+	_, err := New(Config{Length: 90 * time.Second})
+	assert.EqualError(t, err, "slots: length 1m30s is not a whole number of minutes")
+	_, err = New(Config{Length: time.Hour, From: "8am"})
+	assert.EqualError(t, err, `slots: from "8am" is not a time of day like 08:00`)
+	_, err = New(Config{Length: time.Hour, From: "12:00", Until: "12:30"})
+	assert.EqualError(t, err, "slots: no 1h0m0s slot fits between 12:00 and 12:30")
+}